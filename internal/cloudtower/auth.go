@@ -0,0 +1,201 @@
+package cloudtower
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hasura/go-graphql-client"
+	apiclient "github.com/smartxworks/cloudtower-go-sdk/v2/client"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/client/user"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/models"
+)
+
+// authenticator holds the token shared by every request of a Client,
+// and re-login when the token is rejected by CloudTower
+type authenticator struct {
+	mu    sync.Mutex
+	token atomic.Pointer[string]
+	login func(ctx context.Context) (string, error)
+}
+
+func newAuthenticator(login func(ctx context.Context) (string, error)) *authenticator {
+	return &authenticator{login: login}
+}
+
+// newTokenAuthenticator uses a pre-issued token, which can not be renewed by the provider
+func newTokenAuthenticator(token string) *authenticator {
+	a := &authenticator{
+		login: func(ctx context.Context) (string, error) {
+			return "", errors.New("api token is rejected by CloudTower, it may be expired or revoked")
+		},
+	}
+	a.token.Store(&token)
+	return a
+}
+
+func (a *authenticator) Token() string {
+	if t := a.token.Load(); t != nil {
+		return *t
+	}
+	return ""
+}
+
+// refresh re-login only if the token is still the stale one, so concurrent
+// requests failing with the same token trigger a single login
+func (a *authenticator) refresh(ctx context.Context, stale string) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if current := a.Token(); current != stale {
+		return current, nil
+	}
+	token, err := a.login(ctx)
+	if err != nil {
+		return "", err
+	}
+	a.token.Store(&token)
+	return token, nil
+}
+
+func login(ctx context.Context, api *apiclient.Cloudtower, graphqlClient *graphql.Client, cfg ClientConfig) (string, error) {
+	source := cfg.Source
+	loginParams := user.NewLoginParams()
+	loginParams.RequestBody = &models.LoginInput{
+		Username: StrPtr(cfg.Username),
+		Password: StrPtr(cfg.Password),
+		Source:   &source,
+	}
+	if source == models.UserSourceLDAP {
+		authConfigId, err := getLdapConfig(ctx, graphqlClient, cfg.Retry)
+		if err != nil {
+			return "", err
+		}
+		if authConfigId == nil {
+			return "", errors.New("LDAP config not found")
+		}
+		loginParams.RequestBody.AuthConfigID = authConfigId
+		loginParams.RequestBody.Source = models.UserSourceAUTHN.Pointer()
+	}
+	loginParams.Context = ctx
+	loginResp, err := api.User.Login(loginParams)
+	if err != nil {
+		return "", err
+	}
+	return *loginResp.Payload.Data.Token, nil
+}
+
+type authTransport struct {
+	inner      http.RoundTripper
+	auth       *authenticator
+	header     func(token string) string
+	authFailed func(resp *http.Response) bool
+}
+
+func newRestAuthTransport(inner http.RoundTripper, auth *authenticator) http.RoundTripper {
+	return &authTransport{
+		inner: inner,
+		auth:  auth,
+		header: func(token string) string {
+			return "Bearer " + token
+		},
+		authFailed: func(resp *http.Response) bool {
+			return resp.StatusCode == http.StatusUnauthorized
+		},
+	}
+}
+
+func newGraphqlAuthTransport(inner http.RoundTripper, auth *authenticator) http.RoundTripper {
+	return &authTransport{
+		inner: inner,
+		auth:  auth,
+		header: func(token string) string {
+			return token
+		},
+		authFailed: isGraphqlAuthFailure,
+	}
+}
+
+func (t *authTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	// buffer the body so the request can be replayed after re-login
+	var body []byte
+	if r.Body != nil && r.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	token := t.auth.Token()
+	resp, err := t.inner.RoundTrip(t.withToken(r, body, token))
+	if err != nil || !t.authFailed(resp) {
+		return resp, err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	token, err = t.auth.refresh(r.Context(), token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to re-login after authentication failure: %w", err)
+	}
+	return t.inner.RoundTrip(t.withToken(r, body, token))
+}
+
+func (t *authTransport) withToken(r *http.Request, body []byte, token string) *http.Request {
+	req := r.Clone(r.Context())
+	if body != nil {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.ContentLength = int64(len(body))
+	}
+	req.Header.Set("Authorization", t.header(token))
+	return req
+}
+
+// CloudTower GraphQL API reports an expired token as an UNAUTHENTICATED error
+// within a 200 response, peek into the body to find it and restore the body afterwards
+func isGraphqlAuthFailure(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusUnauthorized {
+		return true
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+	raw, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(raw))
+	if err != nil {
+		return false
+	}
+	var reader io.Reader = bytes.NewReader(raw)
+	if resp.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return false
+		}
+		defer gz.Close()
+		reader = gz
+	}
+	var result struct {
+		Errors []struct {
+			Extensions struct {
+				Code string `json:"code"`
+			} `json:"extensions"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(reader).Decode(&result); err != nil {
+		return false
+	}
+	for _, e := range result.Errors {
+		if e.Extensions.Code == "UNAUTHENTICATED" {
+			return true
+		}
+	}
+	return false
+}
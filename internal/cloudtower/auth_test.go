@@ -0,0 +1,213 @@
+package cloudtower
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// newTestAuthenticator returns an authenticator holding token, whose logins
+// are counted and return fresh
+func newTestAuthenticator(token string, fresh string) (*authenticator, *atomic.Int32) {
+	logins := &atomic.Int32{}
+	a := newAuthenticator(func(ctx context.Context) (string, error) {
+		logins.Add(1)
+		return fresh, nil
+	})
+	a.token.Store(&token)
+	return a, logins
+}
+
+// newAuthServer answers the requests authorized with header with their body,
+// and the others with reject
+func newAuthServer(t *testing.T, header string, reject func(w http.ResponseWriter)) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != header {
+			reject(w)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func post(t *testing.T, transport http.RoundTripper, url string, body string) (string, error) {
+	t.Helper()
+	resp, err := (&http.Client{Transport: transport}).Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	return string(raw), err
+}
+
+func TestRestAuthTransport(t *testing.T) {
+	srv := newAuthServer(t, "Bearer fresh", func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	auth, logins := newTestAuthenticator("stale", "fresh")
+	transport := newRestAuthTransport(http.DefaultTransport, auth)
+
+	body, err := post(t, transport, srv.URL, `{"where":{}}`)
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	if body != `{"where":{}}` {
+		t.Errorf("Post() replayed body = %q, want the original one", body)
+	}
+	if n := logins.Load(); n != 1 {
+		t.Errorf("logged in %d times, want 1", n)
+	}
+	if _, err := post(t, transport, srv.URL, `{}`); err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	if n := logins.Load(); n != 1 {
+		t.Errorf("logged in %d times with a valid token, want 1", n)
+	}
+}
+
+func TestGraphqlAuthTransport(t *testing.T) {
+	unauthenticated := `{"data":null,"errors":[{"message":"token expired","extensions":{"code":"UNAUTHENTICATED"}}]}`
+	tests := []struct {
+		name   string
+		reject func(w http.ResponseWriter)
+	}{
+		{
+			name: "401",
+			reject: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusUnauthorized)
+			},
+		},
+		{
+			name: "unauthenticated error",
+			reject: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(unauthenticated))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newAuthServer(t, "fresh", tt.reject)
+			auth, logins := newTestAuthenticator("stale", "fresh")
+			body, err := post(t, newGraphqlAuthTransport(http.DefaultTransport, auth), srv.URL, `{"query":"{ vms { id } }"}`)
+			if err != nil {
+				t.Fatalf("Post() error = %v", err)
+			}
+			if body != `{"query":"{ vms { id } }"}` {
+				t.Errorf("Post() replayed body = %q, want the original one", body)
+			}
+			if n := logins.Load(); n != 1 {
+				t.Errorf("logged in %d times, want 1", n)
+			}
+		})
+	}
+}
+
+func TestIsGraphqlAuthFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		gzip bool
+		want bool
+	}{
+		{
+			name: "data",
+			body: `{"data":{"vms":[]}}`,
+		},
+		{
+			name: "other error",
+			body: `{"errors":[{"message":"invalid query","extensions":{"code":"GRAPHQL_VALIDATION_FAILED"}}]}`,
+		},
+		{
+			name: "not json",
+			body: `bad gateway`,
+		},
+		{
+			name: "unauthenticated error",
+			body: `{"errors":[{"message":"token expired","extensions":{"code":"UNAUTHENTICATED"}}]}`,
+			want: true,
+		},
+		{
+			name: "gzipped unauthenticated error",
+			body: `{"errors":[{"message":"token expired","extensions":{"code":"UNAUTHENTICATED"}}]}`,
+			gzip: true,
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := []byte(tt.body)
+			resp := &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+			}
+			if tt.gzip {
+				var buf bytes.Buffer
+				gz := gzip.NewWriter(&buf)
+				gz.Write(raw)
+				gz.Close()
+				raw = buf.Bytes()
+				resp.Header.Set("Content-Encoding", "gzip")
+			}
+			resp.Body = io.NopCloser(bytes.NewReader(raw))
+			if got := isGraphqlAuthFailure(resp); got != tt.want {
+				t.Errorf("isGraphqlAuthFailure() = %v, want %v", got, tt.want)
+			}
+			// the body is read again by the graphql client
+			if body, _ := io.ReadAll(resp.Body); !bytes.Equal(body, raw) {
+				t.Errorf("body = %q after isGraphqlAuthFailure(), want %q", body, raw)
+			}
+		})
+	}
+}
+
+func TestAuthenticatorRefresh(t *testing.T) {
+	auth, logins := newTestAuthenticator("stale", "fresh")
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if token, err := auth.refresh(context.Background(), "stale"); err != nil || token != "fresh" {
+				t.Errorf("refresh() = %q, %v, want fresh", token, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := logins.Load(); n != 1 {
+		t.Errorf("logged in %d times by concurrent refreshes of the same token, want 1", n)
+	}
+}
+
+func TestAuthTransportLoginFailure(t *testing.T) {
+	srv := newAuthServer(t, "Bearer valid", func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	transport := newRestAuthTransport(http.DefaultTransport, newTokenAuthenticator("revoked"))
+	_, err := post(t, transport, srv.URL, `{}`)
+	if err == nil || !strings.Contains(err.Error(), "api token is rejected") {
+		t.Errorf("Post() error = %v, want the api token to be rejected", err)
+	}
+
+	failing := newAuthenticator(func(ctx context.Context) (string, error) {
+		return "", errors.New("invalid password")
+	})
+	_, err = post(t, newRestAuthTransport(http.DefaultTransport, failing), srv.URL, `{}`)
+	if err == nil || !strings.Contains(err.Error(), "failed to re-login") {
+		t.Errorf("Post() error = %v, want the re-login to fail", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	httptransport "github.com/go-openapi/runtime/client"
@@ -14,7 +15,6 @@ import (
 	apiclient "github.com/smartxworks/cloudtower-go-sdk/v2/client"
 	"github.com/smartxworks/cloudtower-go-sdk/v2/client/organization"
 	"github.com/smartxworks/cloudtower-go-sdk/v2/client/task"
-	"github.com/smartxworks/cloudtower-go-sdk/v2/client/user"
 	"github.com/smartxworks/cloudtower-go-sdk/v2/models"
 )
 
@@ -33,105 +33,189 @@ func BoolPtr(b bool) *bool {
 func FloatPtr(f float64) *float64 {
 	return &f
 }
+func StrValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
 
 type Client struct {
 	server     string
-	username   string
-	passwd     string
-	source     models.UserSource
-	token      string
+	auth       *authenticator
+	tasks      *taskTracker
+	clusters   *clusterSlots
 	OrgId      string
 	Api        *apiclient.Cloudtower
 	GraphqlApi *graphql.Client
+	// RetryOptions are used when retrying requests to CloudTower
+	RetryOptions utils.RetryWithExponentialBackoffOptions
+	// DefaultLabels are added to the labels of every taggable resource
+	DefaultLabels map[string]string
 }
 
-func NewClient(server string, username string, passwd string, source models.UserSource) (*Client, error) {
-	transport := httptransport.New(server, "/v2/api", []string{"http"})
-	transport.Transport = SetUserAgent(transport.Transport, "terraform-provider-cloudtower")
-	api := apiclient.New(transport, strfmt.Default)
-	loginParams := user.NewLoginParams()
-	loginParams.RequestBody = &models.LoginInput{
-		Username: StrPtr(username),
-		Password: StrPtr(passwd),
-		Source:   &source,
-	}
-	graphqlClient := graphql.NewClient(fmt.Sprintf("http://%s/api", server), nil)
-	if source == models.UserSourceLDAP {
-		authConfigId, err := getLdapConfig(context.TODO(), graphqlClient)
-		if err != nil {
-			return nil, err
-		}
-		if authConfigId == nil {
-			return nil, errors.New("LDAP config not found")
-		}
-		loginParams.RequestBody.AuthConfigID = authConfigId
-		loginParams.RequestBody.Source = models.UserSourceAUTHN.Pointer()
+type ClientConfig struct {
+	Server   string
+	Username string
+	Password string
+	Source   models.UserSource
+	// Token is a pre-issued API token, when set username and password are not used
+	Token string
+	// Organization is the name or id of the organization resources are created in,
+	// can be omitted when there is only one organization
+	Organization string
+	// Scheme is the protocol used to talk to CloudTower, http or https, defaults to http
+	Scheme string
+	TLS    TLSConfig
+	// TaskPollInterval is how often outstanding tasks are polled, defaults to 5 seconds
+	TaskPollInterval time.Duration
+	// Retry limits the retries of transient failures, zero values use the defaults
+	Retry     utils.RetryWithExponentialBackoffOptions
+	RateLimit RateLimitConfig
+	// DefaultLabels are added to the labels of every taggable resource
+	DefaultLabels map[string]string
+}
+
+func NewClient(cfg ClientConfig) (*Client, error) {
+	scheme := cfg.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+	if scheme != "http" && scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme %s, must be one of http, https", scheme)
 	}
-	loginResp, err := api.User.Login(loginParams)
+	httpTransport, err := newHttpTransport(cfg.TLS)
 	if err != nil {
 		return nil, err
 	}
-	bearerTokenAuth := httptransport.BearerToken(*loginResp.Payload.Data.Token)
-	transport.DefaultAuthentication = bearerTokenAuth
-	api = apiclient.New(transport, strfmt.Default)
+	baseTransport := newRateLimitTransport(SetUserAgent(httpTransport, "terraform-provider-cloudtower"), cfg.RateLimit)
+	graphqlUrl := fmt.Sprintf("%s://%s/api", scheme, cfg.Server)
+
+	// unauthenticated clients, only used to login
+	loginTransport := httptransport.New(cfg.Server, "/v2/api", []string{scheme})
+	loginTransport.Transport = baseTransport
+	loginApi := apiclient.New(loginTransport, strfmt.Default)
+	loginGraphqlClient := graphql.NewClient(graphqlUrl, &http.Client{Transport: baseTransport})
+
+	var auth *authenticator
+	if cfg.Token != "" {
+		auth = newTokenAuthenticator(cfg.Token)
+	} else {
+		auth = newAuthenticator(func(ctx context.Context) (string, error) {
+			return login(ctx, loginApi, loginGraphqlClient, cfg)
+		})
+		if _, err := auth.refresh(context.TODO(), ""); err != nil {
+			return nil, err
+		}
+	}
+
+	transport := httptransport.New(cfg.Server, "/v2/api", []string{scheme})
+	transport.Transport = newRestAuthTransport(baseTransport, auth)
+	api := apiclient.New(transport, strfmt.Default)
+	graphqlClient := graphql.NewClient(graphqlUrl, &http.Client{
+		Transport: newGraphqlAuthTransport(baseTransport, auth),
+	})
 
 	gop := organization.NewGetOrganizationsParams()
 	orgs, err := api.Organization.GetOrganizations(gop)
+	if err != nil {
+		if cfg.Token != "" {
+			return nil, fmt.Errorf("failed to validate api token: %w", err)
+		}
+		return nil, err
+	}
+	orgId, err := resolveOrganization(orgs.Payload, cfg.Organization)
 	if err != nil {
 		return nil, err
 	}
 
 	return &Client{
-		server:   server,
-		username: username,
-		passwd:   passwd,
-		source:   source,
-		token:    *loginResp.Payload.Data.Token,
-		OrgId:    *orgs.Payload[0].ID,
-		Api:      api,
-		GraphqlApi: graphqlClient.WithRequestModifier(func(r *http.Request) {
-			r.Header.Set("Authorization", *loginResp.Payload.Data.Token)
-		}),
+		server:        cfg.Server,
+		auth:          auth,
+		tasks:         newTaskTracker(api, cfg.TaskPollInterval, cfg.Retry),
+		clusters:      newClusterSlots(cfg.RateLimit.MaxConcurrentClusterCreations),
+		OrgId:         orgId,
+		Api:           api,
+		GraphqlApi:    graphqlClient,
+		RetryOptions:  cfg.Retry,
+		DefaultLabels: cfg.DefaultLabels,
 	}, nil
 }
 
+// AcquireClusterSlot blocks until a VM can be created or cloned on the cluster,
+// the returned func must be called once the creation is done
+func (c *Client) AcquireClusterSlot(ctx context.Context, clusterId string) (func(), error) {
+	slot := c.clusters.get(clusterId)
+	if err := slot.acquire(ctx); err != nil {
+		return nil, err
+	}
+	return slot.release, nil
+}
+
+// PollInterval is how often the client polls CloudTower for tasks, and should poll for other state changes
+func (c *Client) PollInterval() time.Duration {
+	return c.tasks.interval
+}
+
+func resolveOrganization(orgs []*models.Organization, org string) (string, error) {
+	names := make([]string, 0, len(orgs))
+	for _, o := range orgs {
+		if org != "" && (*o.ID == org || *o.Name == org) {
+			return *o.ID, nil
+		}
+		names = append(names, *o.Name)
+	}
+	switch {
+	case org != "":
+		return "", fmt.Errorf("organization %s not found, available organizations: %s", org, strings.Join(names, ", "))
+	case len(orgs) == 0:
+		return "", errors.New("no organization found")
+	case len(orgs) > 1:
+		return "", fmt.Errorf("multiple organizations found, please set organization to one of: %s", strings.Join(names, ", "))
+	}
+	return *orgs[0].ID, nil
+}
+
 func (c *Client) WaitTasksFinish(ctx context.Context, taskIds []string) (*task.GetTasksOK, error) {
 	if len(taskIds) == 0 {
 		return task.NewGetTasksOK(), nil
 	}
-	tasksParams := task.NewGetTasksParams()
-	tasksParams.RequestBody = &models.GetTasksRequestBody{
-		Where: &models.TaskWhereInput{
-			IDIn: taskIds,
-		},
+	chans := make([]chan taskResult, len(taskIds))
+	for i, id := range taskIds {
+		chans[i] = c.tasks.watch(id)
 	}
-	for {
+	defer func() {
+		for i, id := range taskIds {
+			c.tasks.unwatch(id, chans[i])
+		}
+	}()
+
+	tasks := make([]*models.Task, 0, len(taskIds))
+	failedTasks := make([]*models.Task, 0)
+	for _, ch := range chans {
 		select {
 		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-time.After(5 * time.Second):
-			tasksResp, err := utils.RetryWithExponentialBackoff(ctx, func() (*task.GetTasksOK, error) {
-				return c.Api.Task.GetTasks(tasksParams)
-			}, utils.RetryWithExponentialBackoffOptions{})
-			if err != nil {
-				return nil, err
+			if ctx.Err() == context.DeadlineExceeded {
+				return nil, fmt.Errorf("timeout while waiting for tasks %s to finish: %w", strings.Join(taskIds, ", "), ctx.Err())
 			}
-
-			allFinished := true
-			for _, v := range tasksResp.Payload {
-				if *v.Status != models.TaskStatusSUCCESSED && *v.Status != models.TaskStatusFAILED {
-					allFinished = false
-				}
-				if *v.Status == models.TaskStatusFAILED {
-					return nil, errors.New(*v.ErrorMessage)
-				}
+			return nil, ctx.Err()
+		case result := <-ch:
+			if result.err != nil {
+				return nil, result.err
 			}
-			if !allFinished {
-				continue
+			if *result.task.Status == models.TaskStatusFAILED {
+				failedTasks = append(failedTasks, result.task)
 			}
-			return tasksResp, nil
+			tasks = append(tasks, result.task)
 		}
 	}
+	// wait for all tasks before reporting, so every failed task is reported
+	if len(failedTasks) > 0 {
+		return nil, &TaskError{Tasks: failedTasks}
+	}
+	tasksResp := task.NewGetTasksOK()
+	tasksResp.Payload = tasks
+	return tasksResp, nil
 }
 
 func (c *Client) WaitTaskForResource(ctx context.Context, id string, task_type string) (*task.GetTasksOK, error) {
@@ -145,35 +229,25 @@ func (c *Client) WaitTaskForResource(ctx context.Context, id string, task_type s
 		OrderBy: models.TaskOrderByInputLocalCreatedAtDESC.Pointer(),
 		First:   &first,
 	}
-	for {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-time.After(5 * time.Second):
-			tasksResp, err := utils.RetryWithExponentialBackoff(ctx, func() (*task.GetTasksOK, error) {
-				return c.Api.Task.GetTasks(tasksParams)
-			}, utils.RetryWithExponentialBackoffOptions{})
-			if err != nil {
-				return nil, err
-			}
-			allFinished := true
-			for _, v := range tasksResp.Payload {
-				if *v.Status != models.TaskStatusSUCCESSED && *v.Status != models.TaskStatusFAILED {
-					allFinished = false
-				}
-				if *v.Status == models.TaskStatusFAILED {
-					return nil, errors.New(*v.ErrorMessage)
-				}
-			}
-			if !allFinished {
-				continue
-			}
-			return tasksResp, nil
-		}
+	// give CloudTower a poll interval to create the task before looking it up
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(c.tasks.interval):
+	}
+	tasksResp, err := utils.RetryWithExponentialBackoff(ctx, func() (*task.GetTasksOK, error) {
+		return c.Api.Task.GetTasks(tasksParams)
+	}, c.RetryOptions)
+	if err != nil {
+		return nil, err
+	}
+	if len(tasksResp.Payload) == 0 {
+		return tasksResp, nil
 	}
+	return c.WaitTasksFinish(ctx, []string{*tasksResp.Payload[0].ID})
 }
 
-func getLdapConfig(ctx context.Context, graphqlClient *graphql.Client) (*string, error) {
+func getLdapConfig(ctx context.Context, graphqlClient *graphql.Client, retry utils.RetryWithExponentialBackoffOptions) (*string, error) {
 	var authnStrategies struct {
 		AuthnStrategies []struct {
 			Id   graphql.String
@@ -182,7 +256,7 @@ func getLdapConfig(ctx context.Context, graphqlClient *graphql.Client) (*string,
 	}
 	_, err := utils.RetryWithExponentialBackoff(ctx, func() (interface{}, error) {
 		return nil, graphqlClient.Query(ctx, &authnStrategies, map[string]interface{}{})
-	}, utils.RetryWithExponentialBackoffOptions{})
+	}, retry)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,179 @@
+package cloudtower
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+type RateLimitConfig struct {
+	// RequestsPerSecond caps the requests sent to CloudTower, zero means unlimited
+	RequestsPerSecond float64
+	// MaxConcurrentMutations caps the in-flight requests which modify resources, zero means unlimited
+	MaxConcurrentMutations int
+	// MaxConcurrentClusterCreations caps the VMs being created or cloned on one cluster, zero means unlimited
+	MaxConcurrentClusterCreations int
+}
+
+// rateLimiter spaces requests evenly, it reserves the next free slot for each
+// caller so waiting callers never exceed the rate once they are released
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newRateLimiter(requestsPerSecond float64) *rateLimiter {
+	if requestsPerSecond <= 0 {
+		return nil
+	}
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / requestsPerSecond)}
+}
+
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// semaphore is a counting semaphore, a nil semaphore never blocks
+type semaphore chan struct{}
+
+func newSemaphore(size int) semaphore {
+	if size <= 0 {
+		return nil
+	}
+	return make(semaphore, size)
+}
+
+func (s semaphore) acquire(ctx context.Context) error {
+	if s == nil {
+		return nil
+	}
+	select {
+	case s <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s semaphore) release() {
+	if s != nil {
+		<-s
+	}
+}
+
+// rateLimitTransport throttles both the REST and the GraphQL requests of a Client,
+// it sits below the auth transport so the requests replayed after re-login count too
+type rateLimitTransport struct {
+	inner     http.RoundTripper
+	limiter   *rateLimiter
+	mutations semaphore
+}
+
+func newRateLimitTransport(inner http.RoundTripper, cfg RateLimitConfig) http.RoundTripper {
+	limiter := newRateLimiter(cfg.RequestsPerSecond)
+	mutations := newSemaphore(cfg.MaxConcurrentMutations)
+	if limiter == nil && mutations == nil {
+		return inner
+	}
+	return &rateLimitTransport{
+		inner:     inner,
+		limiter:   limiter,
+		mutations: mutations,
+	}
+}
+
+func (t *rateLimitTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if t.mutations != nil {
+		mutation, err := isMutation(r)
+		if err != nil {
+			return nil, err
+		}
+		if mutation {
+			if err := t.mutations.acquire(r.Context()); err != nil {
+				return nil, err
+			}
+			defer t.mutations.release()
+		}
+	}
+	if err := t.limiter.wait(r.Context()); err != nil {
+		return nil, err
+	}
+	return t.inner.RoundTrip(r)
+}
+
+// isMutation tells whether r modifies resources. REST queries are all named
+// get-*, GraphQL mutations are told from the query document, whose body is
+// restored after being inspected
+func isMutation(r *http.Request) (bool, error) {
+	if strings.HasPrefix(r.URL.Path, "/v2/api/") {
+		return !strings.HasPrefix(r.URL.Path, "/v2/api/get-"), nil
+	}
+	if r.Body == nil || r.Body == http.NoBody {
+		return false, nil
+	}
+	body, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	var payload struct {
+		Query string `json:"query"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return false, nil
+	}
+	return strings.HasPrefix(strings.TrimSpace(payload.Query), "mutation"), nil
+}
+
+// clusterSlots caps the VMs being created or cloned on each cluster
+type clusterSlots struct {
+	size  int
+	mu    sync.Mutex
+	slots map[string]semaphore
+}
+
+func newClusterSlots(size int) *clusterSlots {
+	return &clusterSlots{
+		size:  size,
+		slots: make(map[string]semaphore),
+	}
+}
+
+func (c *clusterSlots) get(clusterId string) semaphore {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.slots[clusterId]
+	if !ok {
+		s = newSemaphore(c.size)
+		c.slots[clusterId] = s
+	}
+	return s
+}
@@ -0,0 +1,198 @@
+package cloudtower
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// concurrencyServer records the most requests it has been serving at once, for
+// the REST mutations and for the others
+type concurrencyServer struct {
+	mu           sync.Mutex
+	requests     int
+	mutations    int
+	queries      int
+	maxMutations int
+	maxQueries   int
+}
+
+func (s *concurrencyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	mutation := !strings.HasPrefix(r.URL.Path, "/v2/api/get-")
+	s.mu.Lock()
+	s.requests++
+	if mutation {
+		s.mutations++
+		s.maxMutations = max(s.maxMutations, s.mutations)
+	} else {
+		s.queries++
+		s.maxQueries = max(s.maxQueries, s.queries)
+	}
+	s.mu.Unlock()
+	time.Sleep(50 * time.Millisecond)
+	s.mu.Lock()
+	if mutation {
+		s.mutations--
+	} else {
+		s.queries--
+	}
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusOK)
+}
+
+// sendConcurrently sends a request to each of paths at once and waits for them
+func sendConcurrently(t *testing.T, transport http.RoundTripper, url string, paths []string) {
+	t.Helper()
+	client := &http.Client{Transport: transport}
+	var wg sync.WaitGroup
+	for _, path := range paths {
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+			resp, err := client.Post(url+path, "application/json", strings.NewReader(`{}`))
+			if err != nil {
+				t.Errorf("Post(%s) error = %v", path, err)
+				return
+			}
+			resp.Body.Close()
+		}(path)
+	}
+	wg.Wait()
+}
+
+func TestRateLimitTransportRequestsPerSecond(t *testing.T) {
+	srv := &concurrencyServer{}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	transport := newRateLimitTransport(http.DefaultTransport, RateLimitConfig{RequestsPerSecond: 50})
+
+	start := time.Now()
+	sendConcurrently(t, transport, ts.URL, []string{
+		"/v2/api/get-vms", "/v2/api/get-vms", "/v2/api/get-vms",
+		"/v2/api/get-vms", "/v2/api/get-vms", "/v2/api/get-vms",
+	})
+	// the first request is sent at once, the following ones 20ms apart
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("6 requests are sent in %v at 50 requests per second, want at least 100ms", elapsed)
+	}
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.requests != 6 {
+		t.Errorf("server received %d requests, want 6", srv.requests)
+	}
+}
+
+func TestRateLimitTransportMaxConcurrentMutations(t *testing.T) {
+	srv := &concurrencyServer{}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	transport := newRateLimitTransport(http.DefaultTransport, RateLimitConfig{MaxConcurrentMutations: 1})
+
+	sendConcurrently(t, transport, ts.URL, []string{
+		"/v2/api/create-vm", "/v2/api/delete-vm", "/v2/api/update-vm",
+		"/v2/api/get-vms", "/v2/api/get-vms", "/v2/api/get-vms",
+	})
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.maxMutations != 1 {
+		t.Errorf("%d mutations are sent at once, want 1", srv.maxMutations)
+	}
+	if srv.maxQueries < 2 {
+		t.Errorf("%d queries are sent at once, want them not to be capped", srv.maxQueries)
+	}
+}
+
+func TestNewRateLimitTransportUnlimited(t *testing.T) {
+	if transport := newRateLimitTransport(http.DefaultTransport, RateLimitConfig{}); transport != http.DefaultTransport {
+		t.Errorf("newRateLimitTransport() = %T, want the inner transport when nothing is limited", transport)
+	}
+}
+
+func TestIsMutation(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		body string
+		want bool
+	}{
+		{
+			name: "rest query",
+			path: "/v2/api/get-vms",
+			body: `{"where":{}}`,
+		},
+		{
+			name: "rest mutation",
+			path: "/v2/api/create-vm",
+			body: `[{"name":"vm"}]`,
+			want: true,
+		},
+		{
+			name: "graphql query",
+			path: "/api",
+			body: `{"query":"query { vms { id } }"}`,
+		},
+		{
+			name: "graphql shorthand query",
+			path: "/api",
+			body: `{"query":"{ vms { id } }"}`,
+		},
+		{
+			name: "graphql mutation",
+			path: "/api",
+			body: `{"query":" mutation { deleteVm(where: {}) { id } }"}`,
+			want: true,
+		},
+		{
+			name: "not json",
+			path: "/api",
+			body: `mutation`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			got, err := isMutation(r)
+			if err != nil {
+				t.Fatalf("isMutation() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("isMutation() = %v, want %v", got, tt.want)
+			}
+			// the body is sent after being inspected
+			if body, _ := io.ReadAll(r.Body); string(body) != tt.body {
+				t.Errorf("body = %q after isMutation(), want %q", body, tt.body)
+			}
+		})
+	}
+}
+
+func TestClusterSlots(t *testing.T) {
+	slots := newClusterSlots(1)
+	if err := slots.get("cluster-1").acquire(context.Background()); err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	// other clusters have their own slots
+	if err := slots.get("cluster-2").acquire(context.Background()); err != nil {
+		t.Fatalf("acquire() of another cluster error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := slots.get("cluster-1").acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("acquire() of a full cluster error = %v, want the deadline to be exceeded", err)
+	}
+	slots.get("cluster-1").release()
+	if err := slots.get("cluster-1").acquire(context.Background()); err != nil {
+		t.Errorf("acquire() after release error = %v", err)
+	}
+
+	if err := newClusterSlots(0).get("cluster-1").acquire(ctx); err != nil {
+		t.Errorf("acquire() of unlimited slots error = %v", err)
+	}
+}
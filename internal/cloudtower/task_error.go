@@ -0,0 +1,22 @@
+package cloudtower
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/smartxworks/cloudtower-go-sdk/v2/models"
+)
+
+// TaskError is returned when one or more waited tasks failed, it keeps the
+// failed tasks so callers are able to report them in detail
+type TaskError struct {
+	Tasks []*models.Task
+}
+
+func (e *TaskError) Error() string {
+	messages := make([]string, 0, len(e.Tasks))
+	for _, t := range e.Tasks {
+		messages = append(messages, fmt.Sprintf("task %s failed: %s", StrValue(t.ID), StrValue(t.ErrorMessage)))
+	}
+	return strings.Join(messages, "; ")
+}
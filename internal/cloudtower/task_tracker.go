@@ -0,0 +1,126 @@
+package cloudtower
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/utils"
+	apiclient "github.com/smartxworks/cloudtower-go-sdk/v2/client"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/client/task"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/models"
+)
+
+const DefaultTaskPollInterval = 5 * time.Second
+
+type taskResult struct {
+	task *models.Task
+	err  error
+}
+
+// taskTracker polls all the tasks waited by a Client with a single GetTasks
+// query per tick, and fans the finished tasks out to their waiters
+type taskTracker struct {
+	api      *apiclient.Cloudtower
+	interval time.Duration
+	retry    utils.RetryWithExponentialBackoffOptions
+
+	mu      sync.Mutex
+	waiters map[string][]chan taskResult
+	running bool
+}
+
+func newTaskTracker(api *apiclient.Cloudtower, interval time.Duration, retry utils.RetryWithExponentialBackoffOptions) *taskTracker {
+	if interval <= 0 {
+		interval = DefaultTaskPollInterval
+	}
+	return &taskTracker{
+		api:      api,
+		interval: interval,
+		retry:    retry,
+		waiters:  make(map[string][]chan taskResult),
+	}
+}
+
+func (t *taskTracker) watch(taskId string) chan taskResult {
+	ch := make(chan taskResult, 1)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.waiters[taskId] = append(t.waiters[taskId], ch)
+	if !t.running {
+		t.running = true
+		go t.run()
+	}
+	return ch
+}
+
+func (t *taskTracker) unwatch(taskId string, ch chan taskResult) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	chans := t.waiters[taskId]
+	for i, c := range chans {
+		if c == ch {
+			chans = append(chans[:i], chans[i+1:]...)
+			break
+		}
+	}
+	if len(chans) == 0 {
+		delete(t.waiters, taskId)
+	} else {
+		t.waiters[taskId] = chans
+	}
+}
+
+func (t *taskTracker) run() {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		t.mu.Lock()
+		if len(t.waiters) == 0 {
+			// nothing to wait, the next watch will start polling again
+			t.running = false
+			t.mu.Unlock()
+			return
+		}
+		taskIds := make([]string, 0, len(t.waiters))
+		for id := range t.waiters {
+			taskIds = append(taskIds, id)
+		}
+		t.mu.Unlock()
+
+		t.poll(taskIds)
+	}
+}
+
+func (t *taskTracker) poll(taskIds []string) {
+	tasksParams := task.NewGetTasksParams()
+	tasksParams.RequestBody = &models.GetTasksRequestBody{
+		Where: &models.TaskWhereInput{
+			IDIn: taskIds,
+		},
+	}
+	tasksResp, err := utils.RetryWithExponentialBackoff(context.Background(), func() (*task.GetTasksOK, error) {
+		return t.api.Task.GetTasks(tasksParams)
+	}, t.retry)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err != nil {
+		for _, id := range taskIds {
+			t.notify(id, taskResult{err: err})
+		}
+		return
+	}
+	for _, v := range tasksResp.Payload {
+		if *v.Status == models.TaskStatusSUCCESSED || *v.Status == models.TaskStatusFAILED {
+			t.notify(*v.ID, taskResult{task: v})
+		}
+	}
+}
+
+func (t *taskTracker) notify(taskId string, result taskResult) {
+	for _, ch := range t.waiters[taskId] {
+		ch <- result
+	}
+	delete(t.waiters, taskId)
+}
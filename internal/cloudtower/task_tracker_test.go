@@ -0,0 +1,189 @@
+package cloudtower
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	httptransport "github.com/go-openapi/runtime/client"
+	"github.com/go-openapi/strfmt"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/utils"
+	apiclient "github.com/smartxworks/cloudtower-go-sdk/v2/client"
+)
+
+const testTaskPollInterval = 20 * time.Millisecond
+
+// taskServer answers get-tasks with the status of each task queried, status is
+// told how many times the tasks have been polled, an empty status fails the query
+type taskServer struct {
+	mu      sync.Mutex
+	queries [][]string
+	status  func(id string, polls int) string
+}
+
+func (s *taskServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v2/api/get-tasks" {
+		http.NotFound(w, r)
+		return
+	}
+	var body struct {
+		Where struct {
+			IDIn []string `json:"id_in"`
+		} `json:"where"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	s.queries = append(s.queries, body.Where.IDIn)
+	polls := len(s.queries)
+	s.mu.Unlock()
+	tasks := make([]map[string]any, 0, len(body.Where.IDIn))
+	for _, id := range body.Where.IDIn {
+		status := s.status(id, polls)
+		if status == "" {
+			http.Error(w, "cloudtower is unavailable", http.StatusInternalServerError)
+			return
+		}
+		task := map[string]any{"id": id, "status": status}
+		if status == "FAILED" {
+			task["error_message"] = "disk is full"
+		}
+		tasks = append(tasks, task)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tasks)
+}
+
+func (s *taskServer) polls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.queries)
+}
+
+func newTaskTestClient(t *testing.T, srv *taskServer) *Client {
+	t.Helper()
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+	transport := httptransport.New(strings.TrimPrefix(ts.URL, "http://"), "/v2/api", []string{"http"})
+	api := apiclient.New(transport, strfmt.Default)
+	retry := utils.RetryWithExponentialBackoffOptions{MaxRetries: 1, InitialBackoff: time.Millisecond}
+	return &Client{
+		Api:          api,
+		RetryOptions: retry,
+		tasks:        newTaskTracker(api, testTaskPollInterval, retry),
+	}
+}
+
+func TestWaitTasksFinish(t *testing.T) {
+	srv := &taskServer{
+		status: func(id string, polls int) string {
+			switch {
+			case polls <= 3:
+				return "PROCESSING"
+			case id == "task-2":
+				return "FAILED"
+			}
+			return "SUCCESSED"
+		},
+	}
+	c := newTaskTestClient(t, srv)
+
+	waits := [][]string{
+		{"task-1"},
+		{"task-1"},
+		{"task-1"},
+		{"task-1", "task-2"},
+		{"task-2", "task-1"},
+	}
+	errs := make([]error, len(waits))
+	var wg sync.WaitGroup
+	for i, ids := range waits {
+		wg.Add(1)
+		go func(i int, ids []string) {
+			defer wg.Done()
+			_, errs[i] = c.WaitTasksFinish(context.Background(), ids)
+		}(i, ids)
+	}
+	wg.Wait()
+
+	for i, ids := range waits {
+		var taskErr *TaskError
+		if len(ids) == 1 {
+			if errs[i] != nil {
+				t.Errorf("WaitTasksFinish(%v) error = %v, want nil", ids, errs[i])
+			}
+		} else if !errors.As(errs[i], &taskErr) || len(taskErr.Tasks) != 1 || *taskErr.Tasks[0].ID != "task-2" {
+			t.Errorf("WaitTasksFinish(%v) error = %v, want task-2 to fail", ids, errs[i])
+		}
+	}
+	// the waiters share a single query per tick, instead of polling on their own
+	if polls := srv.polls(); polls != 4 {
+		t.Errorf("tasks are polled %d times by %d waiters, want 4", polls, len(waits))
+	}
+	srv.mu.Lock()
+	for _, ids := range srv.queries {
+		if len(ids) > 2 {
+			t.Errorf("tasks %v are queried, want each task once", ids)
+		}
+	}
+	srv.mu.Unlock()
+
+	// the tracker stops polling once nothing is waited
+	time.Sleep(3 * testTaskPollInterval)
+	c.tasks.mu.Lock()
+	running := c.tasks.running
+	c.tasks.mu.Unlock()
+	if running {
+		t.Errorf("task tracker keeps running without waiters")
+	}
+}
+
+func TestWaitTasksFinishPollFailure(t *testing.T) {
+	srv := &taskServer{
+		status: func(id string, polls int) string {
+			return ""
+		},
+	}
+	c := newTaskTestClient(t, srv)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.WaitTasksFinish(context.Background(), []string{"task-1"}); err == nil {
+				t.Errorf("WaitTasksFinish() error = nil, want the poll failure")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestWaitTasksFinishCanceled(t *testing.T) {
+	srv := &taskServer{
+		status: func(id string, polls int) string {
+			return "PROCESSING"
+		},
+	}
+	c := newTaskTestClient(t, srv)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*testTaskPollInterval)
+	defer cancel()
+	_, err := c.WaitTasksFinish(ctx, []string{"task-1"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitTasksFinish() error = %v, want the deadline to be exceeded", err)
+	}
+	c.tasks.mu.Lock()
+	defer c.tasks.mu.Unlock()
+	if len(c.tasks.waiters) != 0 {
+		t.Errorf("task tracker keeps %d waiters after the wait is canceled", len(c.tasks.waiters))
+	}
+}
@@ -0,0 +1,90 @@
+package cloudtower
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+type TLSConfig struct {
+	// CACertFile is a path to a PEM-encoded CA bundle used to verify the server certificate
+	CACertFile string
+	// CACert is a PEM-encoded CA bundle, appended to the bundle loaded from CACertFile
+	CACert string
+	// ClientCertFile and ClientKeyFile are paths to a PEM-encoded client certificate and key
+	ClientCertFile string
+	ClientKeyFile  string
+	// ClientCert and ClientKey are a PEM-encoded client certificate and key,
+	// ignored when ClientCertFile is set
+	ClientCert         string
+	ClientKey          string
+	InsecureSkipVerify bool
+}
+
+func buildTLSConfig(cfg TLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if cfg.CACertFile != "" || cfg.CACert != "" {
+		// start from the system pool so public CAs keep working alongside the custom bundle
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if cfg.CACertFile != "" {
+			pem, err := os.ReadFile(cfg.CACertFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA bundle %s: %w", cfg.CACertFile, err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no valid certificate found in CA bundle %s", cfg.CACertFile)
+			}
+		}
+		if cfg.CACert != "" {
+			if !pool.AppendCertsFromPEM([]byte(cfg.CACert)) {
+				return nil, errors.New("no valid certificate found in ca_cert")
+			}
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	switch {
+	case cfg.ClientCertFile != "" || cfg.ClientKeyFile != "":
+		if cfg.ClientCertFile == "" || cfg.ClientKeyFile == "" {
+			return nil, errors.New("client_cert_file and client_key_file must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCertFile, cfg.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	case cfg.ClientCert != "" || cfg.ClientKey != "":
+		if cfg.ClientCert == "" || cfg.ClientKey == "" {
+			return nil, errors.New("client_cert and client_key must be set together")
+		}
+		cert, err := tls.X509KeyPair([]byte(cfg.ClientCert), []byte(cfg.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// newHttpTransport returns the transport shared by the REST and GraphQL clients,
+// so both of them trust the same CAs and present the same client certificate
+func newHttpTransport(cfg TLSConfig) (http.RoundTripper, error) {
+	tlsConfig, err := buildTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
@@ -0,0 +1,120 @@
+package cloudtower
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTLSServer starts a HTTPS server and returns it with its self-signed certificate in PEM
+func newTLSServer(t *testing.T) (*httptest.Server, string) {
+	t.Helper()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	return srv, string(cert)
+}
+
+func TestHttpTransport(t *testing.T) {
+	srv, cert := newTLSServer(t)
+	certFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(certFile, []byte(cert), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		cfg     TLSConfig
+		wantErr string
+	}{
+		{
+			name:    "server certificate not trusted",
+			cfg:     TLSConfig{},
+			wantErr: "certificate",
+		},
+		{
+			name: "ca cert",
+			cfg:  TLSConfig{CACert: cert},
+		},
+		{
+			name: "ca cert file",
+			cfg:  TLSConfig{CACertFile: certFile},
+		},
+		{
+			name: "insecure skip verify",
+			cfg:  TLSConfig{InsecureSkipVerify: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, err := newHttpTransport(tt.cfg)
+			if err != nil {
+				t.Fatalf("newHttpTransport() error = %v", err)
+			}
+			resp, err := (&http.Client{Transport: transport}).Get(srv.URL)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Get() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Get() error = %v, want nil", err)
+			}
+			resp.Body.Close()
+		})
+	}
+}
+
+func TestBuildTLSConfig(t *testing.T) {
+	_, cert := newTLSServer(t)
+	tests := []struct {
+		name    string
+		cfg     TLSConfig
+		wantErr string
+	}{
+		{
+			name:    "missing ca cert file",
+			cfg:     TLSConfig{CACertFile: filepath.Join(t.TempDir(), "missing.pem")},
+			wantErr: "failed to read CA bundle",
+		},
+		{
+			name:    "invalid ca cert",
+			cfg:     TLSConfig{CACert: "not a certificate"},
+			wantErr: "no valid certificate found in ca_cert",
+		},
+		{
+			name:    "client cert file without key",
+			cfg:     TLSConfig{ClientCertFile: "client.pem"},
+			wantErr: "client_cert_file and client_key_file must be set together",
+		},
+		{
+			name:    "client cert without key",
+			cfg:     TLSConfig{ClientCert: cert},
+			wantErr: "client_cert and client_key must be set together",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := buildTLSConfig(tt.cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("buildTLSConfig() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("insecure skip verify", func(t *testing.T) {
+		tlsConfig, err := buildTLSConfig(TLSConfig{InsecureSkipVerify: true})
+		if err != nil {
+			t.Fatalf("buildTLSConfig() error = %v", err)
+		}
+		if !tlsConfig.InsecureSkipVerify || tlsConfig.RootCAs != nil {
+			t.Errorf("buildTLSConfig() = %+v, want InsecureSkipVerify with the system CAs", tlsConfig)
+		}
+	})
+}
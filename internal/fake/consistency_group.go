@@ -0,0 +1,161 @@
+package fake
+
+import "fmt"
+
+// setConsistencyGroup sets the fields, VMs and volumes of a consistency group, they have to be
+// in its cluster and in no other consistency group
+func (s *Server) setConsistencyGroup(group object, params object) error {
+	set(group, params, "name", "description")
+	members := map[string]string{"vms": "vms", "vm_volumes": "vm-volumes"}
+	for field, collection := range members {
+		where := params[field]
+		if where == nil {
+			continue
+		}
+		refs := make([]any, 0)
+		for _, member := range s.store.findBy(collection, asObject(where)) {
+			if asObject(member["cluster"])["id"] != asObject(group["cluster"])["id"] {
+				return fmt.Errorf("%s is not in the cluster of consistency group %v", member["name"], group["name"])
+			}
+			for _, other := range s.store.collections["consistency-groups"] {
+				if other["id"] == group["id"] {
+					continue
+				}
+				for _, r := range asList(other[field]) {
+					if asObject(r)["id"] == member["id"] {
+						return fmt.Errorf("%s is already in consistency group %s", member["name"], other["name"])
+					}
+				}
+			}
+			refs = append(refs, ref(member))
+		}
+		group[field] = refs
+	}
+	if len(asList(group["vms"]))+len(asList(group["vm_volumes"])) == 0 {
+		return fmt.Errorf("consistency group %v has neither vm nor vm volume", group["name"])
+	}
+	return nil
+}
+
+func createConsistencyGroup(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	for _, item := range asList(body) {
+		params := asObject(item)
+		cluster := s.store.find("clusters", params["cluster_id"])
+		if cluster == nil {
+			return nil, notFound("cluster", params["cluster_id"])
+		}
+		group := object{
+			"description": "",
+			"cluster":     ref(cluster),
+			"vms":         []any{},
+			"vm_volumes":  []any{},
+		}
+		if err := s.setConsistencyGroup(group, params); err != nil {
+			return nil, badRequest("%v", err)
+		}
+		group = s.store.insert("consistency-groups", group)
+		taskId := s.newTask("createConsistencyGroup", "ConsistencyGroup", group["id"], nil)
+		result = append(result, withTask(taskId, group))
+	}
+	return result, nil
+}
+
+func updateConsistencyGroup(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	data := asObject(asObject(body)["data"])
+	for _, group := range s.withWhere("consistency-groups", body) {
+		group := group
+		taskId := s.newTask("updateConsistencyGroup", "ConsistencyGroup", group["id"], func() error {
+			return s.setConsistencyGroup(group, data)
+		})
+		result = append(result, withTask(taskId, group))
+	}
+	return result, nil
+}
+
+// deleteConsistencyGroup deletes consistency groups which have no snapshot left
+func deleteConsistencyGroup(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	for _, group := range s.withWhere("consistency-groups", body) {
+		id := group["id"]
+		if len(s.store.findBy("consistency-group-snapshots", object{"consistency_group": object{"id": id}})) > 0 {
+			return nil, badRequest("consistency group %s still has snapshots", group["name"])
+		}
+		taskId := s.newTask("deleteConsistencyGroup", "ConsistencyGroup", id, func() error {
+			s.store.remove("consistency-groups", id)
+			return nil
+		})
+		result = append(result, withTask(taskId, object{"id": id}))
+	}
+	return result, nil
+}
+
+// createConsistencyGroupSnapshot snapshots every VM and volume of a consistency group at once,
+// the snapshots are taken when the mutation is answered rather than when its task finishes
+func createConsistencyGroupSnapshot(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	for _, item := range asList(body) {
+		params := asObject(item)
+		group := s.store.find("consistency-groups", params["consistency_group_id"])
+		if group == nil {
+			return nil, notFound("consistency group", params["consistency_group_id"])
+		}
+		snapshot := s.store.insert("consistency-group-snapshots", object{
+			"name":              params["name"],
+			"consistency_group": ref(group),
+			"cluster":           group["cluster"],
+		})
+		vmSnapshots := make([]any, 0)
+		for _, member := range asList(group["vms"]) {
+			vm := s.store.find("vms", asObject(member)["id"])
+			if vm == nil {
+				continue
+			}
+			vmSnapshot := s.insertVmSnapshot(vm, fmt.Sprintf("%s-%s", snapshot["name"], vm["name"]), "CRASH_CONSISTENT")
+			vmSnapshot["consistency_group_snapshot"] = ref(snapshot)
+			vmSnapshots = append(vmSnapshots, ref(vmSnapshot))
+		}
+		volumeSnapshots := make([]any, 0)
+		for _, member := range asList(group["vm_volumes"]) {
+			volume := s.store.find("vm-volumes", asObject(member)["id"])
+			if volume == nil {
+				continue
+			}
+			volumeSnapshot := s.store.insert("vm-volume-snapshots", object{
+				"name":                       fmt.Sprintf("%s-%s", snapshot["name"], volume["name"]),
+				"vm_volume":                  ref(volume),
+				"size":                       volume["size"],
+				"cluster":                    volume["cluster"],
+				"consistency_group_snapshot": ref(snapshot),
+			})
+			volumeSnapshots = append(volumeSnapshots, ref(volumeSnapshot))
+		}
+		snapshot["vm_snapshots"] = vmSnapshots
+		snapshot["vm_volume_snapshots"] = volumeSnapshots
+		taskId := s.newTask("createConsistencyGroupSnapshot", "ConsistencyGroupSnapshot", snapshot["id"], nil)
+		result = append(result, withTask(taskId, snapshot))
+	}
+	return result, nil
+}
+
+// deleteConsistencyGroupSnapshot deletes consistency group snapshots with their VM and volume snapshots
+func deleteConsistencyGroupSnapshot(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	for _, snapshot := range s.withWhere("consistency-group-snapshots", body) {
+		snapshot := snapshot
+		id := snapshot["id"]
+		taskId := s.newTask("deleteConsistencyGroupSnapshot", "ConsistencyGroupSnapshot", id, func() error {
+			for _, r := range asList(snapshot["vm_snapshots"]) {
+				s.store.remove("vm-snapshots", asObject(r)["id"])
+			}
+			for _, r := range asList(snapshot["vm_volume_snapshots"]) {
+				s.store.remove("vm-volume-snapshots", asObject(r)["id"])
+			}
+			s.store.remove("consistency-group-snapshots", id)
+			return nil
+		})
+		result = append(result, withTask(taskId, object{"id": id}))
+	}
+	return result, nil
+}
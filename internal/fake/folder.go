@@ -0,0 +1,78 @@
+package fake
+
+import "fmt"
+
+func createVmFolder(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	for _, item := range asList(body) {
+		params := asObject(item)
+		cluster := s.store.find("clusters", params["cluster_id"])
+		if cluster == nil {
+			return nil, notFound("cluster", params["cluster_id"])
+		}
+		folder := s.store.insert("vm-folders", object{
+			"name":    params["name"],
+			"cluster": ref(cluster),
+			"vm_num":  0,
+		})
+		taskId := s.newTask("createVmFolder", "VmFolder", folder["id"], nil)
+		result = append(result, withTask(taskId, folder))
+	}
+	return result, nil
+}
+
+func updateVmFolder(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	data := asObject(asObject(body)["data"])
+	for _, folder := range s.withWhere("vm-folders", body) {
+		folder := folder
+		taskId := s.newTask("updateVmFolder", "VmFolder", folder["id"], func() error {
+			set(folder, data, "name")
+			s.refresh()
+			return nil
+		})
+		result = append(result, withTask(taskId, folder))
+	}
+	return result, nil
+}
+
+// deleteVmFolder deletes folders, the VMs in them are kept out of any folder
+func deleteVmFolder(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	for _, folder := range s.withWhere("vm-folders", body) {
+		id := folder["id"]
+		taskId := s.newTask("deleteVmFolder", "VmFolder", id, func() error {
+			for _, vm := range s.store.findBy("vms", object{"folder": object{"id": id}}) {
+				vm["folder"] = nil
+			}
+			s.store.remove("vm-folders", id)
+			return nil
+		})
+		result = append(result, withTask(taskId, object{"id": id}))
+	}
+	return result, nil
+}
+
+// addVmToFolder moves VMs into a folder of their cluster
+func addVmToFolder(s *Server, body any) (any, error) {
+	return s.operateVms(body, "addVmToFolder", func(vm object, data object) error {
+		folder := s.store.find("vm-folders", data["folder_id"])
+		if folder == nil {
+			return fmt.Errorf("vm folder %v not found", data["folder_id"])
+		}
+		if asObject(folder["cluster"])["id"] != asObject(vm["cluster"])["id"] {
+			return fmt.Errorf("vm folder %s is not in the cluster of vm %s", folder["name"], vm["name"])
+		}
+		vm["folder"] = ref(folder)
+		s.refresh()
+		return nil
+	})
+}
+
+func removeVmFromFolder(s *Server, body any) (any, error) {
+	return s.operateVms(body, "removeVmToFolder", func(vm object, data object) error {
+		vm["folder"] = nil
+		s.refresh()
+		return nil
+	})
+}
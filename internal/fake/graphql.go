@@ -0,0 +1,172 @@
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type graphqlRequest struct {
+	Query         string         `json:"query"`
+	Variables     map[string]any `json:"variables"`
+	OperationName string         `json:"operationName"`
+}
+
+// serveGraphql answers the GraphQL operations the provider sends, errors are
+// reported within a 200 response like CloudTower does
+func (s *Server) serveGraphql(w http.ResponseWriter, r *http.Request, body []byte) {
+	var req graphqlRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, badRequest("invalid graphql request: %v", err))
+		return
+	}
+	switch {
+	case strings.Contains(req.Query, "authnStrategies"):
+		writeJSON(w, http.StatusOK, object{"data": object{"authnStrategies": []any{}}})
+	case strings.Contains(req.Query, "updateVm("):
+		if !s.authenticated(r) {
+			writeGraphqlError(w, "UNAUTHENTICATED", "token is invalid or expired")
+			return
+		}
+		id, err := s.updateVm(normalize(req.Variables))
+		if err != nil {
+			writeGraphqlError(w, "BAD_USER_INPUT", err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, object{"data": object{"updateVm": object{"id": id}}})
+	default:
+		writeGraphqlError(w, "GRAPHQL_VALIDATION_FAILED", "operation is not supported by the fake CloudTower")
+	}
+}
+
+func writeGraphqlError(w http.ResponseWriter, code string, message string) {
+	writeJSON(w, http.StatusOK, object{
+		"data": nil,
+		"errors": []any{object{
+			"message":    message,
+			"extensions": object{"code": code},
+		}},
+	})
+}
+
+// updateVm starts an updateVm task, the VM is updated when the task finishes
+func (s *Server) updateVm(variables object) (any, error) {
+	where := asObject(variables["where"])
+	vm := s.store.find("vms", where["id"])
+	if vm == nil {
+		return nil, notFound("vm", where["id"])
+	}
+	data := asObject(variables["data"])
+	s.newTask("updateVm", "Vm", vm["id"], func() error {
+		return s.applyVmUpdate(vm, data)
+	})
+	return vm["id"], nil
+}
+
+func (s *Server) applyVmUpdate(vm object, data object) error {
+	set(vm, data, "name", "memory", "ha", "description", "vcpu", "hostname", "dns_servers")
+	if cpu := asObject(data["cpu"]); cpu != nil {
+		merged := asObject(vm["cpu"])
+		if merged == nil {
+			merged = object{}
+		}
+		set(merged, cpu, "cores", "sockets")
+		vm["cpu"] = merged
+	}
+	defer s.refresh()
+
+	nics := asObject(data["vm_nics"])
+	for _, item := range asList(nics["delete"]) {
+		s.store.remove("vm-nics", asObject(item)["id"])
+	}
+	for _, item := range asList(nics["update"]) {
+		update := asObject(item)
+		nic := s.store.find("vm-nics", asObject(update["where"])["id"])
+		if nic == nil {
+			return notFound("vm nic", asObject(update["where"])["id"])
+		}
+		if err := s.updateNic(nic, asObject(update["data"])); err != nil {
+			return err
+		}
+	}
+	for _, item := range asList(nics["create"]) {
+		nic := asObject(item)
+		vlanId := asObject(asObject(nic["vlan"])["connect"])["id"]
+		vlan := s.store.find("vlans", vlanId)
+		if vlan == nil {
+			return notFound("vlan", vlanId)
+		}
+		s.insertNic(vm, vlan, nic)
+	}
+
+	disks := asObject(data["vm_disks"])
+	for _, item := range asList(disks["delete"]) {
+		s.store.remove("vm-disks", asObject(item)["id"])
+	}
+	for _, item := range asList(disks["update"]) {
+		update := asObject(item)
+		disk := s.store.find("vm-disks", asObject(update["where"])["id"])
+		if disk == nil {
+			return notFound("vm disk", asObject(update["where"])["id"])
+		}
+		if err := s.updateDisk(vm, disk, asObject(update["data"])); err != nil {
+			return err
+		}
+	}
+	for _, item := range asList(disks["create"]) {
+		params := asObject(item)
+		diskType := str(params["type"])
+		if diskType == "" {
+			diskType = "DISK"
+		}
+		disk := s.insertDisk(vm, params["boot"], params["bus"], diskType, nil, nil)
+		if err := s.updateDisk(vm, disk, params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateDisk applies the elf_image and vm_volume relations of a disk updation or creation
+func (s *Server) updateDisk(vm object, disk object, data object) error {
+	set(disk, data, "boot", "bus", "key", "disabled")
+	if image := asObject(data["elf_image"]); image != nil {
+		if image["disconnect"] == true {
+			disk["elf_image"] = nil
+		} else if id := asObject(image["connect"])["id"]; id != nil {
+			found := s.store.find("elf-images", id)
+			if found == nil {
+				return notFound("elf image", id)
+			}
+			disk["elf_image"] = ref(found)
+		}
+	}
+	volume := asObject(data["vm_volume"])
+	switch {
+	case volume == nil:
+	case volume["disconnect"] == true:
+		disk["vm_volume"] = nil
+	case volume["update"] != nil:
+		current := s.store.find("vm-volumes", asObject(disk["vm_volume"])["id"])
+		if current == nil {
+			return notFound("vm volume", asObject(disk["vm_volume"])["id"])
+		}
+		size := asObject(volume["update"])["size"]
+		if number(size) < number(current["size"]) {
+			return fmt.Errorf("vm volume %s can not shrink", current["name"])
+		}
+		current["size"] = size
+	case volume["create"] != nil:
+		created := s.insertVolume(asObject(vm["cluster"]), asObject(volume["create"]))
+		disk["vm_volume"] = ref(created)
+	case volume["connect"] != nil:
+		id := asObject(volume["connect"])["id"]
+		found := s.store.find("vm-volumes", id)
+		if found == nil {
+			return notFound("vm volume", id)
+		}
+		disk["vm_volume"] = ref(found)
+	}
+	return nil
+}
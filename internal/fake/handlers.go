@@ -0,0 +1,406 @@
+package fake
+
+import (
+	"fmt"
+	"time"
+)
+
+var mutationHandlers = map[string]mutationHandler{
+	"create-datacenter": createDatacenter,
+	"update-datacenter": updateDatacenter,
+	"delete-datacenter": deleteDatacenter,
+
+	"connect-cluster": connectCluster,
+	"update-cluster":  updateCluster,
+	"delete-cluster":  deleteCluster,
+
+	"create-vm":               createVm,
+	"clone-vm":                cloneVm,
+	"create-vm-from-template": createVmFromTemplate,
+	"create-vm-from-content-library-template": createVmFromContentLibraryTemplate,
+	"rebuild-vm":                rebuildVm,
+	"delete-vm":                 deleteVm,
+	"start-vm":                  vmStatusChange("startVm", "RUNNING"),
+	"restart-vm":                vmStatusChange("restartVm", "RUNNING"),
+	"resume-vm":                 vmStatusChange("resumeVm", "RUNNING"),
+	"shut-down-vm":              vmStatusChange("shutDownVm", "STOPPED"),
+	"poweroff-vm":               vmStatusChange("poweroffVm", "STOPPED"),
+	"suspend-vm":                vmStatusChange("suspendVm", "SUSPENDED"),
+	"migrate-vm":                migrateVm,
+	"migrate-vm-across-cluster": migrateVmAcrossCluster,
+	"rollback-vm":               rollbackVm,
+
+	"add-vm-disk":    addVmDisk,
+	"remove-vm-disk": removeVmDisk,
+
+	"create-vm-folder":    createVmFolder,
+	"update-vm-folder":    updateVmFolder,
+	"delete-vm-folder":    deleteVmFolder,
+	"add-vm-to-folder":    addVmToFolder,
+	"remove-vm-to-folder": removeVmFromFolder,
+
+	"create-vm-placement-group": createVmPlacementGroup,
+	"update-vm-placement-group": updateVmPlacementGroup,
+	"delete-vm-placement-group": deleteVmPlacementGroup,
+
+	"update-elf-image": updateElfImage,
+	"delete-elf-image": deleteElfImage,
+
+	"create-vm-volume": createVmVolume,
+	"update-vm-volume": updateVmVolume,
+	"delete-vm-volume": deleteVmVolume,
+
+	"create-vds": createVds,
+	"update-vds": updateVds,
+	"delete-vds": deleteVds,
+
+	"create-vm-vlan": createVmVlan,
+	"update-vm-vlan": updateVmVlan,
+	"delete-vlan":    deleteVlan,
+
+	"create-label":                 createLabel,
+	"update-label":                 updateLabel,
+	"delete-label":                 deleteLabel,
+	"add-labels-to-resources":      addLabelsToResources,
+	"remove-labels-from-resources": removeLabelsFromResources,
+
+	"create-vm-snapshot": createVmSnapshot,
+	"delete-vm-snapshot": deleteVmSnapshot,
+
+	"create-consistency-group":          createConsistencyGroup,
+	"update-consistency-group":          updateConsistencyGroup,
+	"delete-consistency-group":          deleteConsistencyGroup,
+	"create-consistency-group-snapshot": createConsistencyGroupSnapshot,
+	"delete-consistency-group-snapshot": deleteConsistencyGroupSnapshot,
+
+	"create-snapshot-plan":  createSnapshotPlan,
+	"update-snapshot-plan":  updateSnapshotPlan,
+	"delete-snapshot-plan":  deleteSnapshotPlan,
+	"suspend-snapshot-plan": snapshotPlanStatusChange("suspendSnapshotPlan", "SUSPENDED"),
+	"resume-snapshot-plan":  snapshotPlanStatusChange("resumeSnapshotPlan", "NORMAL"),
+
+	"clone-vm-template-from-vm":   cloneVmTemplateFromVm,
+	"convert-vm-template-from-vm": convertVmTemplateFromVm,
+	"update-vm-template":          updateVmTemplate,
+	"delete-vm-template":          deleteVmTemplate,
+
+	"clone-content-library-vm-template-from-vm": cloneContentLibraryVmTemplateFromVm,
+	"update-content-library-vm-template":        updateContentLibraryVmTemplate,
+	"delete-content-library-vm-template":        deleteContentLibraryVmTemplate,
+}
+
+func now() string {
+	return time.Now().UTC().Format(time.RFC3339Nano)
+}
+
+func str(v any) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// set copies the non null fields of data into obj
+func set(obj object, data object, fields ...string) {
+	for _, f := range fields {
+		if v, ok := data[f]; ok && v != nil {
+			obj[f] = v
+		}
+	}
+}
+
+// withWhere finds the entities matched by the where input of an updation or deletion body
+func (s *Server) withWhere(collection string, body any) []object {
+	return s.store.findBy(collection, asObject(asObject(body)["where"]))
+}
+
+func createDatacenter(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	for _, item := range asList(body) {
+		params := asObject(item)
+		org := s.store.find("organizations", params["organization_id"])
+		if org == nil {
+			return nil, notFound("organization", params["organization_id"])
+		}
+		dc := s.store.insert("datacenters", object{
+			"name":         params["name"],
+			"organization": ref(org),
+		})
+		result = append(result, object{"task_id": nil, "data": dc})
+	}
+	return result, nil
+}
+
+func updateDatacenter(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	data := asObject(asObject(body)["data"])
+	for _, dc := range s.withWhere("datacenters", body) {
+		set(dc, data, "name")
+		result = append(result, object{"task_id": nil, "data": dc})
+	}
+	return result, nil
+}
+
+func deleteDatacenter(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	for _, dc := range s.withWhere("datacenters", body) {
+		s.store.remove("datacenters", dc["id"])
+		result = append(result, object{"task_id": nil, "data": object{"id": dc["id"]}})
+	}
+	return result, nil
+}
+
+func connectCluster(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	for _, item := range asList(body) {
+		params := asObject(item)
+		var dc object
+		if id := str(params["datacenter_id"]); id != "" {
+			if dc = s.store.find("datacenters", id); dc == nil {
+				return nil, notFound("datacenter", id)
+			}
+		}
+		ip := str(params["ip"])
+		cluster := s.insertCluster("cluster-"+ip, ip, dc)
+		taskId := s.newTask("connectCluster", "Cluster", cluster["id"], nil)
+		result = append(result, withTask(taskId, cluster))
+	}
+	return result, nil
+}
+
+func updateCluster(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	data := asObject(asObject(body)["data"])
+	for _, cluster := range s.withWhere("clusters", body) {
+		cluster := cluster
+		taskId := s.newTask("updateCluster", "Cluster", cluster["id"], func() error {
+			set(cluster, data, "ip")
+			if id := str(data["datacenter_id"]); id != "" {
+				dc := s.store.find("datacenters", id)
+				if dc == nil {
+					return fmt.Errorf("datacenter %s not found", id)
+				}
+				cluster["datacenters"] = []any{ref(dc)}
+			}
+			return nil
+		})
+		result = append(result, withTask(taskId, cluster))
+	}
+	return result, nil
+}
+
+func deleteCluster(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	for _, cluster := range s.withWhere("clusters", body) {
+		id := cluster["id"]
+		taskId := s.newTask("deleteCluster", "Cluster", id, func() error {
+			inCluster := object{"cluster": object{"id": id}}
+			for _, collection := range []string{"hosts", "vdses", "elf-storage-policies", "elf-images", "svt-images"} {
+				for _, obj := range s.store.findBy(collection, inCluster) {
+					s.store.remove(collection, obj["id"])
+				}
+			}
+			for _, vlan := range s.store.findBy("vlans", object{"vds": inCluster}) {
+				s.store.remove("vlans", vlan["id"])
+			}
+			for _, nic := range s.store.findBy("nics", object{"host": inCluster}) {
+				s.store.remove("nics", nic["id"])
+			}
+			s.store.remove("clusters", id)
+			return nil
+		})
+		result = append(result, withTask(taskId, object{"id": id}))
+	}
+	return result, nil
+}
+
+func createVmSnapshot(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	for _, item := range asList(asObject(body)["data"]) {
+		params := asObject(item)
+		vm := s.store.find("vms", params["vm_id"])
+		if vm == nil {
+			return nil, notFound("vm", params["vm_id"])
+		}
+		snapshot := s.insertVmSnapshot(vm, params["name"], params["consistent_type"])
+		taskId := s.newTask("createVmSnapshot", "VmSnapshot", snapshot["id"], nil)
+		result = append(result, withTask(taskId, snapshot))
+	}
+	return result, nil
+}
+
+// insertVmSnapshot freezes the disks and nics of vm into a snapshot
+func (s *Server) insertVmSnapshot(vm object, name any, consistentType any) object {
+	if consistentType == nil {
+		consistentType = "CRASH_CONSISTENT"
+	}
+	return s.store.insert("vm-snapshots", object{
+		"name":            name,
+		"consistent_type": consistentType,
+		"vm":              ref(vm),
+		"cluster":         vm["cluster"],
+		"vm_disks":        s.frozenDisks(vm),
+		"vm_nics":         s.frozenNics(vm),
+		"vcpu":            vm["vcpu"],
+		"memory":          vm["memory"],
+		"cpu":             vm["cpu"],
+		"firmware":        vm["firmware"],
+	})
+}
+
+func deleteVmSnapshot(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	for _, snapshot := range s.withWhere("vm-snapshots", body) {
+		id := snapshot["id"]
+		taskId := s.newTask("deleteVmSnapshot", "VmSnapshot", id, func() error {
+			s.store.remove("vm-snapshots", id)
+			return nil
+		})
+		result = append(result, withTask(taskId, object{"id": id}))
+	}
+	return result, nil
+}
+
+// newVmTemplate freezes the disks and nics of vm into a template on the cluster
+func (s *Server) newVmTemplate(vm object, cluster object, params object) object {
+	cloudInitSupported := params["cloud_init_supported"]
+	if cloudInitSupported == nil {
+		cloudInitSupported = false
+	}
+	return s.store.insert("vm-templates", object{
+		"name":                 params["name"],
+		"description":          str(params["description"]),
+		"cloud_init_supported": cloudInitSupported,
+		"cluster":              ref(cluster),
+		"vcpu":                 vm["vcpu"],
+		"memory":               vm["memory"],
+		"cpu":                  vm["cpu"],
+		"ha":                   vm["ha"],
+		"firmware":             vm["firmware"],
+		"clock_offset":         "UTC",
+		"win_opt":              false,
+		"vm_disks":             s.frozenDisks(vm),
+		"vm_nics":              s.frozenNics(vm),
+	})
+}
+
+func cloneVmTemplateFromVm(s *Server, body any) (any, error) {
+	return s.vmTemplateFromVm(body, "cloneVmTemplateFromVm", false)
+}
+
+func convertVmTemplateFromVm(s *Server, body any) (any, error) {
+	return s.vmTemplateFromVm(body, "convertVmTemplateFromVm", true)
+}
+
+func (s *Server) vmTemplateFromVm(body any, mutation string, convert bool) (any, error) {
+	result := make([]any, 0)
+	for _, item := range asList(body) {
+		params := asObject(item)
+		vm := s.store.find("vms", params["vm_id"])
+		if vm == nil {
+			return nil, notFound("vm", params["vm_id"])
+		}
+		cluster := s.store.find("clusters", asObject(vm["cluster"])["id"])
+		template := s.newVmTemplate(vm, cluster, params)
+		var effect func() error
+		if convert {
+			effect = func() error {
+				s.removeVm(vm["id"])
+				return nil
+			}
+		}
+		taskId := s.newTask(mutation, "VmTemplate", template["id"], effect)
+		result = append(result, withTask(taskId, template))
+	}
+	return result, nil
+}
+
+func updateVmTemplate(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	data := asObject(asObject(body)["data"])
+	for _, template := range s.withWhere("vm-templates", body) {
+		set(template, data, "name", "description", "cloud_init_supported")
+		result = append(result, object{"task_id": nil, "data": template})
+	}
+	return result, nil
+}
+
+func deleteVmTemplate(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	for _, template := range s.withWhere("vm-templates", body) {
+		id := template["id"]
+		taskId := s.newTask("deleteVmTemplate", "VmTemplate", id, func() error {
+			s.store.remove("vm-templates", id)
+			return nil
+		})
+		result = append(result, withTask(taskId, object{"id": id}))
+	}
+	return result, nil
+}
+
+func cloneContentLibraryVmTemplateFromVm(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	for _, item := range asList(body) {
+		params := asObject(item)
+		vmId := asObject(params["vm"])["id"]
+		vm := s.store.find("vms", vmId)
+		if vm == nil {
+			return nil, notFound("vm", vmId)
+		}
+		clusters := s.store.findBy("clusters", asObject(params["clusters"]))
+		if len(clusters) == 0 {
+			return nil, badRequest("no cluster to distribute the template to")
+		}
+		clt := s.store.insert("content-library-vm-templates", object{
+			"name":                 params["name"],
+			"description":          str(params["description"]),
+			"cloud_init_supported": params["cloud_init_supported"],
+			"created_at":           now(),
+		})
+		templates := make([]any, 0, len(clusters))
+		clusterRefs := make([]any, 0, len(clusters))
+		for _, cluster := range clusters {
+			template := s.newVmTemplate(vm, cluster, params)
+			template["content_library_vm_template"] = ref(clt)
+			templates = append(templates, ref(template, "cluster"))
+			clusterRefs = append(clusterRefs, ref(cluster))
+		}
+		clt["vm_templates"] = templates
+		clt["clusters"] = clusterRefs
+		clt["vcpu"] = vm["vcpu"]
+		clt["memory"] = vm["memory"]
+		clt["cpu"] = vm["cpu"]
+		taskId := s.newTask("cloneContentLibraryVmTemplateFromVm", "ContentLibraryVmTemplate", clt["id"], nil)
+		result = append(result, withTask(taskId, clt))
+	}
+	return result, nil
+}
+
+func updateContentLibraryVmTemplate(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	data := asObject(asObject(body)["data"])
+	for _, clt := range s.withWhere("content-library-vm-templates", body) {
+		set(clt, data, "name", "description", "cloud_init_supported")
+		for _, template := range s.store.findBy("vm-templates", object{"content_library_vm_template": object{"id": clt["id"]}}) {
+			set(template, data, "name", "description", "cloud_init_supported")
+		}
+		result = append(result, object{"task_id": nil, "data": clt})
+	}
+	return result, nil
+}
+
+func deleteContentLibraryVmTemplate(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	for _, clt := range s.withWhere("content-library-vm-templates", body) {
+		id := clt["id"]
+		taskId := s.newTask("deleteContentLibraryVmTemplate", "ContentLibraryVmTemplate", id, func() error {
+			for _, template := range s.store.findBy("vm-templates", object{"content_library_vm_template": object{"id": id}}) {
+				s.store.remove("vm-templates", template["id"])
+			}
+			s.store.remove("content-library-vm-templates", id)
+			return nil
+		})
+		result = append(result, withTask(taskId, object{"id": id}))
+	}
+	return result, nil
+}
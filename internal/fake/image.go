@@ -0,0 +1,182 @@
+package fake
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// FailUploadChunks makes n chunks uploaded to upload-elf-image fail with a server error
+// once after chunks more have been received, as if the connection dropped
+func (s *Server) FailUploadChunks(after int, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.uploadFailAfter = after
+	s.uploadFailures = n
+	s.uploadTaskLost = false
+}
+
+// LoseUploadTask makes the chunk uploaded to upload-elf-image after chunks more have been
+// received fail with a server error, and removes its upload task as if the cluster lost it
+func (s *Server) LoseUploadTask(after int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.uploadFailAfter = after
+	s.uploadFailures = 1
+	s.uploadTaskLost = true
+}
+
+// uploadElfImage implements the multipart upload-elf-image endpoint. The first chunk of
+// a file starts an upload task, whose chunk_size is the size of that chunk, the following
+// chunks name the task by upload_task_id and are appended in order. The elf image is
+// created in the cluster once size bytes have been received and recorded as the
+// elf_image_id of the task args, its sha256 is recorded so the tests can tell what was uploaded.
+func (s *Server) uploadElfImage(w http.ResponseWriter, r *http.Request, body []byte) {
+	_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || params["boundary"] == "" {
+		writeError(w, badRequest("upload-elf-image expects a multipart form"))
+		return
+	}
+	form, err := multipart.NewReader(bytes.NewReader(body), params["boundary"]).ReadForm(int64(len(body)) + 1)
+	if err != nil {
+		writeError(w, badRequest("invalid multipart form: %v", err))
+		return
+	}
+	field := func(name string) string {
+		if values := form.Value[name]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	var chunk []byte
+	if files := form.File["file"]; len(files) > 0 {
+		f, err := files[0].Open()
+		if err != nil {
+			writeError(w, badRequest("invalid file: %v", err))
+			return
+		}
+		chunk, err = io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			writeError(w, badRequest("invalid file: %v", err))
+			return
+		}
+	}
+	if len(chunk) == 0 {
+		writeError(w, badRequest("file is empty"))
+		return
+	}
+	if s.uploadFailAfter > 0 {
+		s.uploadFailAfter--
+	} else if s.uploadFailures > 0 {
+		s.uploadFailures--
+		if id := field("upload_task_id"); s.uploadTaskLost && id != "" {
+			s.store.remove("upload-tasks", id)
+			delete(s.uploads, id)
+		}
+		writeError(w, &apiError{status: http.StatusServiceUnavailable, message: "connection to the cluster is lost"})
+		return
+	}
+
+	var task object
+	if id := field("upload_task_id"); id != "" {
+		if task = s.store.find("upload-tasks", id); task == nil {
+			writeError(w, notFound("upload task", id))
+			return
+		}
+		if task["status"] != "UPLOADING" {
+			writeError(w, badRequest("upload task %s is %v", id, task["status"]))
+			return
+		}
+	} else {
+		cluster := s.store.find("clusters", field("cluster_id"))
+		if cluster == nil {
+			writeError(w, notFound("cluster", field("cluster_id")))
+			return
+		}
+		size, err := strconv.ParseInt(field("size"), 10, 64)
+		if err != nil || size <= 0 {
+			writeError(w, badRequest("invalid size %q", field("size")))
+			return
+		}
+		task = s.store.insert("upload-tasks", object{
+			"status":        "UPLOADING",
+			"resource_type": "ELF_IMAGE",
+			"size":          size,
+			"chunk_size":    len(chunk),
+			"current_chunk": 0,
+			"args": object{
+				"name":        field("name"),
+				"description": field("description"),
+				"cluster_id":  cluster["id"],
+				"size":        field("size"),
+			},
+			"started_at": time.Now().UTC().Format(time.RFC3339Nano),
+		})
+	}
+
+	id := task["id"].(string)
+	received := append(s.uploads[id], chunk...)
+	size := int(number(task["size"]))
+	if len(received) > size || (len(received) < size && len(chunk) != int(number(task["chunk_size"]))) {
+		writeError(w, badRequest("chunk %.0f of upload task %s has %d bytes, expected %.0f", number(task["current_chunk"]), id, len(chunk), number(task["chunk_size"])))
+		return
+	}
+	s.uploads[id] = received
+	task["current_chunk"] = number(task["current_chunk"]) + 1
+	if len(received) == size {
+		args := asObject(task["args"])
+		sum := sha256.Sum256(received)
+		image := s.store.insert("elf-images", object{
+			"name":        args["name"],
+			"description": args["description"],
+			"path":        fmt.Sprintf("/isos/%s", args["name"]),
+			"size":        size,
+			"sha256":      hex.EncodeToString(sum[:]),
+			"cluster":     ref(s.store.find("clusters", args["cluster_id"])),
+		})
+		args["elf_image_id"] = image["id"]
+		delete(s.uploads, id)
+		task["status"] = "SUCCESSED"
+		task["finished_at"] = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+	writeJSON(w, http.StatusOK, []any{normalize(task)})
+}
+
+func updateElfImage(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	data := asObject(asObject(body)["data"])
+	for _, image := range s.withWhere("elf-images", body) {
+		image := image
+		taskId := s.newTask("updateElfImage", "ElfImage", image["id"], func() error {
+			set(image, data, "name", "description")
+			return nil
+		})
+		result = append(result, withTask(taskId, image))
+	}
+	return result, nil
+}
+
+// deleteElfImage deletes elf images which no cd-rom is loaded with
+func deleteElfImage(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	for _, image := range s.withWhere("elf-images", body) {
+		id := image["id"]
+		if len(s.store.findBy("vm-disks", object{"elf_image": object{"id": id}})) > 0 {
+			return nil, badRequest("elf image %s is used by vm cd-roms", image["name"])
+		}
+		taskId := s.newTask("deleteElfImage", "ElfImage", id, func() error {
+			s.store.remove("elf-images", id)
+			return nil
+		})
+		result = append(result, withTask(taskId, object{"id": id}))
+	}
+	return result, nil
+}
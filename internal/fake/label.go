@@ -0,0 +1,146 @@
+package fake
+
+// labelledCollections maps the fields of AddLabelsToResourcesParamsData to the
+// collections of the resources labels can be added to
+var labelledCollections = map[string]string{
+	"vms":                          "vms",
+	"hosts":                        "hosts",
+	"clusters":                     "clusters",
+	"vm_volumes":                   "vm-volumes",
+	"vm_templates":                 "vm-templates",
+	"content_library_vm_templates": "content-library-vm-templates",
+}
+
+func createLabel(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	for _, item := range asList(body) {
+		params := asObject(item)
+		value := params["value"]
+		if value == nil {
+			value = ""
+		}
+		if len(s.store.findBy("labels", object{"key": params["key"], "value": value})) > 0 {
+			return nil, badRequest("label %v=%v already exists", params["key"], value)
+		}
+		label := s.store.insert("labels", object{
+			"key":       params["key"],
+			"value":     value,
+			"total_num": 0,
+			"vm_num":    0,
+		})
+		taskId := s.newTask("createLabel", "Label", label["id"], nil)
+		result = append(result, withTask(taskId, label))
+	}
+	return result, nil
+}
+
+func updateLabel(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	data := asObject(asObject(body)["data"])
+	for _, label := range s.withWhere("labels", body) {
+		label := label
+		taskId := s.newTask("updateLabel", "Label", label["id"], func() error {
+			set(label, data, "key", "value")
+			s.refresh()
+			return nil
+		})
+		result = append(result, withTask(taskId, label))
+	}
+	return result, nil
+}
+
+// deleteLabel deletes labels and removes them from the resources labelled with them
+func deleteLabel(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	for _, label := range s.withWhere("labels", body) {
+		id := label["id"]
+		taskId := s.newTask("deleteLabel", "Label", id, func() error {
+			s.store.remove("labels", id)
+			s.refresh()
+			return nil
+		})
+		result = append(result, withTask(taskId, object{"id": id}))
+	}
+	return result, nil
+}
+
+func addLabelsToResources(s *Server, body any) (any, error) {
+	return s.labelResources(body, "addLabelsToResources", func(obj object, label object) {
+		for _, l := range asList(obj["labels"]) {
+			if asObject(l)["id"] == label["id"] {
+				return
+			}
+		}
+		obj["labels"] = append(asList(obj["labels"]), ref(label, "key", "value"))
+	})
+}
+
+func removeLabelsFromResources(s *Server, body any) (any, error) {
+	return s.labelResources(body, "removeLabelsFromResources", func(obj object, label object) {
+		labels := make([]any, 0)
+		for _, l := range asList(obj["labels"]) {
+			if asObject(l)["id"] != label["id"] {
+				labels = append(labels, l)
+			}
+		}
+		obj["labels"] = labels
+	})
+}
+
+// labelResources applies effect to every label matched by the where input and
+// every resource matched by the data of a body, each label gets a task
+func (s *Server) labelResources(body any, mutation string, effect func(obj object, label object)) (any, error) {
+	result := make([]any, 0)
+	data := asObject(asObject(body)["data"])
+	for _, label := range s.withWhere("labels", body) {
+		label := label
+		taskId := s.newTask(mutation, "Label", label["id"], func() error {
+			for field, collection := range labelledCollections {
+				where := asObject(data[field])
+				if where == nil {
+					continue
+				}
+				for _, obj := range s.store.findBy(collection, where) {
+					effect(obj, label)
+				}
+			}
+			s.refresh()
+			return nil
+		})
+		result = append(result, withTask(taskId, label))
+	}
+	return result, nil
+}
+
+// refreshLabels updates the labels embedded in resources and the number of resources of labels
+func (s *Server) refreshLabels() {
+	counts := make(map[any]map[string]int)
+	for _, collection := range labelledCollections {
+		for _, obj := range s.store.collections[collection] {
+			if obj["labels"] == nil {
+				continue
+			}
+			labels := make([]any, 0)
+			for _, l := range asList(obj["labels"]) {
+				label := s.store.find("labels", asObject(l)["id"])
+				if label == nil {
+					continue
+				}
+				labels = append(labels, ref(label, "key", "value"))
+				if counts[label["id"]] == nil {
+					counts[label["id"]] = make(map[string]int)
+				}
+				counts[label["id"]][collection]++
+			}
+			obj["labels"] = labels
+		}
+	}
+	for _, label := range s.store.collections["labels"] {
+		total := 0
+		for _, n := range counts[label["id"]] {
+			total += n
+		}
+		label["vm_num"] = counts[label["id"]]["vms"]
+		label["total_num"] = total
+	}
+}
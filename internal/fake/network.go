@@ -0,0 +1,165 @@
+package fake
+
+func createVds(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	for _, item := range asList(body) {
+		params := asObject(item)
+		cluster := s.store.find("clusters", params["cluster_id"])
+		if cluster == nil {
+			return nil, notFound("cluster", params["cluster_id"])
+		}
+		bondMode := params["bond_mode"]
+		if bondMode == nil {
+			bondMode = "ACTIVE_BACKUP"
+		}
+		nics, err := s.vdsNics(cluster, nil, params["nic_ids"])
+		if err != nil {
+			return nil, err
+		}
+		vds := s.store.insert("vdses", object{
+			"name":      params["name"],
+			"bond_mode": bondMode,
+			"cluster":   ref(cluster),
+			"nics":      nics,
+		})
+		taskId := s.newTask("createVds", "Vds", vds["id"], nil)
+		result = append(result, withTask(taskId, vds))
+	}
+	return result, nil
+}
+
+func updateVds(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	data := asObject(asObject(body)["data"])
+	for _, vds := range s.withWhere("vdses", body) {
+		vds := vds
+		var nics []any
+		if data["nic_ids"] != nil {
+			var err error
+			if nics, err = s.vdsNics(asObject(vds["cluster"]), vds, data["nic_ids"]); err != nil {
+				return nil, err
+			}
+		}
+		taskId := s.newTask("updateVds", "Vds", vds["id"], func() error {
+			set(vds, data, "name", "bond_mode")
+			if nics != nil {
+				vds["nics"] = nics
+			}
+			for _, vlan := range s.store.findBy("vlans", object{"vds": object{"id": vds["id"]}}) {
+				vlan["vds"] = ref(vds, "cluster")
+			}
+			return nil
+		})
+		result = append(result, withTask(taskId, vds))
+	}
+	return result, nil
+}
+
+// vdsNics returns the uplinks of a vds, which are nics of the hosts of its cluster no other vds uses
+func (s *Server) vdsNics(cluster object, vds object, ids any) ([]any, error) {
+	if len(asList(ids)) == 0 {
+		return nil, badRequest("a vds needs at least one nic")
+	}
+	nics := make([]any, 0)
+	for _, id := range asList(ids) {
+		nic := s.store.find("nics", id)
+		if nic == nil {
+			return nil, notFound("nic", id)
+		}
+		if asObject(asObject(nic["host"])["cluster"])["id"] != cluster["id"] {
+			return nil, badRequest("nic %v is not on a host of cluster %v", id, cluster["name"])
+		}
+		for _, other := range s.store.findBy("vdses", object{"cluster": object{"id": cluster["id"]}}) {
+			if vds != nil && other["id"] == vds["id"] {
+				continue
+			}
+			for _, used := range asList(other["nics"]) {
+				if asObject(used)["id"] == id {
+					return nil, badRequest("nic %v is used by vds %v", id, other["name"])
+				}
+			}
+		}
+		nics = append(nics, ref(nic))
+	}
+	return nics, nil
+}
+
+// deleteVds deletes vdses which have no vlans left
+func deleteVds(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	for _, vds := range s.withWhere("vdses", body) {
+		vds := vds
+		if len(s.store.findBy("vlans", object{"vds": object{"id": vds["id"]}})) > 0 {
+			return nil, badRequest("vds %s still has vlans", vds["name"])
+		}
+		taskId := s.newTask("deleteVds", "Vds", vds["id"], func() error {
+			s.store.remove("vdses", vds["id"])
+			return nil
+		})
+		result = append(result, withTask(taskId, object{"id": vds["id"]}))
+	}
+	return result, nil
+}
+
+var vlanQosFields = []string{"qos_min_bandwidth", "qos_max_bandwidth", "qos_burst"}
+
+func createVmVlan(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	for _, item := range asList(body) {
+		params := asObject(item)
+		vds := s.store.find("vdses", params["vds_id"])
+		if vds == nil {
+			return nil, notFound("vds", params["vds_id"])
+		}
+		if id := number(params["vlan_id"]); id < 0 || id > 4094 {
+			return nil, badRequest("vlan id %v is out of range", params["vlan_id"])
+		}
+		vlan := s.store.insert("vlans", object{
+			"name":    params["name"],
+			"vlan_id": params["vlan_id"],
+			"type":    "VM",
+			"vds":     ref(vds, "cluster"),
+		})
+		set(vlan, params, vlanQosFields...)
+		taskId := s.newTask("createVmVlan", "Vlan", vlan["id"], nil)
+		result = append(result, withTask(taskId, vlan))
+	}
+	return result, nil
+}
+
+func updateVmVlan(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	data := asObject(asObject(body)["data"])
+	for _, vlan := range s.withWhere("vlans", body) {
+		vlan := vlan
+		if vlan["type"] != "VM" {
+			return nil, badRequest("vlan %s is not a VM vlan", vlan["name"])
+		}
+		taskId := s.newTask("updateVmVlan", "Vlan", vlan["id"], func() error {
+			set(vlan, data, append([]string{"name", "vlan_id"}, vlanQosFields...)...)
+			for _, nic := range s.store.findBy("vm-nics", object{"vlan": object{"id": vlan["id"]}}) {
+				nic["vlan"] = ref(vlan, "vlan_id")
+			}
+			return nil
+		})
+		result = append(result, withTask(taskId, vlan))
+	}
+	return result, nil
+}
+
+// deleteVlan deletes vlans which are not used by any VM nic
+func deleteVlan(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	for _, vlan := range s.withWhere("vlans", body) {
+		vlan := vlan
+		if len(s.store.findBy("vm-nics", object{"vlan": object{"id": vlan["id"]}})) > 0 {
+			return nil, badRequest("vlan %s is used by VM nics", vlan["name"])
+		}
+		taskId := s.newTask("deleteVlan", "Vlan", vlan["id"], func() error {
+			s.store.remove("vlans", vlan["id"])
+			return nil
+		})
+		result = append(result, withTask(taskId, object{"id": vlan["id"]}))
+	}
+	return result, nil
+}
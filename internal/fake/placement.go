@@ -0,0 +1,154 @@
+package fake
+
+import "fmt"
+
+// placementFields are the policy fields of a placement group taken as they are from the params
+var placementFields = []string{
+	"name",
+	"description",
+	"enabled",
+	"vm_vm_policy",
+	"vm_vm_policy_enabled",
+	"vm_host_must_enabled",
+	"vm_host_must_policy",
+	"vm_host_prefer_enabled",
+	"vm_host_prefer_policy",
+}
+
+// setPlacement sets the fields, the hosts and the VMs of a placement group,
+// the hosts and VMs have to be in the cluster of the group
+func (s *Server) setPlacement(group object, params object) error {
+	clusterId := asObject(group["cluster"])["id"]
+	set(group, params, placementFields...)
+	for _, field := range []string{"vm_host_must_host_uuids", "vm_host_prefer_host_uuids"} {
+		ids, ok := params[field]
+		if !ok || ids == nil {
+			continue
+		}
+		hosts := make([]any, 0)
+		for _, id := range asList(ids) {
+			host := s.store.find("hosts", id)
+			if host == nil || asObject(host["cluster"])["id"] != clusterId {
+				return fmt.Errorf("host %v not found in the cluster of vm placement group %v", id, group["name"])
+			}
+			hosts = append(hosts, ref(host))
+		}
+		group[field] = hosts
+	}
+	if where := params["vms"]; where != nil {
+		vms := make([]any, 0)
+		for _, vm := range s.store.findBy("vms", asObject(where)) {
+			if asObject(vm["cluster"])["id"] != clusterId {
+				return fmt.Errorf("vm %s is not in the cluster of vm placement group %v", vm["name"], group["name"])
+			}
+			vms = append(vms, ref(vm))
+		}
+		group["vms"] = vms
+	}
+	return nil
+}
+
+func createVmPlacementGroup(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	for _, item := range asList(body) {
+		params := asObject(item)
+		cluster := s.store.find("clusters", params["cluster_id"])
+		if cluster == nil {
+			return nil, notFound("cluster", params["cluster_id"])
+		}
+		group := object{
+			"description":               "",
+			"enabled":                   true,
+			"cluster":                   ref(cluster),
+			"vm_vm_policy_enabled":      false,
+			"vm_host_must_enabled":      false,
+			"vm_host_prefer_enabled":    false,
+			"vm_host_must_host_uuids":   []any{},
+			"vm_host_prefer_host_uuids": []any{},
+			"vms":                       []any{},
+		}
+		if err := s.setPlacement(group, params); err != nil {
+			return nil, badRequest("%v", err)
+		}
+		group = s.store.insert("vm-placement-groups", group)
+		taskId := s.newTask("createVmPlacementGroup", "VmPlacementGroup", group["id"], nil)
+		result = append(result, withTask(taskId, group))
+	}
+	return result, nil
+}
+
+func updateVmPlacementGroup(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	data := asObject(asObject(body)["data"])
+	for _, group := range s.withWhere("vm-placement-groups", body) {
+		group := group
+		taskId := s.newTask("updateVmPlacementGroup", "VmPlacementGroup", group["id"], func() error {
+			return s.setPlacement(group, data)
+		})
+		result = append(result, withTask(taskId, group))
+	}
+	return result, nil
+}
+
+func deleteVmPlacementGroup(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	for _, group := range s.withWhere("vm-placement-groups", body) {
+		id := group["id"]
+		taskId := s.newTask("deleteVmPlacementGroup", "VmPlacementGroup", id, func() error {
+			s.store.remove("vm-placement-groups", id)
+			return nil
+		})
+		result = append(result, withTask(taskId, object{"id": id}))
+	}
+	return result, nil
+}
+
+// placementViolation tells which must policy of the enabled placement groups of vm
+// is broken by running it on host, the prefer policies are not enforced
+func (s *Server) placementViolation(vm object, host object) error {
+	hasRef := func(refs any, id any) bool {
+		for _, r := range asList(refs) {
+			if asObject(r)["id"] == id {
+				return true
+			}
+		}
+		return false
+	}
+	for _, group := range s.store.collections["vm-placement-groups"] {
+		if group["enabled"] != true || !hasRef(group["vms"], vm["id"]) {
+			continue
+		}
+		if group["vm_host_must_enabled"] == true && hasRef(group["vm_host_must_host_uuids"], host["id"]) != (group["vm_host_must_policy"] == true) {
+			return fmt.Errorf("host %s breaks the vm host policy of vm placement group %s", host["name"], group["name"])
+		}
+		if group["vm_vm_policy_enabled"] != true {
+			continue
+		}
+		for _, member := range asList(group["vms"]) {
+			other := s.store.find("vms", asObject(member)["id"])
+			if other == nil || other["id"] == vm["id"] || other["host"] == nil {
+				continue
+			}
+			sameHost := asObject(other["host"])["id"] == host["id"]
+			if group["vm_vm_policy"] == "MUST_DIFFERENT" && sameHost || group["vm_vm_policy"] == "MUST_SAME" && !sameHost {
+				return fmt.Errorf("host %s breaks the vm vm policy of vm placement group %s", host["name"], group["name"])
+			}
+		}
+	}
+	return nil
+}
+
+// scheduleHost chooses a host of the cluster of vm satisfying its placement groups,
+// the current host is kept when it does
+func (s *Server) scheduleHost(vm object) (object, error) {
+	hosts := s.store.findBy("hosts", object{"cluster": object{"id": asObject(vm["cluster"])["id"]}})
+	if current := s.store.find("hosts", asObject(vm["host"])["id"]); current != nil {
+		hosts = append([]object{current}, hosts...)
+	}
+	for _, host := range hosts {
+		if host["status"] == "CONNECTED_HEALTHY" && s.placementViolation(vm, host) == nil {
+			return host, nil
+		}
+	}
+	return nil, fmt.Errorf("no host satisfies the vm placement groups of vm %s", vm["name"])
+}
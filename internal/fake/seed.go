@@ -0,0 +1,129 @@
+package fake
+
+import "fmt"
+
+// storagePolicies are the ELF storage policies of every cluster, keyed by the
+// suffix of their local id, which is the storage_policy_uuid of frozen disks
+var storagePolicies = []struct {
+	uuid          string
+	name          string
+	replicaNum    int
+	thinProvision bool
+}{
+	{"2-thin", "REPLICA_2_THIN_PROVISION", 2, true},
+	{"2-thick", "REPLICA_2_THICK_PROVISION", 2, false},
+	{"3-thin", "REPLICA_3_THIN_PROVISION", 3, true},
+	{"3-thick", "REPLICA_3_THICK_PROVISION", 3, false},
+}
+
+func (s *Server) seed() {
+	org := s.store.insert("organizations", object{"name": "fake-organization"})
+	dc := s.store.insert("datacenters", object{
+		"name":         "fake-datacenter",
+		"organization": ref(org),
+	})
+	cluster := s.insertCluster("fake-cluster", "192.168.1.10", dc)
+	host := s.store.findBy("hosts", object{"cluster": object{"id": cluster["id"]}})[0]
+	vds := s.store.findBy("vdses", object{"cluster": object{"id": cluster["id"]}})[0]
+	vlan := s.store.findBy("vlans", object{"vds": object{"id": vds["id"]}})[0]
+	image := s.store.insert("elf-images", object{
+		"name":    "fake-image.iso",
+		"path":    "/isos/fake-image.iso",
+		"size":    float64(1 << 30),
+		"cluster": ref(cluster),
+	})
+	svtImage := s.store.insert("svt-images", object{
+		"name":    "SMTX_VMTOOLS-fake.iso",
+		"path":    "/isos/SMTX_VMTOOLS-fake.iso",
+		"size":    float64(100 << 20),
+		"version": 400,
+		"cluster": ref(cluster),
+	})
+	s.Fixtures = Fixtures{
+		OrganizationId: org["id"].(string),
+		DatacenterId:   dc["id"].(string),
+		ClusterId:      cluster["id"].(string),
+		ClusterName:    cluster["name"].(string),
+		HostId:         host["id"].(string),
+		NicId:          asObject(asList(host["nics"])[1])["id"].(string),
+		VdsId:          vds["id"].(string),
+		VlanId:         vlan["id"].(string),
+		VlanName:       vlan["name"].(string),
+		ElfImageId:     image["id"].(string),
+		SvtImageId:     svtImage["id"].(string),
+	}
+}
+
+// insertCluster adds a cluster with a host, a VDS with a VM network, and its storage policies
+func (s *Server) insertCluster(name string, ip string, dc object) object {
+	var datacenters []any
+	if dc != nil {
+		datacenters = []any{ref(dc)}
+	}
+	cluster := s.store.insert("clusters", object{
+		"name":        name,
+		"ip":          ip,
+		"type":        "SMTX_OS",
+		"datacenters": datacenters,
+	})
+	host := s.store.insert("hosts", object{
+		"name":          name + "-host",
+		"management_ip": ip,
+		"data_ip":       ip,
+		"status":        "CONNECTED_HEALTHY",
+		"cluster":       ref(cluster),
+	})
+	// the vds of the cluster uses the first nic of the host, the second one is free
+	nics := make([]any, 0)
+	for _, nicName := range []string{"eth0", "eth1"} {
+		nics = append(nics, ref(s.store.insert("nics", object{
+			"name":    nicName,
+			"mtu":     1500,
+			"up":      true,
+			"running": true,
+			"host":    ref(host, "cluster"),
+		})))
+	}
+	host["nics"] = nics
+	vds := s.store.insert("vdses", object{
+		"name":      name + "-vds",
+		"bond_mode": "ACTIVE_BACKUP",
+		"cluster":   ref(cluster),
+		"nics":      nics[:1],
+	})
+	s.store.insert("vlans", object{
+		"name":    "default",
+		"vlan_id": 0,
+		"type":    "VM",
+		"vds":     ref(vds, "cluster"),
+	})
+	for _, p := range storagePolicies {
+		s.store.insert("elf-storage-policies", object{
+			"name":           p.name,
+			"local_id":       fmt.Sprintf("%s_%s", cluster["local_id"], p.uuid),
+			"replica_num":    p.replicaNum,
+			"thin_provision": p.thinProvision,
+			"cluster":        ref(cluster),
+		})
+	}
+	return cluster
+}
+
+// storagePolicyUuid returns the storage_policy_uuid frozen disks refer to a storage policy with
+func storagePolicyUuid(name any) string {
+	for _, p := range storagePolicies {
+		if p.name == name {
+			return p.uuid
+		}
+	}
+	return storagePolicies[0].uuid
+}
+
+func storagePolicyName(uuid any) string {
+	for _, p := range storagePolicies {
+		if p.uuid == uuid {
+			return p.name
+		}
+	}
+	return storagePolicies[0].name
+}
@@ -0,0 +1,292 @@
+// Package fake implements an in-process CloudTower for the acceptance tests.
+//
+// It serves the parts of the REST and GraphQL API used by the provider from an
+// in-memory store. Mutations return tasks that finish after being polled a few
+// times, and their effects are applied when they finish, like CloudTower does.
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+const (
+	Username = "admin"
+	Password = "fake-password"
+)
+
+// Fixtures are the entities every fake CloudTower starts with
+type Fixtures struct {
+	OrganizationId string
+	DatacenterId   string
+	ClusterId      string
+	ClusterName    string
+	HostId         string
+	NicId          string // a nic of the host no vds uses
+	VdsId          string
+	VlanId         string
+	VlanName       string
+	ElfImageId     string
+	SvtImageId     string
+}
+
+type Server struct {
+	*httptest.Server
+	Fixtures Fixtures
+
+	mu       sync.Mutex
+	store    *store
+	tokens   map[string]bool
+	effects  map[string]func() error
+	failures map[string]string
+	// uploads are the bytes received by the unfinished upload tasks
+	uploads         map[string][]byte
+	uploadFailAfter int
+	uploadFailures  int
+	uploadTaskLost  bool
+	// TaskPolls is how many times a task is polled before it finishes
+	TaskPolls int
+	// GuestTools makes running VMs report VMTools running with their IP
+	// addresses and hostname, as if VMTools were installed in every guest
+	GuestTools bool
+}
+
+type mutationHandler func(s *Server, body any) (any, error)
+
+// apiError is answered as a CloudTower error body with its status code
+type apiError struct {
+	status  int
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+func badRequest(format string, args ...any) error {
+	return &apiError{status: http.StatusBadRequest, message: fmt.Sprintf(format, args...)}
+}
+
+func notFound(collection string, id any) error {
+	return &apiError{status: http.StatusNotFound, message: fmt.Sprintf("%s %v not found", collection, id)}
+}
+
+// NewServer starts a fake CloudTower, it is closed when the test finishes
+func NewServer(t interface {
+	Cleanup(func())
+}) *Server {
+	s := &Server{
+		store:     newStore(),
+		tokens:    make(map[string]bool),
+		effects:   make(map[string]func() error),
+		failures:  make(map[string]string),
+		uploads:   make(map[string][]byte),
+		TaskPolls: 2,
+	}
+	s.seed()
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Host is the address to set as cloudtower_server
+func (s *Server) Host() string {
+	return strings.TrimPrefix(s.URL, "http://")
+}
+
+// ProviderConfig is a provider block connecting to the fake CloudTower
+func (s *Server) ProviderConfig() string {
+	return fmt.Sprintf(`
+provider "cloudtower" {
+  cloudtower_server  = %q
+  username           = %q
+  password           = %q
+  user_source        = "LOCAL"
+  task_poll_interval = 1
+}
+`, s.Host(), Username, Password)
+}
+
+// Insert adds an entity to a collection, e.g. "vms" or "vm-nics", and returns its id
+func (s *Server) Insert(collection string, obj map[string]any) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.store.insert(collection, obj)["id"].(string)
+}
+
+// AddCluster adds a cluster to the datacenter of the fixtures, the cluster,
+// host, VDS and vlan fields of the returned fixtures are the ones of the new cluster
+func (s *Server) AddCluster(name string, ip string) Fixtures {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cluster := s.insertCluster(name, ip, s.store.find("datacenters", s.Fixtures.DatacenterId))
+	host := s.store.findBy("hosts", object{"cluster": object{"id": cluster["id"]}})[0]
+	vds := s.store.findBy("vdses", object{"cluster": object{"id": cluster["id"]}})[0]
+	vlan := s.store.findBy("vlans", object{"vds": object{"id": vds["id"]}})[0]
+	fixtures := s.Fixtures
+	fixtures.ClusterId = cluster["id"].(string)
+	fixtures.ClusterName = cluster["name"].(string)
+	fixtures.HostId = host["id"].(string)
+	fixtures.NicId = asObject(asList(host["nics"])[1])["id"].(string)
+	fixtures.VdsId = vds["id"].(string)
+	fixtures.VlanId = vlan["id"].(string)
+	fixtures.VlanName = vlan["name"].(string)
+	return fixtures
+}
+
+// Get returns a copy of an entity, nil if it does not exist
+func (s *Server) Get(collection string, id string) map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj := s.store.find(collection, id)
+	if obj == nil {
+		return nil
+	}
+	return normalize(obj)
+}
+
+// List returns a copy of the entities matching where, which is a CloudTower where input
+func (s *Server) List(collection string, where map[string]any) []map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]map[string]any, 0)
+	for _, obj := range s.store.findBy(collection, normalize(where)) {
+		result = append(result, normalize(obj))
+	}
+	return result
+}
+
+// Update sets fields of an entity, as if it were changed outside of Terraform
+func (s *Server) Update(collection string, id string, fields map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj := s.store.find(collection, id)
+	if obj == nil {
+		return
+	}
+	for k, v := range normalize(fields) {
+		obj[k] = v
+	}
+	s.refresh()
+}
+
+// ExpireTokens rejects all the issued tokens, so clients have to login again
+func (s *Server) ExpireTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens = make(map[string]bool)
+}
+
+// FailNextTask makes the next task of the resource mutation, e.g. createVm, fail with message
+func (s *Server) FailNextTask(mutation string, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[mutation] = message
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, badRequest("failed to read body: %v", err))
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case r.URL.Path == "/api":
+		s.serveGraphql(w, r, body)
+	case r.URL.Path == "/v2/api/login":
+		s.login(w, body)
+	case strings.HasPrefix(r.URL.Path, "/v2/api/"):
+		if !s.authenticated(r) {
+			writeJSON(w, http.StatusUnauthorized, object{"message": "unauthorized"})
+			return
+		}
+		operation := strings.TrimPrefix(r.URL.Path, "/v2/api/")
+		if operation == "upload-elf-image" {
+			s.uploadElfImage(w, r, body)
+			return
+		}
+		s.serveRest(w, operation, body)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) authenticated(r *http.Request) bool {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return s.tokens[token]
+}
+
+func (s *Server) login(w http.ResponseWriter, body []byte) {
+	var input struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if err := json.Unmarshal(body, &input); err != nil {
+		writeError(w, badRequest("invalid login input: %v", err))
+		return
+	}
+	if input.Username != Username || input.Password != Password {
+		writeError(w, badRequest("invalid username or password"))
+		return
+	}
+	token := s.store.newId("token-")
+	s.tokens[token] = true
+	writeJSON(w, http.StatusOK, object{"task_id": nil, "data": object{"token": token}})
+}
+
+func (s *Server) serveRest(w http.ResponseWriter, operation string, raw []byte) {
+	var body any
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &body); err != nil {
+			writeError(w, badRequest("invalid body: %v", err))
+			return
+		}
+	}
+	if collection, ok := strings.CutPrefix(operation, "get-"); ok {
+		result := s.store.query(collection, asObject(body))
+		if collection == "tasks" {
+			for _, task := range result {
+				s.advanceTask(task)
+			}
+		}
+		writeJSON(w, http.StatusOK, result)
+		return
+	}
+	handler, ok := mutationHandlers[operation]
+	if !ok {
+		writeError(w, &apiError{status: http.StatusNotFound, message: fmt.Sprintf("operation %s is not supported by the fake CloudTower", operation)})
+		return
+	}
+	result, err := handler(s, body)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if e, ok := err.(*apiError); ok {
+		status = e.status
+	}
+	writeJSON(w, status, object{
+		"message": err.Error(),
+		"code":    http.StatusText(status),
+		"path":    "",
+	})
+}
@@ -0,0 +1,166 @@
+package fake
+
+import (
+	"fmt"
+	"sort"
+)
+
+// snapshotPlanFields are the fields of a snapshot plan taken as they are from the params
+var snapshotPlanFields = []string{
+	"name",
+	"description",
+	"exec_type",
+	"interval",
+	"exec_h_m",
+	"exec_days",
+	"enable_window",
+	"window_start",
+	"window_end",
+	"remain_snapshot_num",
+	"consistent_type",
+	"start_time",
+}
+
+// setSnapshotPlan sets the fields and the VMs of a snapshot plan, the VMs have to be in its cluster
+func (s *Server) setSnapshotPlan(plan object, params object) error {
+	set(plan, params, snapshotPlanFields...)
+	if where := params["vms"]; where != nil {
+		vms := make([]any, 0)
+		for _, vm := range s.store.findBy("vms", asObject(where)) {
+			if asObject(vm["cluster"])["id"] != asObject(plan["cluster"])["id"] {
+				return fmt.Errorf("vm %s is not in the cluster of snapshot plan %v", vm["name"], plan["name"])
+			}
+			vms = append(vms, ref(vm))
+		}
+		if len(vms) == 0 {
+			return fmt.Errorf("snapshot plan %v has no vm", plan["name"])
+		}
+		plan["vms"] = vms
+	}
+	return nil
+}
+
+func createSnapshotPlan(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	for _, item := range asList(body) {
+		params := asObject(item)
+		cluster := s.store.find("clusters", params["cluster_id"])
+		if cluster == nil {
+			return nil, notFound("cluster", params["cluster_id"])
+		}
+		plan := object{
+			"description":        "",
+			"interval":           1,
+			"exec_h_m":           object{"hour": 0, "minute": 0},
+			"exec_days":          []any{},
+			"enable_window":      false,
+			"consistent_type":    "CRASH_CONSISTENT",
+			"status":             "NORMAL",
+			"cluster":            ref(cluster),
+			"vms":                []any{},
+			"snapshot_group_num": 0,
+		}
+		if err := s.setSnapshotPlan(plan, params); err != nil {
+			return nil, badRequest("%v", err)
+		}
+		plan = s.store.insert("snapshot-plans", plan)
+		taskId := s.newTask("createSnapshotPlan", "SnapshotPlan", plan["id"], nil)
+		result = append(result, withTask(taskId, plan))
+	}
+	return result, nil
+}
+
+func updateSnapshotPlan(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	data := asObject(asObject(body)["data"])
+	for _, plan := range s.withWhere("snapshot-plans", body) {
+		plan := plan
+		taskId := s.newTask("updateSnapshotPlan", "SnapshotPlan", plan["id"], func() error {
+			if err := s.setSnapshotPlan(plan, data); err != nil {
+				return err
+			}
+			s.pruneSnapshotPlan(plan)
+			return nil
+		})
+		result = append(result, withTask(taskId, plan))
+	}
+	return result, nil
+}
+
+// deleteSnapshotPlan deletes snapshot plans, the snapshots they took are kept
+func deleteSnapshotPlan(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	for _, plan := range s.withWhere("snapshot-plans", body) {
+		id := plan["id"]
+		taskId := s.newTask("deleteSnapshotPlan", "SnapshotPlan", id, func() error {
+			for _, group := range s.store.findBy("snapshot-groups", object{"snapshot_plan": object{"id": id}}) {
+				group["snapshot_plan"] = nil
+			}
+			s.store.remove("snapshot-plans", id)
+			return nil
+		})
+		result = append(result, withTask(taskId, object{"id": id}))
+	}
+	return result, nil
+}
+
+func snapshotPlanStatusChange(mutation string, status string) mutationHandler {
+	return func(s *Server, body any) (any, error) {
+		result := make([]any, 0)
+		for _, plan := range s.withWhere("snapshot-plans", body) {
+			plan := plan
+			taskId := s.newTask(mutation, "SnapshotPlan", plan["id"], func() error {
+				plan["status"] = status
+				return nil
+			})
+			result = append(result, withTask(taskId, plan))
+		}
+		return result, nil
+	}
+}
+
+// RunSnapshotPlan takes the snapshots of a snapshot plan as its schedule would, all of its
+// VMs are snapshotted in a snapshot group, and the groups beyond its retention are deleted.
+// A suspended plan takes no snapshot.
+func (s *Server) RunSnapshotPlan(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	plan := s.store.find("snapshot-plans", id)
+	if plan == nil || plan["status"] != "NORMAL" {
+		return
+	}
+	groupNum := number(plan["snapshot_group_num"]) + 1
+	plan["snapshot_group_num"] = groupNum
+	group := s.store.insert("snapshot-groups", object{
+		"name":          fmt.Sprintf("%s-%.0f", plan["name"], groupNum),
+		"snapshot_plan": ref(plan),
+	})
+	snapshots := make([]any, 0)
+	for _, member := range asList(plan["vms"]) {
+		vm := s.store.find("vms", asObject(member)["id"])
+		if vm == nil {
+			continue
+		}
+		snapshot := s.insertVmSnapshot(vm, fmt.Sprintf("%s-%s", group["name"], vm["name"]), plan["consistent_type"])
+		snapshot["snapshot_group"] = ref(group, "snapshot_plan")
+		snapshots = append(snapshots, ref(snapshot))
+	}
+	group["vm_snapshots"] = snapshots
+	s.pruneSnapshotPlan(plan)
+}
+
+// pruneSnapshotPlan deletes the oldest snapshot groups of plan and their snapshots
+// beyond the remain_snapshot_num of the plan
+func (s *Server) pruneSnapshotPlan(plan object) {
+	groups := s.store.findBy("snapshot-groups", object{"snapshot_plan": object{"id": plan["id"]}})
+	sort.SliceStable(groups, func(i, j int) bool {
+		return str(groups[i]["local_created_at"]) < str(groups[j]["local_created_at"])
+	})
+	for len(groups) > int(number(plan["remain_snapshot_num"])) {
+		for _, snapshot := range asList(groups[0]["vm_snapshots"]) {
+			s.store.remove("vm-snapshots", asObject(snapshot)["id"])
+		}
+		s.store.remove("snapshot-groups", groups[0]["id"])
+		groups = groups[1:]
+	}
+}
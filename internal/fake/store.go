@@ -0,0 +1,113 @@
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// store keeps the entities of every collection in insertion order, collections
+// are named after the REST API, e.g. vms for get-vms and vm-nics for get-vm-nics
+type store struct {
+	collections map[string][]object
+	seq         int
+}
+
+func newStore() *store {
+	return &store{collections: make(map[string][]object)}
+}
+
+func (s *store) newId(prefix string) string {
+	s.seq++
+	return fmt.Sprintf("%s%06d", prefix, s.seq)
+}
+
+// insert normalizes obj to its JSON form and assigns the fields every
+// CloudTower entity has, unless they are given
+func (s *store) insert(collection string, obj object) object {
+	obj = normalize(obj)
+	if _, ok := obj["id"]; !ok {
+		obj["id"] = s.newId("cl")
+	}
+	if _, ok := obj["local_id"]; !ok {
+		obj["local_id"] = s.newId("local-")
+	}
+	if _, ok := obj["local_created_at"]; !ok {
+		obj["local_created_at"] = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+	s.collections[collection] = append(s.collections[collection], obj)
+	return obj
+}
+
+func (s *store) find(collection string, id any) object {
+	for _, obj := range s.collections[collection] {
+		if equal(obj["id"], id) {
+			return obj
+		}
+	}
+	return nil
+}
+
+func (s *store) findBy(collection string, where object) []object {
+	result := make([]object, 0)
+	for _, obj := range s.collections[collection] {
+		if match(obj, where) {
+			result = append(result, obj)
+		}
+	}
+	return result
+}
+
+func (s *store) remove(collection string, id any) {
+	objs := s.collections[collection]
+	for i, obj := range objs {
+		if equal(obj["id"], id) {
+			s.collections[collection] = append(objs[:i:i], objs[i+1:]...)
+			return
+		}
+	}
+}
+
+// query implements the get-* endpoints: where, orderBy, first and skip
+func (s *store) query(collection string, body object) []object {
+	result := s.findBy(collection, asObject(body["where"]))
+	if order, ok := body["orderBy"].(string); ok {
+		orderBy(result, order)
+	}
+	if skip, ok := body["skip"].(float64); ok {
+		if int(skip) >= len(result) {
+			return []object{}
+		}
+		result = result[int(skip):]
+	}
+	if first, ok := body["first"].(float64); ok && int(first) < len(result) {
+		result = result[:int(first)]
+	}
+	return result
+}
+
+// ref is the form an entity takes when it is embedded in another one
+func ref(obj object, fields ...string) object {
+	if obj == nil {
+		return nil
+	}
+	r := object{"id": obj["id"], "name": obj["name"]}
+	for _, f := range fields {
+		r[f] = obj[f]
+	}
+	return r
+}
+
+// normalize converts v to the values produced by decoding JSON,
+// so stored entities compare equal to the conditions of requests
+func normalize(v object) object {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	var o object
+	if err := json.Unmarshal(raw, &o); err != nil {
+		panic(err)
+	}
+	return o
+}
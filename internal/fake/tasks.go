@@ -0,0 +1,72 @@
+package fake
+
+import (
+	"time"
+)
+
+// newTask records an asynchronous task of a resource mutation, effect is
+// applied to the store once the task succeeds
+func (s *Server) newTask(mutation string, resourceType string, resourceId any, effect func() error) string {
+	task := s.store.insert("tasks", object{
+		"status":            "PENDING",
+		"description":       mutation,
+		"resource_mutation": mutation,
+		"resource_type":     resourceType,
+		"resource_id":       resourceId,
+		"progress":          0,
+		"polls":             0,
+		"steps":             []any{},
+		"local_created_at":  time.Now().UTC().Format(time.RFC3339Nano),
+	})
+	id := task["id"].(string)
+	if effect != nil {
+		s.effects[id] = effect
+	}
+	return id
+}
+
+// withTask is the response of a mutation, a task paired with the mutated entity
+func withTask(taskId string, data object) object {
+	return object{"task_id": taskId, "data": normalize(data)}
+}
+
+// advanceTask moves a task one step forward each time it is polled
+func (s *Server) advanceTask(task object) {
+	status := task["status"]
+	if status == "SUCCESSED" || status == "FAILED" {
+		return
+	}
+	polls := int(task["polls"].(float64)) + 1
+	task["polls"] = float64(polls)
+	if polls < s.TaskPolls {
+		task["status"] = "EXECUTING"
+		task["progress"] = float64(polls) / float64(s.TaskPolls)
+		return
+	}
+
+	id := task["id"].(string)
+	mutation, _ := task["resource_mutation"].(string)
+	effect := s.effects[id]
+	delete(s.effects, id)
+	if message, ok := s.failures[mutation]; ok {
+		delete(s.failures, mutation)
+		s.failTask(task, message)
+		return
+	}
+	if effect != nil {
+		if err := effect(); err != nil {
+			s.failTask(task, err.Error())
+			return
+		}
+	}
+	task["status"] = "SUCCESSED"
+	task["progress"] = float64(1)
+	task["finished_at"] = time.Now().UTC().Format(time.RFC3339Nano)
+}
+
+func (s *Server) failTask(task object, message string) {
+	task["status"] = "FAILED"
+	task["error_code"] = "FAKE_TASK_FAILED"
+	task["error_message"] = message
+	task["finished_at"] = time.Now().UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,713 @@
+package fake
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// vmBody is the part shared by the bodies of every VM creation
+type vmBody struct {
+	params  object
+	cluster object
+	host    object
+}
+
+func (s *Server) vmBody(params object, fallbackCluster any) (*vmBody, error) {
+	clusterId := params["cluster_id"]
+	if clusterId == nil {
+		clusterId = fallbackCluster
+	}
+	cluster := s.store.find("clusters", clusterId)
+	if cluster == nil {
+		return nil, notFound("cluster", clusterId)
+	}
+	inCluster := object{"cluster": object{"id": cluster["id"]}}
+	var host object
+	if hostId := str(params["host_id"]); hostId != "" && hostId != "AUTO_SCHEDULE" {
+		if host = s.store.find("hosts", hostId); host == nil {
+			return nil, notFound("host", hostId)
+		}
+	} else if hosts := s.store.findBy("hosts", inCluster); len(hosts) > 0 {
+		host = hosts[0]
+	}
+	return &vmBody{params: params, cluster: cluster, host: host}, nil
+}
+
+// insertVm adds a VM from the creation params, the fields missing from them are taken from base
+func (s *Server) insertVm(b *vmBody, base object) object {
+	field := func(name string, fallback any) any {
+		if v, ok := b.params[name]; ok && v != nil {
+			return v
+		}
+		if v, ok := base[name]; ok && v != nil {
+			return v
+		}
+		return fallback
+	}
+	vcpu := field("vcpu", float64(1))
+	cpu := asObject(base["cpu"])
+	cores := b.params["cpu_cores"]
+	if cores == nil {
+		cores = cpu["cores"]
+	}
+	if cores == nil {
+		cores = float64(1)
+	}
+	sockets := b.params["cpu_sockets"]
+	if sockets == nil {
+		sockets = cpu["sockets"]
+	}
+	if sockets == nil {
+		sockets = vcpu
+	}
+	var folder any
+	if id := str(b.params["folder_id"]); id != "" {
+		folder = object{"id": id}
+		if f := s.store.find("vm-folders", id); f != nil {
+			folder = ref(f)
+		}
+	}
+	return s.store.insert("vms", object{
+		"name":            b.params["name"],
+		"cluster":         ref(b.cluster),
+		"host":            ref(b.host),
+		"vcpu":            vcpu,
+		"memory":          field("memory", float64(1<<30)),
+		"ha":              field("ha", true),
+		"firmware":        field("firmware", "BIOS"),
+		"status":          field("status", "STOPPED"),
+		"description":     field("description", ""),
+		"guest_os_type":   field("guest_os_type", "UNKNOWN"),
+		"cpu":             object{"cores": cores, "sockets": sockets},
+		"folder":          folder,
+		"vm_tools_status": "NOT_INSTALLED",
+		"in_recycle_bin":  false,
+		"vm_nics":         []any{},
+		"vm_disks":        []any{},
+	})
+}
+
+type diskSpec struct {
+	boot  float64
+	build func(vm object, boot float64) error
+}
+
+// insertDisks mounts the disks of a VMDiskParams, ordered by boot like CloudTower lists them
+func (s *Server) insertDisks(vm object, params object) error {
+	cluster := asObject(vm["cluster"])
+	specs := make([]diskSpec, 0)
+	for _, item := range asList(params["mount_cd_roms"]) {
+		p := asObject(item)
+		specs = append(specs, diskSpec{boot: number(p["boot"]), build: func(vm object, boot float64) error {
+			var image object
+			if id := str(p["elf_image_id"]); id != "" {
+				if image = s.store.find("elf-images", id); image == nil {
+					return notFound("elf image", id)
+				}
+			}
+			s.insertDisk(vm, boot, "IDE", "CD_ROM", nil, image)
+			return nil
+		}})
+	}
+	for _, item := range asList(params["mount_disks"]) {
+		p := asObject(item)
+		specs = append(specs, diskSpec{boot: number(p["boot"]), build: func(vm object, boot float64) error {
+			volume := s.store.find("vm-volumes", p["vm_volume_id"])
+			if volume == nil {
+				return notFound("vm volume", p["vm_volume_id"])
+			}
+			s.insertDisk(vm, boot, p["bus"], "DISK", volume, nil)
+			return nil
+		}})
+	}
+	for _, item := range asList(params["mount_new_create_disks"]) {
+		p := asObject(item)
+		specs = append(specs, diskSpec{boot: number(p["boot"]), build: func(vm object, boot float64) error {
+			volume := s.insertVolume(cluster, asObject(p["vm_volume"]))
+			s.insertDisk(vm, boot, p["bus"], "DISK", volume, nil)
+			return nil
+		}})
+	}
+	sort.SliceStable(specs, func(i, j int) bool { return specs[i].boot < specs[j].boot })
+	for _, spec := range specs {
+		if err := spec.build(vm, spec.boot); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) insertVolume(cluster object, params object) object {
+	policy := params["elf_storage_policy"]
+	if policy == nil {
+		policy = storagePolicyName(nil)
+	}
+	volume := s.store.insert("vm-volumes", object{
+		"name":     params["name"],
+		"size":     params["size"],
+		"cluster":  ref(cluster),
+		"mounting": false,
+		"sharing":  false,
+		"vm_disks": []any{},
+	})
+	volume["path"] = "/volumes/" + volume["id"].(string)
+	setStoragePolicy(volume, policy)
+	return volume
+}
+
+// setStoragePolicy sets the storage policy of a volume along with the replica number and provision it implies
+func setStoragePolicy(volume object, policy any) {
+	volume["elf_storage_policy"] = policy
+	for _, p := range storagePolicies {
+		if p.name == policy {
+			volume["elf_storage_policy_replica_num"] = p.replicaNum
+			volume["elf_storage_policy_thin_provision"] = p.thinProvision
+		}
+	}
+}
+
+func (s *Server) insertDisk(vm object, boot any, bus any, diskType string, volume object, image object) object {
+	if bus == nil {
+		bus = "VIRTIO"
+	}
+	disk := object{
+		"vm":        ref(vm),
+		"boot":      boot,
+		"bus":       bus,
+		"type":      diskType,
+		"key":       float64(len(s.store.collections["vm-disks"]) + 1),
+		"disabled":  false,
+		"vm_volume": nil,
+		"elf_image": nil,
+	}
+	if volume != nil {
+		disk["vm_volume"] = ref(volume)
+	}
+	if image != nil {
+		disk["elf_image"] = ref(image)
+	}
+	return s.store.insert("vm-disks", disk)
+}
+
+// insertNics connects the VM to the vlans of VMNicParams, ordered as given
+func (s *Server) insertNics(vm object, params []any) error {
+	for _, item := range params {
+		p := asObject(item)
+		vlan := s.store.find("vlans", p["connect_vlan_id"])
+		if vlan == nil {
+			return notFound("vlan", p["connect_vlan_id"])
+		}
+		s.insertNic(vm, vlan, p)
+	}
+	return nil
+}
+
+func (s *Server) insertNic(vm object, vlan object, params object) object {
+	field := func(name string, fallback any) any {
+		if v, ok := params[name]; ok && v != nil && v != "" {
+			return v
+		}
+		return fallback
+	}
+	// new nics go after the existing ones, which keep their order
+	order := 0
+	for _, nic := range s.store.findBy("vm-nics", object{"vm": object{"id": vm["id"]}}) {
+		if o := int(number(nic["order"])) + 1; o > order {
+			order = o
+		}
+	}
+	s.store.seq++
+	return s.store.insert("vm-nics", object{
+		"vm":          ref(vm),
+		"vlan":        ref(vlan, "vlan_id"),
+		"enabled":     field("enabled", true),
+		"mirror":      field("mirror", false),
+		"model":       field("model", "VIRTIO"),
+		"mac_address": field("mac_address", fmt.Sprintf("52:54:00:%02x:%02x:%02x", s.store.seq>>16&0xff, s.store.seq>>8&0xff, s.store.seq&0xff)),
+		"ip_address":  field("ip_address", ""),
+		"subnet_mask": field("subnet_mask", ""),
+		"gateway":     field("gateway", ""),
+		"order":       float64(order),
+		"nic":         nil,
+	})
+}
+
+// updateNic applies the data of a vm_nics update in place, the other fields are kept
+func (s *Server) updateNic(nic object, data object) error {
+	if connect := asObject(asObject(data["vlan"])["connect"]); connect != nil {
+		vlan := s.store.find("vlans", connect["id"])
+		if vlan == nil {
+			return notFound("vlan", connect["id"])
+		}
+		nic["vlan"] = ref(vlan, "vlan_id")
+	}
+	for _, field := range []string{"enabled", "mirror", "model", "mac_address", "ip_address", "subnet_mask", "gateway"} {
+		if v, ok := data[field]; ok && v != nil {
+			nic[field] = v
+		}
+	}
+	return nil
+}
+
+// frozenDisks are the disks of a VM as they are kept in snapshots and templates
+func (s *Server) frozenDisks(vm object) []any {
+	result := make([]any, 0)
+	for i, disk := range s.store.findBy("vm-disks", object{"vm": object{"id": vm["id"]}}) {
+		frozen := object{
+			"type":               disk["type"],
+			"boot":               disk["boot"],
+			"bus":                disk["bus"],
+			"index":              float64(i),
+			"elf_image_local_id": "",
+			"svt_image_local_id": "",
+			"disk_name":          "",
+			"path":               "",
+			"size":               float64(0),
+		}
+		if volume := s.store.find("vm-volumes", asObject(disk["vm_volume"])["id"]); volume != nil {
+			frozen["disk_name"] = volume["name"]
+			frozen["path"] = volume["path"]
+			frozen["size"] = volume["size"]
+			frozen["storage_policy_uuid"] = storagePolicyUuid(volume["elf_storage_policy"])
+		}
+		if image := s.store.find("elf-images", asObject(disk["elf_image"])["id"]); image != nil {
+			frozen["elf_image_local_id"] = image["local_id"]
+			frozen["path"] = image["path"]
+		}
+		result = append(result, frozen)
+	}
+	return result
+}
+
+func (s *Server) frozenNics(vm object) []any {
+	result := make([]any, 0)
+	for i, nic := range s.store.findBy("vm-nics", object{"vm": object{"id": vm["id"]}}) {
+		vlan := s.store.find("vlans", asObject(nic["vlan"])["id"])
+		frozen := object{
+			"enabled":     nic["enabled"],
+			"mirror":      nic["mirror"],
+			"model":       nic["model"],
+			"mac_address": nic["mac_address"],
+			"local_id":    nic["local_id"],
+			"index":       float64(i),
+		}
+		if vlan != nil {
+			frozen["vlan"] = object{"vlan_local_id": vlan["local_id"], "name": vlan["name"], "vlan_id": vlan["vlan_id"]}
+		}
+		result = append(result, frozen)
+	}
+	return result
+}
+
+// thawDisks copies frozen disks to the VM, except the ones at the removed indexes
+func (s *Server) thawDisks(vm object, frozen []any, removed map[float64]bool) {
+	cluster := asObject(vm["cluster"])
+	for _, item := range frozen {
+		f := asObject(item)
+		if removed[number(f["index"])] {
+			continue
+		}
+		if f["type"] == "CD_ROM" {
+			var image object
+			if images := s.store.findBy("elf-images", object{"local_id": f["elf_image_local_id"]}); len(images) > 0 {
+				image = images[0]
+			}
+			s.insertDisk(vm, f["boot"], f["bus"], "CD_ROM", nil, image)
+			continue
+		}
+		volume := s.insertVolume(cluster, object{
+			"name":               f["disk_name"],
+			"size":               f["size"],
+			"elf_storage_policy": storagePolicyName(f["storage_policy_uuid"]),
+		})
+		s.insertDisk(vm, f["boot"], f["bus"], "DISK", volume, nil)
+	}
+}
+
+func (s *Server) thawNics(vm object, frozen []any) {
+	for _, item := range frozen {
+		f := asObject(item)
+		vlans := s.store.findBy("vlans", object{"local_id": asObject(f["vlan"])["vlan_local_id"]})
+		if len(vlans) == 0 {
+			continue
+		}
+		s.insertNic(vm, vlans[0], object{"enabled": f["enabled"], "mirror": f["mirror"], "model": f["model"]})
+	}
+}
+
+// copyDevices gives the VM new disks and nics like the ones of src
+func (s *Server) copyDevices(vm object, src object, disks bool, nics bool) {
+	if disks {
+		s.thawDisks(vm, s.frozenDisks(src), nil)
+	}
+	if nics {
+		s.thawNics(vm, s.frozenNics(src))
+	}
+}
+
+// refresh rebuilds the references between VMs, disks, nics, volumes, folders and the groups of VMs
+func (s *Server) refresh() {
+	for _, vm := range s.store.collections["vms"] {
+		byVm := object{"vm": object{"id": vm["id"]}}
+		nics := make([]any, 0)
+		for _, nic := range s.store.findBy("vm-nics", byVm) {
+			nics = append(nics, object{"id": nic["id"]})
+		}
+		disks := make([]any, 0)
+		for _, disk := range s.store.findBy("vm-disks", byVm) {
+			disks = append(disks, object{"id": disk["id"]})
+		}
+		vm["vm_nics"] = nics
+		vm["vm_disks"] = disks
+	}
+	for _, volume := range s.store.collections["vm-volumes"] {
+		disks := make([]any, 0)
+		for _, disk := range s.store.findBy("vm-disks", object{"vm_volume": object{"id": volume["id"]}}) {
+			disks = append(disks, object{"id": disk["id"], "type": disk["type"], "vm": object{"id": asObject(disk["vm"])["id"]}})
+		}
+		volume["vm_disks"] = disks
+		volume["mounting"] = len(disks) > 0
+	}
+	for _, folder := range s.store.collections["vm-folders"] {
+		folder["vm_num"] = len(s.store.findBy("vms", object{"folder": object{"id": folder["id"]}}))
+	}
+	for _, group := range s.store.collections["consistency-groups"] {
+		for field, collection := range map[string]string{"vms": "vms", "vm_volumes": "vm-volumes"} {
+			members := make([]any, 0)
+			for _, member := range asList(group[field]) {
+				if obj := s.store.find(collection, asObject(member)["id"]); obj != nil {
+					members = append(members, ref(obj))
+				}
+			}
+			group[field] = members
+		}
+	}
+	for _, group := range s.store.collections["vm-placement-groups"] {
+		vms := make([]any, 0)
+		for _, member := range asList(group["vms"]) {
+			if vm := s.store.find("vms", asObject(member)["id"]); vm != nil {
+				vms = append(vms, ref(vm))
+			}
+		}
+		group["vms"] = vms
+	}
+	s.refreshLabels()
+}
+
+// removeVm deletes a VM with its nics and disks, and the volumes no other VM mounts
+func (s *Server) removeVm(id any) {
+	byVm := object{"vm": object{"id": id}}
+	for _, nic := range s.store.findBy("vm-nics", byVm) {
+		s.store.remove("vm-nics", nic["id"])
+	}
+	for _, disk := range s.store.findBy("vm-disks", byVm) {
+		s.store.remove("vm-disks", disk["id"])
+		volumeId := asObject(disk["vm_volume"])["id"]
+		if volumeId != nil && len(s.store.findBy("vm-disks", object{"vm_volume": object{"id": volumeId}})) == 0 {
+			s.store.remove("vm-volumes", volumeId)
+		}
+	}
+	s.store.remove("vms", id)
+	s.refresh()
+}
+
+// createVms runs create for every item of a creation body, each VM gets a task of mutation
+func (s *Server) createVms(body any, mutation string, create func(params object) (object, error)) (any, error) {
+	result := make([]any, 0)
+	for _, item := range asList(body) {
+		params := asObject(item)
+		vm, err := create(params)
+		if err != nil {
+			return nil, err
+		}
+		s.refresh()
+		if vm["status"] == "RUNNING" {
+			s.bootGuest(vm, asObject(params["cloud_init"]))
+		}
+		taskId := s.newTask(mutation, "Vm", vm["id"], nil)
+		result = append(result, withTask(taskId, vm))
+	}
+	return result, nil
+}
+
+func createVm(s *Server, body any) (any, error) {
+	return s.createVms(body, "createVm", func(params object) (object, error) {
+		b, err := s.vmBody(params, nil)
+		if err != nil {
+			return nil, err
+		}
+		vm := s.insertVm(b, nil)
+		if err := s.insertDisks(vm, asObject(params["vm_disks"])); err != nil {
+			return nil, err
+		}
+		return vm, s.insertNics(vm, asList(params["vm_nics"]))
+	})
+}
+
+func cloneVm(s *Server, body any) (any, error) {
+	return s.createVms(body, "cloneVm", func(params object) (object, error) {
+		src := s.store.find("vms", params["src_vm_id"])
+		if src == nil {
+			return nil, notFound("vm", params["src_vm_id"])
+		}
+		b, err := s.vmBody(params, asObject(src["cluster"])["id"])
+		if err != nil {
+			return nil, err
+		}
+		vm := s.insertVm(b, src)
+		disks := asObject(params["vm_disks"])
+		s.copyDevices(vm, src, disks == nil, params["vm_nics"] == nil)
+		if err := s.insertDisks(vm, disks); err != nil {
+			return nil, err
+		}
+		return vm, s.insertNics(vm, asList(params["vm_nics"]))
+	})
+}
+
+// createFromTemplate creates a VM from the frozen disks and nics of a template
+func (s *Server) createFromTemplate(params object, template object) (object, error) {
+	b, err := s.vmBody(params, asObject(template["cluster"])["id"])
+	if err != nil {
+		return nil, err
+	}
+	vm := s.insertVm(b, template)
+	operate := asObject(params["disk_operate"])
+	removed := make(map[float64]bool)
+	for _, index := range asList(asObject(operate["remove_disks"])["disk_index"]) {
+		removed[number(index)] = true
+	}
+	s.thawDisks(vm, asList(template["vm_disks"]), removed)
+	if err := s.insertDisks(vm, asObject(operate["new_disks"])); err != nil {
+		return nil, err
+	}
+	if params["vm_nics"] == nil {
+		s.thawNics(vm, asList(template["vm_nics"]))
+		return vm, nil
+	}
+	return vm, s.insertNics(vm, asList(params["vm_nics"]))
+}
+
+func createVmFromTemplate(s *Server, body any) (any, error) {
+	return s.createVms(body, "createVmFromTemplate", func(params object) (object, error) {
+		template := s.store.find("vm-templates", params["template_id"])
+		if template == nil {
+			return nil, notFound("vm template", params["template_id"])
+		}
+		return s.createFromTemplate(params, template)
+	})
+}
+
+func createVmFromContentLibraryTemplate(s *Server, body any) (any, error) {
+	return s.createVms(body, "createVmFromContentLibraryTemplate", func(params object) (object, error) {
+		byTemplate := object{"content_library_vm_template": object{"id": params["template_id"]}}
+		templates := s.store.findBy("vm-templates", byTemplate)
+		if len(templates) == 0 {
+			return nil, notFound("content library vm template", params["template_id"])
+		}
+		template := templates[0]
+		if params["cluster_id"] != nil {
+			byTemplate["cluster"] = object{"id": params["cluster_id"]}
+			inCluster := s.store.findBy("vm-templates", byTemplate)
+			if len(inCluster) == 0 {
+				return nil, badRequest("content library vm template %v is not distributed to cluster %v", params["template_id"], params["cluster_id"])
+			}
+			template = inCluster[0]
+		}
+		return s.createFromTemplate(params, template)
+	})
+}
+
+func rebuildVm(s *Server, body any) (any, error) {
+	return s.createVms(body, "rebuildVm", func(params object) (object, error) {
+		snapshot := s.store.find("vm-snapshots", params["rebuild_from_snapshot_id"])
+		if snapshot == nil {
+			return nil, notFound("vm snapshot", params["rebuild_from_snapshot_id"])
+		}
+		b, err := s.vmBody(params, asObject(snapshot["cluster"])["id"])
+		if err != nil {
+			return nil, err
+		}
+		vm := s.insertVm(b, snapshot)
+		disks := asObject(params["vm_disks"])
+		if disks == nil {
+			s.thawDisks(vm, asList(snapshot["vm_disks"]), nil)
+		}
+		if err := s.insertDisks(vm, disks); err != nil {
+			return nil, err
+		}
+		if params["vm_nics"] == nil {
+			s.thawNics(vm, asList(snapshot["vm_nics"]))
+			return vm, nil
+		}
+		return vm, s.insertNics(vm, asList(params["vm_nics"]))
+	})
+}
+
+// operateVms starts a task of mutation for every VM matched by the body, effect is applied when it finishes
+func (s *Server) operateVms(body any, mutation string, effect func(vm object, data object) error) (any, error) {
+	result := make([]any, 0)
+	data := asObject(asObject(body)["data"])
+	for _, vm := range s.withWhere("vms", body) {
+		vm := vm
+		taskId := s.newTask(mutation, "Vm", vm["id"], func() error {
+			return effect(vm, data)
+		})
+		result = append(result, withTask(taskId, vm))
+	}
+	return result, nil
+}
+
+func vmStatusChange(mutation string, status string) mutationHandler {
+	return func(s *Server, body any) (any, error) {
+		return s.operateVms(body, mutation, func(vm object, data object) error {
+			if hostId := str(data["host_id"]); hostId != "" {
+				host := s.store.find("hosts", hostId)
+				if host == nil {
+					return fmt.Errorf("host %s not found", hostId)
+				}
+				vm["host"] = ref(host)
+			}
+			vm["status"] = status
+			if status == "RUNNING" {
+				s.bootGuest(vm, nil)
+			} else if vm["vm_tools_status"] == "RUNNING" {
+				vm["vm_tools_status"] = "NOT_RUNNING"
+				vm["ips"] = ""
+			}
+			return nil
+		})
+	}
+}
+
+// bootGuest reports the guest of a running VM when GuestTools is set, the
+// hostname is the one set by cloudInit or updateVm, and defaults to the VM name
+func (s *Server) bootGuest(vm object, cloudInit object) {
+	if !s.GuestTools {
+		return
+	}
+	if hostname := str(cloudInit["hostname"]); hostname != "" {
+		vm["hostname"] = hostname
+	} else if str(vm["hostname"]) == "" {
+		vm["hostname"] = vm["name"]
+	}
+	ips := make([]string, 0)
+	for i, nic := range s.store.findBy("vm-nics", object{"vm": object{"id": vm["id"]}}) {
+		ip := str(nic["ip_address"])
+		if ip == "" {
+			ip = fmt.Sprintf("192.168.100.%d", i+10)
+		}
+		ips = append(ips, ip)
+	}
+	vm["ips"] = strings.Join(ips, ",")
+	vm["vm_tools_status"] = "RUNNING"
+}
+
+func migrateVm(s *Server, body any) (any, error) {
+	return s.operateVms(body, "migrateVm", func(vm object, data object) error {
+		if hostId := str(data["host_id"]); hostId != "" {
+			host := s.store.find("hosts", hostId)
+			if host == nil {
+				return fmt.Errorf("host %s not found", hostId)
+			}
+			if err := s.placementViolation(vm, host); err != nil {
+				return err
+			}
+			vm["host"] = ref(host)
+			return nil
+		}
+		// without a host the VM is scheduled to one satisfying its placement groups
+		host, err := s.scheduleHost(vm)
+		if err != nil {
+			return err
+		}
+		vm["host"] = ref(host)
+		return nil
+	})
+}
+
+// migrateVmAcrossCluster moves a VM, its volumes and nics to another cluster,
+// every nic has to be on a vlan of the target cluster once the vlan mappings are applied
+func migrateVmAcrossCluster(s *Server, body any) (any, error) {
+	return s.operateVms(body, "migrateVmAcrossCluster", func(vm object, data object) error {
+		cluster := s.store.find("clusters", data["elf_cluster_id"])
+		if cluster == nil {
+			return fmt.Errorf("cluster %v not found", data["elf_cluster_id"])
+		}
+		hosts := s.store.findBy("hosts", object{"cluster": object{"id": cluster["id"]}})
+		if hostId := str(data["host_id"]); hostId != "" {
+			hosts = s.store.findBy("hosts", object{"id": hostId, "cluster": object{"id": cluster["id"]}})
+		}
+		if len(hosts) == 0 {
+			return fmt.Errorf("no host of cluster %s can run the vm", cluster["name"])
+		}
+		mappings := make(map[string]string)
+		for _, item := range asList(data["vlan_mappings"]) {
+			mapping := asObject(item)
+			mappings[str(mapping["src_vlan_id"])] = str(mapping["dest_vlan_id"])
+		}
+		nics := s.store.findBy("vm-nics", object{"vm": object{"id": vm["id"]}})
+		vlans := make([]object, len(nics))
+		for i, nic := range nics {
+			vlanId := str(asObject(nic["vlan"])["id"])
+			if dest, ok := mappings[vlanId]; ok {
+				vlanId = dest
+			}
+			vlan := s.store.find("vlans", vlanId)
+			if vlan == nil || asObject(asObject(vlan["vds"])["cluster"])["id"] != cluster["id"] {
+				return fmt.Errorf("vlan %s is not in cluster %s", vlanId, cluster["name"])
+			}
+			vlans[i] = vlan
+		}
+		// the nics are recreated in the target cluster, they keep their mac address and order
+		sort.SliceStable(nics, func(i, j int) bool { return number(nics[i]["order"]) < number(nics[j]["order"]) })
+		for _, nic := range nics {
+			s.store.remove("vm-nics", nic["id"])
+		}
+		for i, nic := range nics {
+			s.insertNic(vm, vlans[i], nic)
+		}
+		// the disks are recreated along with their volumes, they keep their boot order and bus
+		for _, disk := range s.store.findBy("vm-disks", object{"vm": object{"id": vm["id"]}}) {
+			volume := s.store.find("vm-volumes", asObject(disk["vm_volume"])["id"])
+			if volume == nil {
+				continue
+			}
+			volume["cluster"] = ref(cluster)
+			if policy := data["elf_storage_policy"]; policy != nil {
+				setStoragePolicy(volume, policy)
+			}
+			s.store.remove("vm-disks", disk["id"])
+			s.insertDisk(vm, disk["boot"], disk["bus"], str(disk["type"]), volume, nil)
+		}
+		// folders belong to a cluster, the vm leaves its folder
+		vm["cluster"] = ref(cluster)
+		vm["host"] = ref(hosts[0])
+		vm["folder"] = nil
+		s.refresh()
+		return nil
+	})
+}
+
+func rollbackVm(s *Server, body any) (any, error) {
+	return s.operateVms(body, "rollbackVm", func(vm object, data object) error {
+		snapshot := s.store.find("vm-snapshots", data["snapshot_id"])
+		if snapshot == nil {
+			return fmt.Errorf("snapshot %v not found", data["snapshot_id"])
+		}
+		set(vm, snapshot, "vcpu", "memory", "cpu", "firmware")
+		return nil
+	})
+}
+
+func deleteVm(s *Server, body any) (any, error) {
+	return s.operateVms(body, "deleteVm", func(vm object, data object) error {
+		s.removeVm(vm["id"])
+		return nil
+	})
+}
+
+func number(v any) float64 {
+	f, _ := v.(float64)
+	return f
+}
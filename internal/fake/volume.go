@@ -0,0 +1,92 @@
+package fake
+
+import "fmt"
+
+func createVmVolume(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	for _, item := range asList(body) {
+		params := asObject(item)
+		cluster := s.store.find("clusters", params["cluster_id"])
+		if cluster == nil {
+			return nil, notFound("cluster", params["cluster_id"])
+		}
+		volume := s.insertVolume(cluster, params)
+		set(volume, params, "sharing")
+		taskId := s.newTask("createVmVolume", "VmVolume", volume["id"], nil)
+		result = append(result, withTask(taskId, volume))
+	}
+	return result, nil
+}
+
+func updateVmVolume(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	data := asObject(asObject(body)["data"])
+	for _, volume := range s.withWhere("vm-volumes", body) {
+		volume := volume
+		taskId := s.newTask("updateVmVolume", "VmVolume", volume["id"], func() error {
+			if number(data["size"]) != 0 && number(data["size"]) < number(volume["size"]) {
+				return fmt.Errorf("vm volume %s can not shrink", volume["name"])
+			}
+			set(volume, data, "name", "size")
+			if policy := data["elf_storage_policy"]; policy != nil {
+				setStoragePolicy(volume, policy)
+			}
+			return nil
+		})
+		result = append(result, withTask(taskId, volume))
+	}
+	return result, nil
+}
+
+// deleteVmVolume deletes volumes which are not mounted by any VM
+func deleteVmVolume(s *Server, body any) (any, error) {
+	result := make([]any, 0)
+	for _, volume := range s.withWhere("vm-volumes", body) {
+		volume := volume
+		if len(s.store.findBy("vm-disks", object{"vm_volume": object{"id": volume["id"]}})) > 0 {
+			return nil, badRequest("vm volume %s is mounted by a VM", volume["name"])
+		}
+		taskId := s.newTask("deleteVmVolume", "VmVolume", volume["id"], func() error {
+			s.store.remove("vm-volumes", volume["id"])
+			return nil
+		})
+		result = append(result, withTask(taskId, object{"id": volume["id"]}))
+	}
+	return result, nil
+}
+
+// addVmDisk mounts volumes to a VM, a volume mounted by another VM must be sharing
+func addVmDisk(s *Server, body any) (any, error) {
+	return s.operateVms(body, "addVmDisk", func(vm object, data object) error {
+		disks := asObject(data["vm_disks"])
+		for _, item := range asList(disks["mount_disks"]) {
+			volume := s.store.find("vm-volumes", asObject(item)["vm_volume_id"])
+			if volume == nil {
+				return fmt.Errorf("vm volume %v not found", asObject(item)["vm_volume_id"])
+			}
+			if volume["sharing"] != true && len(s.store.findBy("vm-disks", object{"vm_volume": object{"id": volume["id"]}})) > 0 {
+				return fmt.Errorf("vm volume %s is not sharing and is mounted by another VM", volume["name"])
+			}
+		}
+		if err := s.insertDisks(vm, disks); err != nil {
+			return err
+		}
+		s.refresh()
+		return nil
+	})
+}
+
+// removeVmDisk unmounts disks from a VM and keeps their volumes
+func removeVmDisk(s *Server, body any) (any, error) {
+	return s.operateVms(body, "removeVmDisk", func(vm object, data object) error {
+		for _, id := range asList(data["disk_ids"]) {
+			disk := s.store.find("vm-disks", id)
+			if disk == nil || asObject(disk["vm"])["id"] != vm["id"] {
+				return fmt.Errorf("vm disk %v not found in vm %s", id, vm["name"])
+			}
+			s.store.remove("vm-disks", id)
+		}
+		s.refresh()
+		return nil
+	})
+}
@@ -0,0 +1,193 @@
+package fake
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// object is a CloudTower entity as it is serialized by the REST API,
+// relations are embedded as nested objects or lists of objects
+type object = map[string]any
+
+// operators of the CloudTower where input, the longest suffixes come first
+// so that _not_in is not taken for _in
+var whereOperators = []string{
+	"_not_starts_with", "_not_ends_with", "_not_contains", "_not_in",
+	"_starts_with", "_ends_with", "_contains", "_in",
+	"_some", "_every", "_none",
+	"_gte", "_lte", "_gt", "_lt", "_not",
+}
+
+// match tells whether obj satisfies a CloudTower where input
+func match(obj object, where object) bool {
+	for key, cond := range where {
+		if cond == nil {
+			continue
+		}
+		if !matchField(obj, key, cond) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchField(obj object, key string, cond any) bool {
+	switch key {
+	case "AND":
+		for _, w := range asList(cond) {
+			if !match(obj, asObject(w)) {
+				return false
+			}
+		}
+		return true
+	case "OR":
+		list := asList(cond)
+		for _, w := range list {
+			if match(obj, asObject(w)) {
+				return true
+			}
+		}
+		return len(list) == 0
+	case "NOT":
+		for _, w := range asList(cond) {
+			if match(obj, asObject(w)) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if _, ok := obj[key]; !ok {
+		for _, op := range whereOperators {
+			if field, found := strings.CutSuffix(key, op); found {
+				return matchOperator(obj[field], op, cond)
+			}
+		}
+	}
+	value := obj[key]
+	if nested, ok := cond.(object); ok {
+		related, ok := value.(object)
+		return ok && match(related, nested)
+	}
+	return equal(value, cond)
+}
+
+func matchOperator(value any, op string, cond any) bool {
+	switch op {
+	case "_in", "_not_in":
+		in := false
+		for _, c := range asList(cond) {
+			if equal(value, c) {
+				in = true
+				break
+			}
+		}
+		return in == (op == "_in")
+	case "_contains", "_not_contains":
+		s, ok := value.(string)
+		return ok && strings.Contains(s, fmt.Sprint(cond)) == (op == "_contains")
+	case "_starts_with", "_not_starts_with":
+		s, ok := value.(string)
+		return ok && strings.HasPrefix(s, fmt.Sprint(cond)) == (op == "_starts_with")
+	case "_ends_with", "_not_ends_with":
+		s, ok := value.(string)
+		return ok && strings.HasSuffix(s, fmt.Sprint(cond)) == (op == "_ends_with")
+	case "_some", "_every", "_none":
+		matched := 0
+		list := asList(value)
+		for _, item := range list {
+			if match(asObject(item), asObject(cond)) {
+				matched++
+			}
+		}
+		switch op {
+		case "_some":
+			return matched > 0
+		case "_every":
+			return matched == len(list)
+		default:
+			return matched == 0
+		}
+	case "_not":
+		if nested, ok := cond.(object); ok {
+			related, ok := value.(object)
+			return !ok || !match(related, nested)
+		}
+		return !equal(value, cond)
+	default:
+		return compare(value, cond, op)
+	}
+}
+
+func compare(value any, cond any, op string) bool {
+	var c int
+	switch v := value.(type) {
+	case float64:
+		n, ok := cond.(float64)
+		if !ok {
+			return false
+		}
+		switch {
+		case v < n:
+			c = -1
+		case v > n:
+			c = 1
+		}
+	case string:
+		c = strings.Compare(v, fmt.Sprint(cond))
+	default:
+		return false
+	}
+	switch op {
+	case "_gt":
+		return c > 0
+	case "_gte":
+		return c >= 0
+	case "_lt":
+		return c < 0
+	default:
+		return c <= 0
+	}
+}
+
+func equal(a, b any) bool {
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+func asList(v any) []any {
+	list, _ := v.([]any)
+	return list
+}
+
+func asObject(v any) object {
+	o, _ := v.(object)
+	return o
+}
+
+// orderBy sorts objects by an order by input such as local_created_at_DESC
+func orderBy(objs []object, order string) {
+	field, desc := strings.CutSuffix(order, "_DESC")
+	if !desc {
+		field = strings.TrimSuffix(order, "_ASC")
+	}
+	sort.SliceStable(objs, func(i, j int) bool {
+		a, b := objs[i][field], objs[j][field]
+		var less bool
+		switch av := a.(type) {
+		case float64:
+			bv, _ := b.(float64)
+			less = av < bv
+			if desc {
+				less = av > bv
+			}
+		default:
+			as, bs := fmt.Sprint(a), fmt.Sprint(b)
+			less = as < bs
+			if desc {
+				less = as > bs
+			}
+		}
+		return less
+	})
+}
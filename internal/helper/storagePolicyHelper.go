@@ -22,14 +22,28 @@ func GetElfStoragePolicyByLocalId(client *apiclient.Cloudtower, localId string)
 	if len(res.Payload) == 0 {
 		return "", fmt.Errorf("no storage policy found for local id: %s", localId)
 	} else {
-		replicaNum := *res.Payload[0].ReplicaNum
-		var provision string
-		isThinProvision := *res.Payload[0].ThinProvision
-		if isThinProvision == true {
-			provision = "THIN"
-		} else {
-			provision = "THICK"
-		}
-		return fmt.Sprintf("REPLICA_%d_%s_PROVISION", replicaNum, provision), nil
+		return FormatElfStoragePolicy(*res.Payload[0].ReplicaNum, *res.Payload[0].ThinProvision), nil
 	}
 }
+
+// GetVmVolumeElfStoragePolicy returns the storage policy of a volume from its replica number
+// and provision, which follow a policy migration, and falls back to its elf_storage_policy
+func GetVmVolumeElfStoragePolicy(volume *models.VMVolume) string {
+	if volume.ElfStoragePolicyReplicaNum != nil && volume.ElfStoragePolicyThinProvision != nil {
+		return FormatElfStoragePolicy(*volume.ElfStoragePolicyReplicaNum, *volume.ElfStoragePolicyThinProvision)
+	}
+	if volume.ElfStoragePolicy == nil {
+		return ""
+	}
+	return string(*volume.ElfStoragePolicy)
+}
+
+func FormatElfStoragePolicy(replicaNum int32, isThinProvision bool) string {
+	var provision string
+	if isThinProvision == true {
+		provision = "THIN"
+	} else {
+		provision = "THICK"
+	}
+	return fmt.Sprintf("REPLICA_%d_%s_PROVISION", replicaNum, provision)
+}
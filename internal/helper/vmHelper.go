@@ -3,7 +3,7 @@ package helper
 import (
 	"context"
 	"fmt"
-	"time"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-provider-cloudtower/internal/cloudtower"
@@ -12,14 +12,43 @@ import (
 	"github.com/smartxworks/cloudtower-go-sdk/v2/models"
 )
 
+// pendingVMTools is the pending signal of a guest whose VMTools is not running
+const pendingVMTools = "VMTools running"
+
+// GuestReadiness are the signals reported by VMTools that a guest is ready,
+// VMTools running is always waited for
+type GuestReadiness struct {
+	// IPAddress waits until the guest reports an IP address
+	IPAddress bool
+	// Hostname waits until the guest reports this hostname, empty to not wait for it.
+	// It is matched regardless of case and of either side being fully qualified.
+	Hostname string
+}
+
+// GuestNotReadyError is returned when a guest is not ready before the deadline
+type GuestNotReadyError struct {
+	VmId string
+	// Pending are the signals the guest has not reported yet
+	Pending []string
+}
+
+func (e *GuestNotReadyError) Error() string {
+	return fmt.Sprintf("guest of vm %s is not ready before timeout, still waiting for %s", e.VmId, strings.Join(e.Pending, ", "))
+}
+
+// WaitVmToolsRunning polls the VM until its VMTools are running, until the deadline of ctx,
+// which is the timeout of the resource operation
 func WaitVmToolsRunning(ctx context.Context, ct *cloudtower.Client, vmId string) (*models.VM, error) {
+	return WaitVmGuestReady(ctx, ct, vmId, GuestReadiness{})
+}
+
+// WaitVmGuestReady polls the VM until its guest reports every signal of readiness,
+// until the deadline of ctx
+func WaitVmGuestReady(ctx context.Context, ct *cloudtower.Client, vmId string, readiness GuestReadiness) (*models.VM, error) {
 	if vmId == "" {
 		return nil, fmt.Errorf("vmId cannot be empty")
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
-	defer cancel()
-
 	params := vm.NewGetVmsParams()
 	params.RequestBody = &models.GetVmsRequestBody{
 		Where: &models.VMWhereInput{
@@ -28,35 +57,61 @@ func WaitVmToolsRunning(ctx context.Context, ct *cloudtower.Client, vmId string)
 		First: utils.Pointy[int32](1),
 	}
 
+	pending := []string{pendingVMTools}
 	for {
-		select {
-		case <-ctx.Done():
+		params.Context = ctx
+		res, err := utils.RetryWithExponentialBackoff(ctx, func() (*vm.GetVmsOK, error) {
+			return ct.Api.VM.GetVms(params)
+		}, ct.RetryOptions)
+		if err != nil {
 			if ctx.Err() == context.DeadlineExceeded {
-				return nil, fmt.Errorf("vm %s tools status is not running after 10 minutes", vmId)
+				return nil, &GuestNotReadyError{VmId: vmId, Pending: pending}
 			}
-			return nil, ctx.Err()
-		default:
-			var res *vm.GetVmsOK
-			var err error
+			return nil, fmt.Errorf("failed to get VM status: %w", err)
+		}
 
-			res, err = utils.RetryWithExponentialBackoff(ctx, func() (*vm.GetVmsOK, error) {
-				return ct.Api.VM.GetVms(params)
-			}, utils.RetryWithExponentialBackoffOptions{})
+		if res == nil || res.Payload == nil || len(res.Payload) == 0 {
+			return nil, fmt.Errorf("no VM found with id: %s", vmId)
+		}
 
-			if err != nil {
-				return nil, fmt.Errorf("failed to get VM status after %d retries: %v", 3, err)
-			}
+		pending = pendingGuestSignals(res.Payload[0], readiness)
+		if len(pending) == 0 {
+			return res.Payload[0], nil
+		}
 
-			if res == nil || res.Payload == nil || len(res.Payload) == 0 {
-				return nil, fmt.Errorf("no VM found with id: %s", vmId)
+		if err := utils.Sleep(ctx, ct.PollInterval()); err != nil {
+			if err == context.DeadlineExceeded {
+				return nil, &GuestNotReadyError{VmId: vmId, Pending: pending}
 			}
+			return nil, err
+		}
+	}
+}
 
-			if res.Payload[0].VMToolsStatus != nil && *res.Payload[0].VMToolsStatus == models.VMToolsStatusRUNNING {
-				return res.Payload[0], nil
-			}
+func pendingGuestSignals(v *models.VM, readiness GuestReadiness) []string {
+	if v.VMToolsStatus == nil || *v.VMToolsStatus != models.VMToolsStatusRUNNING {
+		// the other signals are reported by VMTools
+		return []string{pendingVMTools}
+	}
+	pending := make([]string, 0)
+	if readiness.IPAddress && (v.Ips == nil || *v.Ips == "") {
+		pending = append(pending, "an IP address")
+	}
+	if readiness.Hostname != "" && (v.Hostname == nil || !guestHostnameMatches(*v.Hostname, readiness.Hostname)) {
+		pending = append(pending, fmt.Sprintf("hostname %s", readiness.Hostname))
+	}
+	return pending
+}
 
-		}
+// guestHostnameMatches tells whether the hostname reported by a guest is the expected one,
+// a guest may report the short form of a fully qualified hostname or the other way around
+func guestHostnameMatches(reported string, expected string) bool {
+	if strings.EqualFold(reported, expected) {
+		return true
 	}
+	reportedShort, _, reportedQualified := strings.Cut(reported, ".")
+	expectedShort, _, expectedQualified := strings.Cut(expected, ".")
+	return reportedQualified != expectedQualified && strings.EqualFold(reportedShort, expectedShort)
 }
 
 func StartVmTemporary(ctx context.Context, ct *cloudtower.Client, vmId string) (func() error, error) {
@@ -71,9 +126,10 @@ func StartVmTemporary(ctx context.Context, ct *cloudtower.Client, vmId string) (
 	var res *vm.GetVmsOK
 	var err error
 
+	getParams.Context = ctx
 	res, err = utils.RetryWithExponentialBackoff(ctx, func() (*vm.GetVmsOK, error) {
 		return ct.Api.VM.GetVms(getParams)
-	}, utils.RetryWithExponentialBackoffOptions{})
+	}, ct.RetryOptions)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get VM: %v", err)
@@ -99,15 +155,16 @@ func StartVmTemporary(ctx context.Context, ct *cloudtower.Client, vmId string) (
 				HostID: res.Payload[0].Host.ID,
 			},
 		}
+		startParams.Context = ctx
 		res, err := utils.RetryWithExponentialBackoff(ctx, func() (*vm.StartVMOK, error) {
 			return ct.Api.VM.StartVM(startParams)
-		}, utils.RetryWithExponentialBackoffOptions{})
+		}, ct.RetryOptions)
 		if err != nil {
 			return nil, fmt.Errorf("failed to start VM: %v", err)
 		}
 		_, err = ct.WaitTasksFinish(ctx, []string{*res.Payload[0].TaskID})
 		if err != nil {
-			return nil, fmt.Errorf("failed to wait for VM to start: %v", err)
+			return nil, fmt.Errorf("failed to wait for VM to start: %w", err)
 		}
 		// vm has been started temporary, need to power off when done
 		return func() error {
@@ -118,9 +175,10 @@ func StartVmTemporary(ctx context.Context, ct *cloudtower.Client, vmId string) (
 					ID: &vmId,
 				},
 			}
+			shutdownParams.Context = ctx
 			shutDownResp, err := utils.RetryWithExponentialBackoff(ctx, func() (*vm.ShutDownVMOK, error) {
 				return ct.Api.VM.ShutDownVM(shutdownParams)
-			}, utils.RetryWithExponentialBackoffOptions{})
+			}, ct.RetryOptions)
 			if err == nil {
 				_, err = ct.WaitTasksFinish(ctx, []string{*shutDownResp.Payload[0].TaskID})
 				if err == nil {
@@ -135,16 +193,17 @@ func StartVmTemporary(ctx context.Context, ct *cloudtower.Client, vmId string) (
 					ID: &vmId,
 				},
 			}
+			powerOffParams.Context = ctx
 			resp, err := utils.RetryWithExponentialBackoff(ctx, func() (*vm.PoweroffVMOK, error) {
 				return ct.Api.VM.PoweroffVM(powerOffParams)
-			}, utils.RetryWithExponentialBackoffOptions{})
+			}, ct.RetryOptions)
 
 			if err != nil {
 				return fmt.Errorf("failed to power off VM: %v", err)
 			}
 			_, err = ct.WaitTasksFinish(ctx, []string{*resp.Payload[0].TaskID})
 			if err != nil {
-				return fmt.Errorf("failed to wait for VM to power off: %v", err)
+				return fmt.Errorf("failed to wait for VM to power off: %w", err)
 			}
 			return nil
 		}, nil
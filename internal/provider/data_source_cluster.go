@@ -37,6 +37,7 @@ func dataSourceCluster() *schema.Resource {
 				Optional:    true,
 				Description: "filter clusters by name contain a certain string",
 			},
+			"label": labelFilterSchema("clusters"),
 			"clusters": {
 				Type:        schema.TypeList,
 				Computed:    true,
@@ -83,6 +84,12 @@ func dataSourceClusterRead(ctx context.Context, d *schema.ResourceData, meta int
 		gp.RequestBody.Where.NameContains = &nameContains
 	}
 
+	for _, l := range expandLabelFilter(d) {
+		gp.RequestBody.Where.AND = append(gp.RequestBody.Where.AND, &models.ClusterWhereInput{
+			LabelsSome: l,
+		})
+	}
+
 	clusters, err := ct.Api.Cluster.GetClusters(gp)
 	if err != nil {
 		return diag.FromErr(err)
@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/fake"
+)
+
+func TestAccDataSourceCluster(t *testing.T) {
+	srv := fake.NewServer(t)
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: srv.ProviderConfig() + `
+data "cloudtower_cluster" "test" {
+  name_in = ["fake-cluster", "missing-cluster"]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.cloudtower_cluster.test", "clusters.#", "1"),
+					resource.TestCheckResourceAttr("data.cloudtower_cluster.test", "clusters.0.id", srv.Fixtures.ClusterId),
+					resource.TestCheckResourceAttr("data.cloudtower_cluster.test", "clusters.0.name", srv.Fixtures.ClusterName),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDataSourceCluster_label(t *testing.T) {
+	srv := fake.NewServer(t)
+	target := srv.AddCluster("tf-acc-labelled", "192.168.3.10")
+	labelId := srv.Insert("labels", map[string]any{"key": "zone", "value": "a"})
+	srv.Update("clusters", target.ClusterId, map[string]any{
+		"labels": []any{map[string]any{"id": labelId}},
+	})
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: srv.ProviderConfig() + `
+data "cloudtower_cluster" "test" {
+  label = { zone = "a" }
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.cloudtower_cluster.test", "clusters.#", "1"),
+					resource.TestCheckResourceAttr("data.cloudtower_cluster.test", "clusters.0.id", target.ClusterId),
+				),
+			},
+		},
+	})
+}
@@ -59,6 +59,7 @@ func dataSourceContentLibraryVmTemplate() *schema.Resource {
 				},
 				Description: "the cluster id which template has already distributed to.",
 			},
+			"label": labelFilterSchema("content library vm templates"),
 			"content_library_vm_templates": {
 				Type:        schema.TypeList,
 				Computed:    true,
@@ -254,6 +255,11 @@ func dataSourceContentLibraryVmTemplateRead(ctx context.Context, d *schema.Resou
 			},
 		}
 	}
+	for _, l := range expandLabelFilter(d) {
+		gp.RequestBody.Where.AND = append(gp.RequestBody.Where.AND, &models.ContentLibraryVMTemplateWhereInput{
+			LabelsSome: l,
+		})
+	}
 	vm_templates, err := ct.Api.ContentLibraryVMTemplate.GetContentLibraryVMTemplates(gp)
 	if err != nil {
 		return diag.FromErr(err)
@@ -0,0 +1,38 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/fake"
+)
+
+func TestAccDataSourceContentLibraryVmTemplate(t *testing.T) {
+	srv := fake.NewServer(t)
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: srv.ProviderConfig() + testAccVmConfig(srv, "tf-acc-vm") + fmt.Sprintf(`
+resource "cloudtower_content_library_vm_template" "test" {
+  name                 = "tf-acc-clt"
+  src_vm_id            = cloudtower_vm.tf-acc-vm.id
+  cluster_id           = [%q]
+  cloud_init_supported = false
+}
+
+data "cloudtower_content_library_vm_template" "test" {
+  name          = cloudtower_content_library_vm_template.test.name
+  cluster_id_in = [%[1]q]
+}
+`, srv.Fixtures.ClusterId),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.cloudtower_content_library_vm_template.test", "content_library_vm_templates.#", "1"),
+					resource.TestCheckResourceAttrPair("data.cloudtower_content_library_vm_template.test", "content_library_vm_templates.0.id", "cloudtower_content_library_vm_template.test", "id"),
+					resource.TestCheckResourceAttr("data.cloudtower_content_library_vm_template.test", "content_library_vm_templates.0.vm_templates.#", "1"),
+				),
+			},
+		},
+	})
+}
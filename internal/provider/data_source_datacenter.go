@@ -41,6 +41,7 @@ func dataSourceDatacenter() *schema.Resource {
 				Optional:    true,
 				Description: "filter datacenters by name contain a certain string",
 			},
+			"label": labelFilterSchema("datacenters"),
 			"datacenters": {
 				Type:        schema.TypeList,
 				Computed:    true,
@@ -87,6 +88,12 @@ func dataSourceDatacenterRead(ctx context.Context, d *schema.ResourceData, meta
 		gp.RequestBody.Where.NameContains = &nameContains
 	}
 
+	for _, l := range expandLabelFilter(d) {
+		gp.RequestBody.Where.AND = append(gp.RequestBody.Where.AND, &models.DatacenterWhereInput{
+			LabelsSome: l,
+		})
+	}
+
 	datacenters, err := ct.Api.Datacenter.GetDatacenters(gp)
 	if err != nil {
 		return diag.FromErr(err)
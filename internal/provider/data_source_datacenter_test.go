@@ -0,0 +1,28 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/fake"
+)
+
+func TestAccDataSourceDatacenter(t *testing.T) {
+	srv := fake.NewServer(t)
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: srv.ProviderConfig() + `
+data "cloudtower_datacenter" "test" {
+  name = "fake-datacenter"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.cloudtower_datacenter.test", "datacenters.#", "1"),
+					resource.TestCheckResourceAttr("data.cloudtower_datacenter.test", "datacenters.0.id", srv.Fixtures.DatacenterId),
+				),
+			},
+		},
+	})
+}
@@ -96,6 +96,7 @@ func dataSourceHost() *schema.Resource {
 				ConflictsWith: []string{"cluster_id"},
 				Description:   "filter datacenters by cluster id as an array",
 			},
+			"label": labelFilterSchema("hosts"),
 			"hosts": {
 				Type:        schema.TypeList,
 				Computed:    true,
@@ -122,6 +123,25 @@ func dataSourceHost() *schema.Resource {
 							Computed:    true,
 							Description: "host's data IP",
 						},
+						"nics": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "host's physical nics, a cloudtower_vds uses them as its uplinks",
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"id": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "nic's id",
+									},
+									"name": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "nic's name",
+									},
+								},
+							},
+						},
 					},
 				},
 			},
@@ -143,17 +163,30 @@ func dataSourceHostRead(ctx context.Context, d *schema.ResourceData, meta interf
 		return diag.FromErr(err)
 	}
 	gp.RequestBody.Where = where
+	for _, l := range expandLabelFilter(d) {
+		gp.RequestBody.Where.AND = append(gp.RequestBody.Where.AND, &models.HostWhereInput{
+			LabelsSome: l,
+		})
+	}
 	hosts, err := ct.Api.Host.GetHosts(gp)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 	output := make([]map[string]interface{}, 0)
 	for _, d := range hosts.Payload {
+		nics := make([]map[string]interface{}, 0, len(d.Nics))
+		for _, nic := range d.Nics {
+			nics = append(nics, map[string]interface{}{
+				"id":   nic.ID,
+				"name": nic.Name,
+			})
+		}
 		output = append(output, map[string]interface{}{
 			"id":            d.ID,
 			"name":          d.Name,
 			"management_ip": d.ManagementIP,
 			"data_ip":       d.DataIP,
+			"nics":          nics,
 		})
 	}
 	err = d.Set("hosts", output)
@@ -0,0 +1,32 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/fake"
+)
+
+func TestAccDataSourceHost(t *testing.T) {
+	srv := fake.NewServer(t)
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: srv.ProviderConfig() + fmt.Sprintf(`
+data "cloudtower_host" "test" {
+  cluster_id = %q
+}
+`, srv.Fixtures.ClusterId),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.cloudtower_host.test", "hosts.#", "1"),
+					resource.TestCheckResourceAttr("data.cloudtower_host.test", "hosts.0.id", srv.Fixtures.HostId),
+					resource.TestCheckResourceAttr("data.cloudtower_host.test", "hosts.0.management_ip", "192.168.1.10"),
+					resource.TestCheckResourceAttr("data.cloudtower_host.test", "hosts.0.nics.#", "2"),
+					resource.TestCheckResourceAttr("data.cloudtower_host.test", "hosts.0.nics.1.id", srv.Fixtures.NicId),
+				),
+			},
+		},
+	})
+}
@@ -56,6 +56,7 @@ func dataSourceIso() *schema.Resource {
 				ConflictsWith: []string{"cluster_id"},
 				Description:   "filter iso by cluster id as an array",
 			},
+			"label": labelFilterSchema("isos"),
 			"isos": {
 				Type:        schema.TypeList,
 				Computed:    true,
@@ -93,6 +94,11 @@ func dataSourceIsoRead(ctx context.Context, d *schema.ResourceData, meta interfa
 		return diag.FromErr(err)
 	}
 	gp.RequestBody.Where = where
+	for _, l := range expandLabelFilter(d) {
+		gp.RequestBody.Where.AND = append(gp.RequestBody.Where.AND, &models.ElfImageWhereInput{
+			LabelsSome: l,
+		})
+	}
 	isos, err := ct.Api.ElfImage.GetElfImages(gp)
 	if err != nil {
 		return diag.FromErr(err)
@@ -0,0 +1,30 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/fake"
+)
+
+func TestAccDataSourceIso(t *testing.T) {
+	srv := fake.NewServer(t)
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: srv.ProviderConfig() + fmt.Sprintf(`
+data "cloudtower_iso" "test" {
+  name_contains = "fake-image"
+  cluster_id    = %q
+}
+`, srv.Fixtures.ClusterId),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.cloudtower_iso.test", "isos.#", "1"),
+					resource.TestCheckResourceAttr("data.cloudtower_iso.test", "isos.0.id", srv.Fixtures.ElfImageId),
+				),
+			},
+		},
+	})
+}
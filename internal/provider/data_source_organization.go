@@ -0,0 +1,118 @@
+package provider
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/cloudtower"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/helper"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/client/organization"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/models"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceOrganization() *schema.Resource {
+	return &schema.Resource{
+		Description: "CloudTower organization data source.",
+
+		ReadContext: dataSourceOrganizationRead,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"name_in"},
+				Description:   "filter organizations by name",
+			},
+			"name_in": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				ConflictsWith: []string{"name"},
+				Description:   "filter organizations by name as an array",
+			},
+			"name_contains": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "filter organizations by name contain a certain string",
+			},
+			"current_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "id of the organization the provider is configured to use",
+			},
+			"organizations": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "list of organizations",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "organization's id",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "organization's name",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceOrganizationRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	ct := meta.(*cloudtower.Client)
+
+	gp := organization.NewGetOrganizationsParams()
+	gp.RequestBody = &models.GetOrganizationsRequestBody{
+		Where: &models.OrganizationWhereInput{},
+	}
+	if name := d.Get("name").(string); name != "" {
+		gp.RequestBody.Where.Name = &name
+	} else {
+		nameIn, err := helper.SliceInterfacesToTypeSlice[string](d.Get("name_in").([]interface{}))
+		if err != nil {
+			return diag.FromErr(err)
+		} else if len(nameIn) > 0 {
+			gp.RequestBody.Where.NameIn = nameIn
+		}
+	}
+	if nameContains := d.Get("name_contains").(string); nameContains != "" {
+		gp.RequestBody.Where.NameContains = &nameContains
+	}
+	gp.Context = ctx
+
+	organizations, err := ct.Api.Organization.GetOrganizations(gp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	output := make([]map[string]interface{}, 0)
+	for _, o := range organizations.Payload {
+		output = append(output, map[string]interface{}{
+			"id":   o.ID,
+			"name": o.Name,
+		})
+	}
+	err = d.Set("organizations", output)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("current_id", ct.OrgId); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(strconv.FormatInt(time.Now().Unix(), 10))
+
+	return diags
+}
@@ -0,0 +1,29 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/fake"
+)
+
+func TestAccDataSourceOrganization(t *testing.T) {
+	srv := fake.NewServer(t)
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: srv.ProviderConfig() + `
+data "cloudtower_organization" "test" {
+  name_contains = "fake"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.cloudtower_organization.test", "organizations.#", "1"),
+					resource.TestCheckResourceAttr("data.cloudtower_organization.test", "organizations.0.id", srv.Fixtures.OrganizationId),
+					resource.TestCheckResourceAttr("data.cloudtower_organization.test", "current_id", srv.Fixtures.OrganizationId),
+				),
+			},
+		},
+	})
+}
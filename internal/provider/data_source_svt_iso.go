@@ -70,6 +70,7 @@ func dataSourceSvtImage() *schema.Resource {
 				Optional:    true,
 				Description: "filter svt ISOs by version greater than or equal to",
 			},
+			"label": labelFilterSchema("svt ISOs"),
 			"isos": {
 				Type:        schema.TypeList,
 				Computed:    true,
@@ -112,6 +113,11 @@ func dataSourceSvtImageRead(ctx context.Context, d *schema.ResourceData, meta in
 		return diag.FromErr(err)
 	}
 	gp.RequestBody.Where = where
+	for _, l := range expandLabelFilter(d) {
+		gp.RequestBody.Where.AND = append(gp.RequestBody.Where.AND, &models.SvtImageWhereInput{
+			LabelsSome: l,
+		})
+	}
 	isos, err := ct.Api.SvtImage.GetSvtImages(gp)
 	if err != nil {
 		return diag.FromErr(err)
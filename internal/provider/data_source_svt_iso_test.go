@@ -0,0 +1,57 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/fake"
+)
+
+func TestAccDataSourceSvtIso(t *testing.T) {
+	srv := fake.NewServer(t)
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: srv.ProviderConfig() + `
+data "cloudtower_svt_iso" "test" {
+  version_gte = 300
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.cloudtower_svt_iso.test", "isos.#", "1"),
+					resource.TestCheckResourceAttr("data.cloudtower_svt_iso.test", "isos.0.id", srv.Fixtures.SvtImageId),
+					resource.TestCheckResourceAttr("data.cloudtower_svt_iso.test", "isos.0.version", "400"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDataSourceSvtIso_label(t *testing.T) {
+	srv := fake.NewServer(t)
+	labelId := srv.Insert("labels", map[string]any{"key": "zone", "value": "a"})
+	svtImageId := srv.Insert("svt-images", map[string]any{
+		"name":    "SMTX_VMTOOLS-labelled.iso",
+		"path":    "/isos/SMTX_VMTOOLS-labelled.iso",
+		"version": 400,
+		"cluster": map[string]any{"id": srv.Fixtures.ClusterId},
+		"labels":  []any{map[string]any{"id": labelId}},
+	})
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: srv.ProviderConfig() + `
+data "cloudtower_svt_iso" "test" {
+  label = { zone = "a" }
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.cloudtower_svt_iso.test", "isos.#", "1"),
+					resource.TestCheckResourceAttr("data.cloudtower_svt_iso.test", "isos.0.id", svtImageId),
+				),
+			},
+		},
+	})
+}
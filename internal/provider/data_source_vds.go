@@ -0,0 +1,153 @@
+package provider
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/cloudtower"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/helper"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/client/vds"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/models"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceVds() *schema.Resource {
+	return &schema.Resource{
+		Description: "CloudTower vds data source.",
+
+		ReadContext: dataSourceVdsRead,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"name_in"},
+				Description:   "filter vdses by name",
+			},
+			"name_in": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				Description:   "filter vdses by name as an array",
+				ConflictsWith: []string{"name"},
+				Elem:          &schema.Schema{Type: schema.TypeString},
+			},
+			"name_contains": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "filter vdses by name contain a certain string",
+			},
+			"cluster_id": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"cluster_id_in"},
+				Description:   "filter vdses by cluster id",
+			},
+			"cluster_id_in": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				Description:   "filter vdses by cluster id as array",
+				ConflictsWith: []string{"cluster_id"},
+				Elem:          &schema.Schema{Type: schema.TypeString},
+			},
+			"label": labelFilterSchema("vdses"),
+			"vdses": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "list of vdses",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "vds's id",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "vds's name",
+						},
+						"bond_mode": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "vds's bond mode",
+						},
+						"cluster_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "vds's cluster id",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceVdsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	ct := meta.(*cloudtower.Client)
+
+	gp := vds.NewGetVdsesParams()
+	gp.RequestBody = &models.GetVdsesRequestBody{
+		Where: &models.VdsWhereInput{},
+	}
+	if name := d.Get("name").(string); name != "" {
+		gp.RequestBody.Where.Name = &name
+	} else {
+		nameIn, err := helper.SliceInterfacesToTypeSlice[string](d.Get("name_in").([]interface{}))
+		if err != nil {
+			return diag.FromErr(err)
+		} else if len(nameIn) > 0 {
+			gp.RequestBody.Where.NameIn = nameIn
+		}
+	}
+	if nameContains := d.Get("name_contains").(string); nameContains != "" {
+		gp.RequestBody.Where.NameContains = &nameContains
+	}
+	if clusterId := d.Get("cluster_id").(string); clusterId != "" {
+		gp.RequestBody.Where.Cluster = &models.ClusterWhereInput{
+			ID: &clusterId,
+		}
+	} else {
+		clusterIdIn, err := helper.SliceInterfacesToTypeSlice[string](d.Get("cluster_id_in").([]interface{}))
+		if err != nil {
+			return diag.FromErr(err)
+		} else if len(clusterIdIn) > 0 {
+			gp.RequestBody.Where.Cluster = &models.ClusterWhereInput{
+				IDIn: clusterIdIn,
+			}
+		}
+	}
+
+	for _, l := range expandLabelFilter(d) {
+		gp.RequestBody.Where.AND = append(gp.RequestBody.Where.AND, &models.VdsWhereInput{
+			LabelsSome: l,
+		})
+	}
+
+	vdses, err := ct.Api.Vds.GetVdses(gp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	output := make([]map[string]interface{}, 0)
+	for _, v := range vdses.Payload {
+		output = append(output, map[string]interface{}{
+			"id":         v.ID,
+			"name":       v.Name,
+			"bond_mode":  v.BondMode,
+			"cluster_id": v.Cluster.ID,
+		})
+	}
+	err = d.Set("vdses", output)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(strconv.FormatInt(time.Now().Unix(), 10))
+
+	return diags
+}
@@ -0,0 +1,32 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/fake"
+)
+
+func TestAccDataSourceVds(t *testing.T) {
+	srv := fake.NewServer(t)
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: srv.ProviderConfig() + fmt.Sprintf(`
+data "cloudtower_vds" "test" {
+  name_contains = "vds"
+  cluster_id    = %q
+}
+`, srv.Fixtures.ClusterId),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.cloudtower_vds.test", "vdses.#", "1"),
+					resource.TestCheckResourceAttr("data.cloudtower_vds.test", "vdses.0.id", srv.Fixtures.VdsId),
+					resource.TestCheckResourceAttr("data.cloudtower_vds.test", "vdses.0.cluster_id", srv.Fixtures.ClusterId),
+					resource.TestCheckResourceAttr("data.cloudtower_vds.test", "vdses.0.bond_mode", "ACTIVE_BACKUP"),
+				),
+			},
+		},
+	})
+}
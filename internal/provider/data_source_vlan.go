@@ -69,6 +69,7 @@ func dataSourceVlan() *schema.Resource {
 				ConflictsWith: []string{"cluster_id"},
 				Elem:          &schema.Schema{Type: schema.TypeString},
 			},
+			"label": labelFilterSchema("vlans"),
 			"vlans": {
 				Type:        schema.TypeList,
 				Computed:    true,
@@ -116,6 +117,11 @@ func dataSourceVlanRead(ctx context.Context, d *schema.ResourceData, meta interf
 		return diag.FromErr(err)
 	}
 	gp.RequestBody.Where = where
+	for _, l := range expandLabelFilter(d) {
+		gp.RequestBody.Where.AND = append(gp.RequestBody.Where.AND, &models.VlanWhereInput{
+			LabelsSome: l,
+		})
+	}
 	vlans, err := ct.Api.Vlan.GetVlans(gp)
 
 	if err != nil {
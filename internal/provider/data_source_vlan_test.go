@@ -0,0 +1,32 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/fake"
+)
+
+func TestAccDataSourceVlan(t *testing.T) {
+	srv := fake.NewServer(t)
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: srv.ProviderConfig() + fmt.Sprintf(`
+data "cloudtower_vlan" "test" {
+  name       = %q
+  type       = "VM"
+  cluster_id = %q
+}
+`, srv.Fixtures.VlanName, srv.Fixtures.ClusterId),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.cloudtower_vlan.test", "vlans.#", "1"),
+					resource.TestCheckResourceAttr("data.cloudtower_vlan.test", "vlans.0.id", srv.Fixtures.VlanId),
+					resource.TestCheckResourceAttr("data.cloudtower_vlan.test", "vlans.0.cluster_id", srv.Fixtures.ClusterId),
+				),
+			},
+		},
+	})
+}
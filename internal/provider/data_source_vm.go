@@ -81,6 +81,7 @@ func dataSourceVm() *schema.Resource {
 				ConflictsWith: []string{"host_id"},
 				Description:   "filter VMs by host id in an array",
 			},
+			"label": labelFilterSchema("vms"),
 			"vms": {
 				Type:        schema.TypeList,
 				Computed:    true,
@@ -123,6 +124,11 @@ func dataSourceVmRead(ctx context.Context, d *schema.ResourceData, meta interfac
 		return diag.FromErr(err)
 	}
 	gp.RequestBody.Where = where
+	for _, l := range expandLabelFilter(d) {
+		gp.RequestBody.Where.AND = append(gp.RequestBody.Where.AND, &models.VMWhereInput{
+			LabelsSome: l,
+		})
+	}
 	vms, err := ct.Api.VM.GetVms(gp)
 	if err != nil {
 		return diag.FromErr(err)
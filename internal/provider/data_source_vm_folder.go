@@ -0,0 +1,146 @@
+package provider
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/cloudtower"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/helper"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/client/vm_folder"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/models"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceVmFolder() *schema.Resource {
+	return &schema.Resource{
+		Description: "CloudTower vm folder data source.",
+
+		ReadContext: dataSourceVmFolderRead,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"name_in"},
+				Description:   "filter vm folders by name",
+			},
+			"name_in": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				Description:   "filter vm folders by name as an array",
+				ConflictsWith: []string{"name"},
+				Elem:          &schema.Schema{Type: schema.TypeString},
+			},
+			"name_contains": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "filter vm folders by name contain a certain string",
+			},
+			"cluster_id": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"cluster_id_in"},
+				Description:   "filter vm folders by cluster id",
+			},
+			"cluster_id_in": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				Description:   "filter vm folders by cluster id as array",
+				ConflictsWith: []string{"cluster_id"},
+				Elem:          &schema.Schema{Type: schema.TypeString},
+			},
+			"vm_folders": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "list of vm folders",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "vm folder's id",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "vm folder's name",
+						},
+						"cluster_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "vm folder's cluster id",
+						},
+						"vm_num": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "number of VMs in the vm folder",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceVmFolderRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	ct := meta.(*cloudtower.Client)
+
+	gp := vm_folder.NewGetVMFoldersParams()
+	gp.RequestBody = &models.GetVMFoldersRequestBody{
+		Where: &models.VMFolderWhereInput{},
+	}
+	if name := d.Get("name").(string); name != "" {
+		gp.RequestBody.Where.Name = &name
+	} else {
+		nameIn, err := helper.SliceInterfacesToTypeSlice[string](d.Get("name_in").([]interface{}))
+		if err != nil {
+			return diag.FromErr(err)
+		} else if len(nameIn) > 0 {
+			gp.RequestBody.Where.NameIn = nameIn
+		}
+	}
+	if nameContains := d.Get("name_contains").(string); nameContains != "" {
+		gp.RequestBody.Where.NameContains = &nameContains
+	}
+	if clusterId := d.Get("cluster_id").(string); clusterId != "" {
+		gp.RequestBody.Where.Cluster = &models.ClusterWhereInput{
+			ID: &clusterId,
+		}
+	} else {
+		clusterIdIn, err := helper.SliceInterfacesToTypeSlice[string](d.Get("cluster_id_in").([]interface{}))
+		if err != nil {
+			return diag.FromErr(err)
+		} else if len(clusterIdIn) > 0 {
+			gp.RequestBody.Where.Cluster = &models.ClusterWhereInput{
+				IDIn: clusterIdIn,
+			}
+		}
+	}
+
+	folders, err := ct.Api.VMFolder.GetVMFolders(gp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	output := make([]map[string]interface{}, 0)
+	for _, f := range folders.Payload {
+		output = append(output, map[string]interface{}{
+			"id":         f.ID,
+			"name":       f.Name,
+			"cluster_id": f.Cluster.ID,
+			"vm_num":     f.VMNum,
+		})
+	}
+	err = d.Set("vm_folders", output)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(strconv.FormatInt(time.Now().Unix(), 10))
+
+	return diags
+}
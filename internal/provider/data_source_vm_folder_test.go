@@ -0,0 +1,36 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/fake"
+)
+
+func TestAccDataSourceVmFolder(t *testing.T) {
+	srv := fake.NewServer(t)
+	folderId := srv.Insert("vm-folders", map[string]any{
+		"name":    "tf-acc-folder",
+		"cluster": map[string]any{"id": srv.Fixtures.ClusterId},
+		"vm_num":  0,
+	})
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: srv.ProviderConfig() + fmt.Sprintf(`
+data "cloudtower_vm_folder" "test" {
+  name       = "tf-acc-folder"
+  cluster_id = %q
+}
+`, srv.Fixtures.ClusterId),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.cloudtower_vm_folder.test", "vm_folders.#", "1"),
+					resource.TestCheckResourceAttr("data.cloudtower_vm_folder.test", "vm_folders.0.id", folderId),
+					resource.TestCheckResourceAttr("data.cloudtower_vm_folder.test", "vm_folders.0.cluster_id", srv.Fixtures.ClusterId),
+				),
+			},
+		},
+	})
+}
@@ -56,6 +56,12 @@ func dataSourceVmSnapshot() *schema.Resource {
 				ConflictsWith: []string{"vm_id"},
 				Description:   "vm's id of the snapshot as an array",
 			},
+			"snapshot_plan_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "filter vm snapshots by the id of the cloudtower_snapshot_plan which took them",
+			},
+			"label": labelFilterSchema("vm snapshots"),
 			"vm_snapshots": {
 				Type:        schema.TypeList,
 				Computed:    true,
@@ -207,15 +213,31 @@ func dataSourceVmSnapshotRead(ctx context.Context, d *schema.ResourceData, meta
 		gp.RequestBody.Where.NameContains = &nameContains
 	}
 	if vmId := d.Get("vm_id").(string); vmId != "" {
-		gp.RequestBody.Where.VM.ID = &vmId
+		gp.RequestBody.Where.VM = &models.VMWhereInput{
+			ID: &vmId,
+		}
 	} else {
 		vmIdIn, err := helper.SliceInterfacesToTypeSlice[string](d.Get("vm_id_in").([]interface{}))
 		if err != nil {
 			return diag.FromErr(err)
-		} else {
-			gp.RequestBody.Where.VM.IDIn = vmIdIn
+		} else if len(vmIdIn) > 0 {
+			gp.RequestBody.Where.VM = &models.VMWhereInput{
+				IDIn: vmIdIn,
+			}
+		}
+	}
+	if planId := d.Get("snapshot_plan_id").(string); planId != "" {
+		gp.RequestBody.Where.SnapshotGroup = &models.SnapshotGroupWhereInput{
+			SnapshotPlan: &models.SnapshotPlanWhereInput{
+				ID: &planId,
+			},
 		}
 	}
+	for _, l := range expandLabelFilter(d) {
+		gp.RequestBody.Where.AND = append(gp.RequestBody.Where.AND, &models.VMSnapshotWhereInput{
+			LabelsSome: l,
+		})
+	}
 	snapshots, err := ct.Api.VMSnapshot.GetVMSnapshots(gp)
 	if err != nil {
 		return diag.FromErr(err)
@@ -285,13 +307,6 @@ func dataSourceVmSnapshotRead(ctx context.Context, d *schema.ResourceData, meta
 			"nics":        nics,
 		})
 	}
-	for _, d := range snapshots.Payload {
-		output = append(output, map[string]interface{}{
-			"id":          d.ID,
-			"name":        d.Name,
-			"create_time": d.LocalCreatedAt,
-		})
-	}
 	err = d.Set("vm_snapshots", output)
 	if err != nil {
 		return diag.FromErr(err)
@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/fake"
+)
+
+func TestAccDataSourceVmSnapshot(t *testing.T) {
+	srv := fake.NewServer(t)
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: srv.ProviderConfig() + testAccVmConfig(srv, "tf-acc-vm") + `
+resource "cloudtower_vm_snapshot" "test" {
+  name  = "tf-acc-snapshot"
+  vm_id = cloudtower_vm.tf-acc-vm.id
+}
+
+data "cloudtower_vm_snapshot" "test" {
+  vm_id = cloudtower_vm_snapshot.test.vm_id
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.cloudtower_vm_snapshot.test", "vm_snapshots.#", "1"),
+					resource.TestCheckResourceAttrPair("data.cloudtower_vm_snapshot.test", "vm_snapshots.0.id", "cloudtower_vm_snapshot.test", "id"),
+					resource.TestCheckResourceAttr("data.cloudtower_vm_snapshot.test", "vm_snapshots.0.disks.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccDataSourceVmSnapshot_snapshotPlan(t *testing.T) {
+	srv := fake.NewServer(t)
+	config := testAccSnapshotPlanConfig(srv, `    frequency = "DAILY"`, "")
+	var planId string
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check:  resource.TestCheckResourceAttrWith("cloudtower_snapshot_plan.test", "id", func(v string) error { planId = v; return nil }),
+			},
+			{
+				// the plan keeps its 2 latest snapshots
+				PreConfig: func() {
+					for i := 0; i < 3; i++ {
+						srv.RunSnapshotPlan(planId)
+					}
+				},
+				Config: config + `
+resource "cloudtower_vm_snapshot" "manual" {
+  name  = "tf-acc-manual-snapshot"
+  vm_id = cloudtower_vm.tf-acc-vm.id
+}
+
+data "cloudtower_vm_snapshot" "test" {
+  snapshot_plan_id = cloudtower_snapshot_plan.test.id
+  depends_on       = [cloudtower_vm_snapshot.manual]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.cloudtower_vm_snapshot.test", "vm_snapshots.#", "2"),
+					resource.TestCheckResourceAttr("data.cloudtower_vm_snapshot.test", "vm_snapshots.1.name", "tf-acc-snapshot-plan-3-tf-acc-vm"),
+				),
+			},
+		},
+	})
+}
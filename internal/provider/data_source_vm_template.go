@@ -52,6 +52,7 @@ func dataSourceVmTemplate() *schema.Resource {
 				ConflictsWith: []string{"cluster_id"},
 				Description:   "filter vm template by cluster's id of the template as an array",
 			},
+			"label": labelFilterSchema("vm templates"),
 			"vm_templates": {
 				Type:        schema.TypeList,
 				Computed:    true,
@@ -246,6 +247,11 @@ func dataSourceVmTemplateRead(ctx context.Context, d *schema.ResourceData, meta
 			}
 		}
 	}
+	for _, l := range expandLabelFilter(d) {
+		gp.RequestBody.Where.AND = append(gp.RequestBody.Where.AND, &models.VMTemplateWhereInput{
+			LabelsSome: l,
+		})
+	}
 	vm_templates, err := ct.Api.VMTemplate.GetVMTemplates(gp)
 	if err != nil {
 		return diag.FromErr(err)
@@ -0,0 +1,35 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/fake"
+)
+
+func TestAccDataSourceVmTemplate(t *testing.T) {
+	srv := fake.NewServer(t)
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: srv.ProviderConfig() + testAccVmConfig(srv, "tf-acc-vm") + `
+resource "cloudtower_vm_template" "test" {
+  name                 = "tf-acc-template"
+  src_vm_id            = cloudtower_vm.tf-acc-vm.id
+  cloud_init_supported = false
+}
+
+data "cloudtower_vm_template" "test" {
+  name = cloudtower_vm_template.test.name
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.cloudtower_vm_template.test", "vm_templates.#", "1"),
+					resource.TestCheckResourceAttrPair("data.cloudtower_vm_template.test", "vm_templates.0.id", "cloudtower_vm_template.test", "id"),
+					resource.TestCheckResourceAttr("data.cloudtower_vm_template.test", "vm_templates.0.nics.#", "1"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,29 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/fake"
+)
+
+func TestAccDataSourceVm(t *testing.T) {
+	srv := fake.NewServer(t)
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: srv.ProviderConfig() + testAccVmConfig(srv, "tf-acc-vm") + `
+data "cloudtower_vm" "test" {
+  name   = cloudtower_vm.tf-acc-vm.name
+  status = "STOPPED"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.cloudtower_vm.test", "vms.#", "1"),
+					resource.TestCheckResourceAttrPair("data.cloudtower_vm.test", "vms.0.id", "cloudtower_vm.tf-acc-vm", "id"),
+				),
+			},
+		},
+	})
+}
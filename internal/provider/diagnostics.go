@@ -0,0 +1,78 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/cloudtower"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/helper"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/models"
+)
+
+// diagFromTaskErr reports every failed task of a cloudtower.TaskError as its own
+// diagnostic, with enough detail to find the task in CloudTower, and explains
+// what a guest that is not ready is missing, other errors are reported as
+// diag.FromErr does
+func diagFromTaskErr(err error) diag.Diagnostics {
+	var guestErr *helper.GuestNotReadyError
+	if errors.As(err, &guestErr) {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Guest of VM %s is not ready", guestErr.VmId),
+			Detail: fmt.Sprintf("The guest did not report %s before the timeout. "+
+				"Check that VMTools is installed in the guest, or raise wait_for_guest.timeout.",
+				strings.Join(guestErr.Pending, ", ")),
+		}}
+	}
+	var taskErr *cloudtower.TaskError
+	if !errors.As(err, &taskErr) {
+		return diag.FromErr(err)
+	}
+	var diags diag.Diagnostics
+	for _, t := range taskErr.Tasks {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("CloudTower task %s failed: %s", cloudtower.StrValue(t.ID), cloudtower.StrValue(t.ErrorMessage)),
+			Detail:   taskDetail(t),
+		})
+	}
+	return diags
+}
+
+func taskDetail(t *models.Task) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Task ID: %s\n", cloudtower.StrValue(t.ID))
+	fmt.Fprintf(&b, "Description: %s\n", cloudtower.StrValue(t.Description))
+	resourceType := ""
+	if t.ResourceType != nil {
+		resourceType = *t.ResourceType
+	}
+	fmt.Fprintf(&b, "Resource: %s %s\n", resourceType, cloudtower.StrValue(t.ResourceID))
+	if t.ResourceMutation != nil {
+		fmt.Fprintf(&b, "Mutation: %s\n", *t.ResourceMutation)
+	}
+	fmt.Fprintf(&b, "Error code: %s\n", cloudtower.StrValue(t.ErrorCode))
+	if len(t.Steps) > 0 {
+		b.WriteString("Steps:\n")
+		for _, step := range t.Steps {
+			if step == nil {
+				continue
+			}
+			status := "pending"
+			if step.Finished != nil && *step.Finished {
+				status = "finished"
+			}
+			progress := ""
+			if step.Current != nil && step.Total != nil {
+				progress = fmt.Sprintf(" %v/%v", *step.Current, *step.Total)
+				if step.Unit != nil {
+					progress += " " + string(*step.Unit)
+				}
+			}
+			fmt.Fprintf(&b, "  - %s%s (%s)\n", cloudtower.StrValue(step.Key), progress, status)
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
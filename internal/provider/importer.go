@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// importLookup resolves the id given to terraform import, which is either the
+// id of the resource or the path of names leading to it, e.g. cluster_name/vm_name
+type importLookup struct {
+	// kind names the resource in errors, e.g. VM
+	kind string
+	// format is the name path accepted in place of an id, leading names are
+	// optional when the last ones are unique, e.g. [cluster_name/]vm_name
+	format string
+	// byId returns the ids of the resources with the id, at most one
+	byId func(id string) ([]string, error)
+	// byName returns the ids of the resources at the name path, names has at
+	// least one element and ends with the name of the resource itself
+	byName func(names []string) ([]string, error)
+}
+
+func (l *importLookup) resolve(importId string) (string, error) {
+	ids, err := l.byId(importId)
+	if err != nil {
+		return "", err
+	}
+	if len(ids) == 1 {
+		return ids[0], nil
+	}
+	names := strings.Split(importId, "/")
+	if len(names) > strings.Count(l.format, "/")+1 {
+		return "", fmt.Errorf("invalid import id %q, expected an id or %s", importId, l.format)
+	}
+	for _, name := range names {
+		if name == "" {
+			return "", fmt.Errorf("invalid import id %q, expected an id or %s", importId, l.format)
+		}
+	}
+	ids, err = l.byName(names)
+	if err != nil {
+		return "", err
+	}
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("no %s found with id or name %q", l.kind, importId)
+	case 1:
+		return ids[0], nil
+	default:
+		return "", fmt.Errorf("%d %ss match %q, import by id or by the full %s instead", len(ids), l.kind, importId, l.format)
+	}
+}
+
+// importState is the StateContext of a resource importer, it replaces the
+// import id with the id resolved by lookup and leaves the rest to read
+func importState(lookup func(ctx context.Context, meta interface{}) *importLookup) schema.StateContextFunc {
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+		id, err := lookup(ctx, meta).resolve(d.Id())
+		if err != nil {
+			return nil, err
+		}
+		d.SetId(id)
+		return []*schema.ResourceData{d}, nil
+	}
+}
+
+// namesAt returns the name at index i counted from the end of names, nil if names is shorter
+func namesAt(names []string, i int) *string {
+	if i >= len(names) {
+		return nil
+	}
+	return &names[len(names)-1-i]
+}
+
+// suppressAfterCreate ignores changes to arguments only used when the resource is created
+func suppressAfterCreate(k, old, new string, d *schema.ResourceData) bool {
+	return d.Id() != ""
+}
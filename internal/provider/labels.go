@@ -0,0 +1,270 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/cloudtower"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/client/label"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/models"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// labelsSchema is the labels argument of a taggable resource, the
+// default_labels of the provider are added to them
+func labelsSchema(object string) *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeMap,
+		Optional:    true,
+		Elem:        &schema.Schema{Type: schema.TypeString},
+		Description: fmt.Sprintf("%s's labels, a map of label keys to values, a value here overrides the one of the same key in default_labels of the provider", object),
+	}
+}
+
+// labelsAllSchema is the labels of a taggable resource including the default_labels of the provider
+func labelsAllSchema(object string) *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeMap,
+		Computed:    true,
+		Elem:        &schema.Schema{Type: schema.TypeString},
+		Description: fmt.Sprintf("all of %s's labels, including the ones from default_labels of the provider", object),
+	}
+}
+
+// labelFilterSchema filters the objects of a data source by their labels
+func labelFilterSchema(objects string) *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeMap,
+		Optional:    true,
+		Elem:        &schema.Schema{Type: schema.TypeString},
+		Description: fmt.Sprintf("filter %s by labels, only %s having all the label keys and values are returned", objects, objects),
+	}
+}
+
+// expandLabelFilter returns a where input for every label of the label filter,
+// they are meant to be put in the AND of the where input of the data source
+func expandLabelFilter(d *schema.ResourceData) []*models.LabelWhereInput {
+	filter := d.Get("label").(map[string]interface{})
+	keys := make([]string, 0, len(filter))
+	for k := range filter {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	wheres := make([]*models.LabelWhereInput, 0, len(keys))
+	for _, k := range keys {
+		k, v := k, filter[k].(string)
+		wheres = append(wheres, &models.LabelWhereInput{
+			Key:   &k,
+			Value: &v,
+		})
+	}
+	return wheres
+}
+
+// labelsCustomizeDiff plans labels_all as the default_labels of the provider merged with labels
+func labelsCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("labels") {
+		return d.SetNewComputed("labels_all")
+	}
+	ct := meta.(*cloudtower.Client)
+	all := make(map[string]interface{})
+	for k, v := range ct.DefaultLabels {
+		all[k] = v
+	}
+	for k, v := range d.Get("labels").(map[string]interface{}) {
+		all[k] = v
+	}
+	if reflect.DeepEqual(all, d.Get("labels_all")) {
+		return nil
+	}
+	return d.SetNew("labels_all", all)
+}
+
+// setLabels sets labels_all to the labels of a resource, and labels to the ones
+// not coming from default_labels of the provider, unless they are in labels already
+func setLabels(ctx context.Context, d *schema.ResourceData, ct *cloudtower.Client, nested []*models.NestedLabel) error {
+	ids := make([]string, 0, len(nested))
+	for _, l := range nested {
+		ids = append(ids, *l.ID)
+	}
+	all := make(map[string]interface{})
+	if len(ids) > 0 {
+		glp := label.NewGetLabelsParams()
+		glp.RequestBody = &models.GetLabelsRequestBody{
+			Where: &models.LabelWhereInput{
+				IDIn: ids,
+			},
+		}
+		glp.Context = ctx
+		labels, err := ct.Api.Label.GetLabels(glp)
+		if err != nil {
+			return err
+		}
+		for _, l := range labels.Payload {
+			value := ""
+			if l.Value != nil {
+				value = *l.Value
+			}
+			all[*l.Key] = value
+		}
+	}
+	prior := d.Get("labels").(map[string]interface{})
+	configured := make(map[string]interface{})
+	for k, v := range all {
+		_, inPrior := prior[k]
+		defaultValue, isDefault := ct.DefaultLabels[k]
+		if inPrior || !isDefault || defaultValue != v {
+			configured[k] = v
+		}
+	}
+	if err := d.Set("labels", configured); err != nil {
+		return err
+	}
+	return d.Set("labels_all", all)
+}
+
+// labelTarget is the resource labels are added to or removed from, only one field is set
+type labelTarget struct {
+	vm                       *models.VMWhereInput
+	vmTemplate               *models.VMTemplateWhereInput
+	contentLibraryVmTemplate *models.ContentLibraryVMTemplateWhereInput
+	cluster                  *models.ClusterWhereInput
+	vmVolume                 *models.VMVolumeWhereInput
+}
+
+// updateLabels adds and removes the labels of target to match the planned labels_all,
+// labels which do not exist yet are created, labels left unused are kept
+func updateLabels(ctx context.Context, d *schema.ResourceData, ct *cloudtower.Client, target labelTarget) error {
+	o, n := d.GetChange("labels_all")
+	oldLabels, newLabels := o.(map[string]interface{}), n.(map[string]interface{})
+	toAdd := make(map[string]string)
+	for k, v := range newLabels {
+		if ov, ok := oldLabels[k]; !ok || ov != v {
+			toAdd[k] = v.(string)
+		}
+	}
+	toRemove := make(map[string]string)
+	for k, v := range oldLabels {
+		if nv, ok := newLabels[k]; !ok || nv != v {
+			toRemove[k] = v.(string)
+		}
+	}
+	taskIds := make([]string, 0)
+	if len(toRemove) > 0 {
+		rlp := label.NewRemoveLabelsFromResourcesParams()
+		rlp.RequestBody = &models.RemoveLabelsFromResourcesParams{
+			Where: labelsWhereInput(toRemove),
+			Data: &models.RemoveLabelsFromResourcesParamsData{
+				Vms:                       target.vm,
+				VMTemplates:               target.vmTemplate,
+				ContentLibraryVMTemplates: target.contentLibraryVmTemplate,
+				Clusters:                  target.cluster,
+				VMVolumes:                 target.vmVolume,
+			},
+		}
+		rlp.Context = ctx
+		labels, err := ct.Api.Label.RemoveLabelsFromResources(rlp)
+		if err != nil {
+			return err
+		}
+		for _, l := range labels.Payload {
+			if l.TaskID != nil {
+				taskIds = append(taskIds, *l.TaskID)
+			}
+		}
+	}
+	if len(toAdd) > 0 {
+		if err := ensureLabels(ctx, ct, toAdd); err != nil {
+			return err
+		}
+		alp := label.NewAddLabelsToResourcesParams()
+		alp.RequestBody = &models.AddLabelsToResourcesParams{
+			Where: labelsWhereInput(toAdd),
+			Data: &models.AddLabelsToResourcesParamsData{
+				Vms:                       target.vm,
+				VMTemplates:               target.vmTemplate,
+				ContentLibraryVMTemplates: target.contentLibraryVmTemplate,
+				Clusters:                  target.cluster,
+				VMVolumes:                 target.vmVolume,
+			},
+		}
+		alp.Context = ctx
+		labels, err := ct.Api.Label.AddLabelsToResources(alp)
+		if err != nil {
+			return err
+		}
+		for _, l := range labels.Payload {
+			if l.TaskID != nil {
+				taskIds = append(taskIds, *l.TaskID)
+			}
+		}
+	}
+	_, err := ct.WaitTasksFinish(ctx, taskIds)
+	return err
+}
+
+// ensureLabels creates the labels with the keys and values which do not exist yet
+func ensureLabels(ctx context.Context, ct *cloudtower.Client, labels map[string]string) error {
+	glp := label.NewGetLabelsParams()
+	glp.RequestBody = &models.GetLabelsRequestBody{
+		Where: labelsWhereInput(labels),
+	}
+	glp.Context = ctx
+	existing, err := ct.Api.Label.GetLabels(glp)
+	if err != nil {
+		return err
+	}
+	missing := make(map[string]string)
+	for k, v := range labels {
+		missing[k] = v
+	}
+	for _, l := range existing.Payload {
+		if l.Value != nil && missing[*l.Key] == *l.Value {
+			delete(missing, *l.Key)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	params := make([]*models.LabelCreationParams, 0, len(missing))
+	for k, v := range missing {
+		k, v := k, v
+		params = append(params, &models.LabelCreationParams{
+			Key:   &k,
+			Value: &v,
+		})
+	}
+	clp := label.NewCreateLabelParams()
+	clp.RequestBody = params
+	clp.Context = ctx
+	created, err := ct.Api.Label.CreateLabel(clp)
+	if err != nil {
+		return err
+	}
+	taskIds := make([]string, 0)
+	for _, l := range created.Payload {
+		if l.TaskID != nil {
+			taskIds = append(taskIds, *l.TaskID)
+		}
+	}
+	_, err = ct.WaitTasksFinish(ctx, taskIds)
+	return err
+}
+
+// labelsWhereInput matches the labels with any of the keys and values
+func labelsWhereInput(labels map[string]string) *models.LabelWhereInput {
+	or := make([]*models.LabelWhereInput, 0, len(labels))
+	for k, v := range labels {
+		k, v := k, v
+		or = append(or, &models.LabelWhereInput{
+			Key:   &k,
+			Value: &v,
+		})
+	}
+	return &models.LabelWhereInput{
+		OR: or,
+	}
+}
@@ -2,12 +2,15 @@ package provider
 
 import (
 	"context"
+	"time"
 
 	"github.com/hashicorp/terraform-provider-cloudtower/internal/cloudtower"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/utils"
 	"github.com/smartxworks/cloudtower-go-sdk/v2/models"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func init() {
@@ -31,22 +34,34 @@ func New(version string) func() *schema.Provider {
 		p := &schema.Provider{
 			Schema: map[string]*schema.Schema{
 				"username": {
-					Type:        schema.TypeString,
-					Required:    true,
-					DefaultFunc: schema.EnvDefaultFunc("CLOUDTOWER_USER", nil),
-					Description: "The username for CloudTower API operations.",
+					Type:          schema.TypeString,
+					Optional:      true,
+					DefaultFunc:   schema.EnvDefaultFunc("CLOUDTOWER_USER", nil),
+					ConflictsWith: []string{"api_token"},
+					Description:   "The username for CloudTower API operations, required unless `api_token` is set.",
 				},
 				"password": {
-					Type:        schema.TypeString,
-					Required:    true,
-					DefaultFunc: schema.EnvDefaultFunc("CLOUDTOWER_PASSWORD", nil),
-					Description: "The user password for CloudTower API operations.",
+					Type:          schema.TypeString,
+					Optional:      true,
+					Sensitive:     true,
+					DefaultFunc:   schema.EnvDefaultFunc("CLOUDTOWER_PASSWORD", nil),
+					ConflictsWith: []string{"api_token"},
+					Description:   "The user password for CloudTower API operations, required unless `api_token` is set.",
 				},
 				"user_source": {
-					Type:        schema.TypeString,
-					Required:    true,
-					DefaultFunc: schema.EnvDefaultFunc("CLOUDTOWER_USER_SOURCE", nil),
-					Description: "The source type of user",
+					Type:          schema.TypeString,
+					Optional:      true,
+					DefaultFunc:   schema.EnvDefaultFunc("CLOUDTOWER_USER_SOURCE", nil),
+					ConflictsWith: []string{"api_token"},
+					Description:   "The source type of user",
+				},
+				"api_token": {
+					Type:          schema.TypeString,
+					Optional:      true,
+					Sensitive:     true,
+					DefaultFunc:   schema.EnvDefaultFunc("CLOUDTOWER_TOKEN", nil),
+					ConflictsWith: []string{"username", "password", "user_source"},
+					Description:   "A pre-issued CloudTower API token, used instead of `username` and `password`.",
 				},
 				"cloudtower_server": {
 					Type:        schema.TypeString,
@@ -54,11 +69,120 @@ func New(version string) func() *schema.Provider {
 					DefaultFunc: schema.EnvDefaultFunc("CLOUDTOWER_SERVER", nil),
 					Description: "The CloudTower Server name.",
 				},
+				"organization": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					DefaultFunc: schema.EnvDefaultFunc("CLOUDTOWER_ORGANIZATION", nil),
+					Description: "The name or id of the organization to manage resources in, required when there are multiple organizations.",
+				},
+				"scheme": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("CLOUDTOWER_SCHEME", "http"),
+					ValidateFunc: validation.StringInSlice([]string{"http", "https"}, false),
+					Description:  "The protocol used to connect to CloudTower, must be one of 'http', 'https'. Defaults to `http`.",
+				},
+				"ca_cert_file": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					DefaultFunc: schema.EnvDefaultFunc("CLOUDTOWER_CA_CERT_FILE", nil),
+					Description: "Path to a PEM-encoded CA bundle used to verify the CloudTower server certificate.",
+				},
+				"ca_cert": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					DefaultFunc: schema.EnvDefaultFunc("CLOUDTOWER_CA_CERT", nil),
+					Description: "PEM-encoded CA bundle used to verify the CloudTower server certificate.",
+				},
+				"client_cert_file": {
+					Type:          schema.TypeString,
+					Optional:      true,
+					DefaultFunc:   schema.EnvDefaultFunc("CLOUDTOWER_CLIENT_CERT_FILE", nil),
+					ConflictsWith: []string{"client_cert"},
+					Description:   "Path to a PEM-encoded client certificate for mutual TLS.",
+				},
+				"client_key_file": {
+					Type:          schema.TypeString,
+					Optional:      true,
+					DefaultFunc:   schema.EnvDefaultFunc("CLOUDTOWER_CLIENT_KEY_FILE", nil),
+					ConflictsWith: []string{"client_key"},
+					Description:   "Path to the PEM-encoded private key of `client_cert_file`.",
+				},
+				"client_cert": {
+					Type:          schema.TypeString,
+					Optional:      true,
+					ConflictsWith: []string{"client_cert_file"},
+					Description:   "PEM-encoded client certificate for mutual TLS.",
+				},
+				"client_key": {
+					Type:          schema.TypeString,
+					Optional:      true,
+					Sensitive:     true,
+					ConflictsWith: []string{"client_key_file"},
+					Description:   "PEM-encoded private key of `client_cert`.",
+				},
+				"task_poll_interval": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("CLOUDTOWER_TASK_POLL_INTERVAL", 5),
+					ValidateFunc: validation.IntAtLeast(1),
+					Description:  "Interval in seconds to poll the status of CloudTower tasks. Defaults to `5`.",
+				},
+				"max_retries": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("CLOUDTOWER_MAX_RETRIES", 3),
+					ValidateFunc: validation.IntAtLeast(1),
+					Description:  "Maximum attempts of a request failed with a transient error, such as a network failure, throttling or a server error. Defaults to `3`.",
+				},
+				"retry_max_delay": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("CLOUDTOWER_RETRY_MAX_DELAY", 10),
+					ValidateFunc: validation.IntAtLeast(1),
+					Description:  "Maximum delay in seconds between two attempts of a request, a longer `Retry-After` asked by CloudTower is cut to it. Defaults to `10`.",
+				},
+				"max_requests_per_second": {
+					Type:         schema.TypeFloat,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("CLOUDTOWER_MAX_REQUESTS_PER_SECOND", 0.0),
+					ValidateFunc: validation.FloatAtLeast(0),
+					Description:  "Maximum requests sent to CloudTower per second, shared by all resources. Defaults to `0`, which means unlimited.",
+				},
+				"max_concurrent_mutations": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("CLOUDTOWER_MAX_CONCURRENT_MUTATIONS", 0),
+					ValidateFunc: validation.IntAtLeast(0),
+					Description:  "Maximum in-flight requests which create, update or delete resources. Defaults to `0`, which means unlimited.",
+				},
+				"max_concurrent_vm_creations_per_cluster": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("CLOUDTOWER_MAX_CONCURRENT_VM_CREATIONS_PER_CLUSTER", 0),
+					ValidateFunc: validation.IntAtLeast(0),
+					Description:  "Maximum VMs being created or cloned at the same time on one cluster, applied to VMs with `cluster_id` set. Defaults to `0`, which means unlimited.",
+				},
+				"insecure_skip_verify": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					DefaultFunc: schema.EnvDefaultFunc("CLOUDTOWER_INSECURE_SKIP_VERIFY", false),
+					Description: "Skip verification of the CloudTower server certificate, only use it for testing.",
+				},
+				"default_labels": {
+					Type:        schema.TypeMap,
+					Optional:    true,
+					Elem:        &schema.Schema{Type: schema.TypeString},
+					Description: "Labels added to every resource supporting `labels`, a label of the same key in `labels` of the resource overrides the default one.",
+				},
 			},
 			DataSourcesMap: map[string]*schema.Resource{
 				"cloudtower_datacenter":                  dataSourceDatacenter(),
+				"cloudtower_organization":                dataSourceOrganization(),
 				"cloudtower_cluster":                     dataSourceCluster(),
 				"cloudtower_vlan":                        dataSourceVlan(),
+				"cloudtower_vds":                         dataSourceVds(),
+				"cloudtower_vm_folder":                   dataSourceVmFolder(),
 				"cloudtower_iso":                         dataSourceIso(),
 				"cloudtower_svt_iso":                     dataSourceSvtImage(),
 				"cloudtower_host":                        dataSourceHost(),
@@ -74,6 +198,17 @@ func New(version string) func() *schema.Provider {
 				"cloudtower_vm_snapshot":                 resourceVmSnapshot(),
 				"cloudtower_vm_template":                 resourceVmTemplate(),
 				"cloudtower_content_library_vm_template": resourceContentLibraryVmTemplate(),
+				"cloudtower_vm_volume":                   resourceVmVolume(),
+				"cloudtower_vm_disk_attachment":          resourceVmDiskAttachment(),
+				"cloudtower_vds":                         resourceVds(),
+				"cloudtower_vlan":                        resourceVlan(),
+				"cloudtower_vm_folder":                   resourceVmFolder(),
+				"cloudtower_vm_placement_group":          resourceVmPlacementGroup(),
+				"cloudtower_snapshot_plan":               resourceSnapshotPlan(),
+				"cloudtower_consistency_group":           resourceConsistencyGroup(),
+				"cloudtower_consistency_group_snapshot":  resourceConsistencyGroupSnapshot(),
+				"cloudtower_iso":                         resourceIso(),
+				"cloudtower_label":                       resourceLabel(),
 			},
 		}
 
@@ -91,13 +226,49 @@ func configure(version string, p *schema.Provider) func(context.Context, *schema
 		password := d.Get("password").(string)
 		usersource := d.Get("user_source").(string)
 		server := d.Get("cloudtower_server").(string)
+		apiToken := d.Get("api_token").(string)
+		if apiToken == "" && (username == "" || password == "") {
+			return nil, diag.Errorf("either api_token or username and password must be configured")
+		}
 		var usource models.UserSource
 		if usersource == "LDAP" {
 			usource = models.UserSourceLDAP
 		} else {
 			usource = models.UserSourceLOCAL
 		}
-		c, err := cloudtower.NewClient(server, username, password, usource)
+		defaultLabels := make(map[string]string)
+		for k, v := range d.Get("default_labels").(map[string]interface{}) {
+			defaultLabels[k] = v.(string)
+		}
+		c, err := cloudtower.NewClient(cloudtower.ClientConfig{
+			Server:           server,
+			Username:         username,
+			Password:         password,
+			Source:           usource,
+			Token:            apiToken,
+			Organization:     d.Get("organization").(string),
+			TaskPollInterval: time.Duration(d.Get("task_poll_interval").(int)) * time.Second,
+			Scheme:           d.Get("scheme").(string),
+			Retry: utils.RetryWithExponentialBackoffOptions{
+				MaxRetries: d.Get("max_retries").(int),
+				MaxDelay:   time.Duration(d.Get("retry_max_delay").(int)) * time.Second,
+			},
+			RateLimit: cloudtower.RateLimitConfig{
+				RequestsPerSecond:             d.Get("max_requests_per_second").(float64),
+				MaxConcurrentMutations:        d.Get("max_concurrent_mutations").(int),
+				MaxConcurrentClusterCreations: d.Get("max_concurrent_vm_creations_per_cluster").(int),
+			},
+			TLS: cloudtower.TLSConfig{
+				CACertFile:         d.Get("ca_cert_file").(string),
+				CACert:             d.Get("ca_cert").(string),
+				ClientCertFile:     d.Get("client_cert_file").(string),
+				ClientKeyFile:      d.Get("client_key_file").(string),
+				ClientCert:         d.Get("client_cert").(string),
+				ClientKey:          d.Get("client_key").(string),
+				InsecureSkipVerify: d.Get("insecure_skip_verify").(bool),
+			},
+			DefaultLabels: defaultLabels,
+		})
 
 		if err != nil {
 			return nil, diag.FromErr(err)
@@ -0,0 +1,88 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/fake"
+)
+
+// providerFactories are used to instantiate a provider during acceptance testing,
+// the acceptance tests talk to a fake CloudTower so they run without a real cluster
+var providerFactories = map[string]func() (*schema.Provider, error){
+	"cloudtower": func() (*schema.Provider, error) {
+		return New("test")(), nil
+	},
+}
+
+func TestProvider(t *testing.T) {
+	if err := New("test")().InternalValidate(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+// testAccVmConfig is a stopped VM with a disk, a cd-rom and a nic on the fixtures of srv
+func testAccVmConfig(srv *fake.Server, name string) string {
+	return fmt.Sprintf(`
+resource "cloudtower_vm" %[1]q {
+  name       = %[1]q
+  cluster_id = %[2]q
+  vcpu       = 2
+  memory     = 2147483648
+  ha         = true
+  firmware   = "BIOS"
+  status     = "STOPPED"
+
+  disk {
+    boot = 1
+    bus  = "VIRTIO"
+    vm_volume {
+      storage_policy = "REPLICA_2_THIN_PROVISION"
+      name           = "%[1]s-disk"
+      size           = 10737418240
+    }
+  }
+
+  cd_rom {
+    boot   = 2
+    iso_id = %[3]q
+  }
+
+  nic {
+    vlan_id = %[4]q
+  }
+}
+`, name, srv.Fixtures.ClusterId, srv.Fixtures.ElfImageId, srv.Fixtures.VlanId)
+}
+
+// testAccCheckExists checks the resource in the state exists in the collection of the fake CloudTower
+func testAccCheckExists(srv *fake.Server, name string, collection string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("%s not found in state", name)
+		}
+		if srv.Get(collection, rs.Primary.ID) == nil {
+			return fmt.Errorf("%s %s not found in %s", name, rs.Primary.ID, collection)
+		}
+		return nil
+	}
+}
+
+// testAccCheckDestroy checks every resource of the type is removed from the collection of the fake CloudTower
+func testAccCheckDestroy(srv *fake.Server, resourceType string, collection string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != resourceType {
+				continue
+			}
+			if srv.Get(collection, rs.Primary.ID) != nil {
+				return fmt.Errorf("%s %s still exists", resourceType, rs.Primary.ID)
+			}
+		}
+		return nil
+	}
+}
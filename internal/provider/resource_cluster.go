@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"time"
 
 	"github.com/hashicorp/terraform-provider-cloudtower/internal/cloudtower"
 	"github.com/smartxworks/cloudtower-go-sdk/v2/client/cluster"
@@ -19,6 +20,15 @@ func resourceCluster() *schema.Resource {
 		ReadContext:   resourceClusterRead,
 		UpdateContext: resourceClusterUpdate,
 		DeleteContext: resourceClusterDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importState(clusterImportLookup),
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
+			Update: schema.DefaultTimeout(30 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+		CustomizeDiff: labelsCustomizeDiff,
 
 		Schema: map[string]*schema.Schema{
 			"ip": {
@@ -51,6 +61,8 @@ func resourceCluster() *schema.Resource {
 				Computed:    true,
 				Description: "cluster's name",
 			},
+			"labels":     labelsSchema("cluster"),
+			"labels_all": labelsAllSchema("cluster"),
 		},
 	}
 }
@@ -68,6 +80,7 @@ func resourceClusterCreate(ctx context.Context, d *schema.ResourceData, meta int
 		Password:     &password,
 		DatacenterID: &datacenterId,
 	}}
+	ccp.Context = ctx
 	clusters, err := ct.Api.Cluster.ConnectCluster(ccp)
 	if err != nil {
 		return diag.FromErr(err)
@@ -75,7 +88,11 @@ func resourceClusterCreate(ctx context.Context, d *schema.ResourceData, meta int
 	d.SetId(*clusters.Payload[0].Data.ID)
 	err = waitClusterTasksFinish(ctx, ct, clusters.Payload)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromTaskErr(err)
+	}
+	err = updateLabels(ctx, d, ct, labelTarget{cluster: &models.ClusterWhereInput{ID: clusters.Payload[0].Data.ID}})
+	if err != nil {
+		return diagFromTaskErr(err)
 	}
 
 	return resourceClusterRead(ctx, d, meta)
@@ -92,6 +109,7 @@ func resourceClusterRead(ctx context.Context, d *schema.ResourceData, meta inter
 			ID: &id,
 		},
 	}
+	gcp.Context = ctx
 	clusters, err := ct.Api.Cluster.GetClusters(gcp)
 	if err != nil {
 		return diag.FromErr(err)
@@ -100,7 +118,21 @@ func resourceClusterRead(ctx context.Context, d *schema.ResourceData, meta inter
 		d.SetId("")
 		return diags
 	}
-	if err = d.Set("name", clusters.Payload[0].Name); err != nil {
+	c := clusters.Payload[0]
+	if err = d.Set("name", c.Name); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("ip", c.IP); err != nil {
+		return diag.FromErr(err)
+	}
+	datacenterId := ""
+	if len(c.Datacenters) > 0 {
+		datacenterId = *c.Datacenters[0].ID
+	}
+	if err = d.Set("datacenter_id", datacenterId); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = setLabels(ctx, d, ct, c.Labels); err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -109,30 +141,39 @@ func resourceClusterRead(ctx context.Context, d *schema.ResourceData, meta inter
 
 func resourceClusterUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	ct := meta.(*cloudtower.Client)
-	ucp := cluster.NewUpdateClusterParams()
-	username := d.Get("username").(string)
-	password := d.Get("password").(string)
-	ip := d.Get("ip").(string)
-	datacenterId := d.Get("datacenter_id").(string)
 	id := d.Id()
-	ucp.RequestBody = &models.ClusterUpdationParams{
-		Where: &models.ClusterWhereInput{
-			ID: &id,
-		},
-		Data: &models.ClusterUpdationParamsData{
-			IP:           &ip,
-			Username:     &username,
-			Password:     &password,
-			DatacenterID: &datacenterId,
-		},
-	}
-	clusters, err := ct.Api.Cluster.UpdateCluster(ucp)
-	if err != nil {
-		return diag.FromErr(err)
+	if d.HasChanges("ip", "username", "password", "datacenter_id") {
+		ucp := cluster.NewUpdateClusterParams()
+		username := d.Get("username").(string)
+		password := d.Get("password").(string)
+		ip := d.Get("ip").(string)
+		datacenterId := d.Get("datacenter_id").(string)
+		ucp.RequestBody = &models.ClusterUpdationParams{
+			Where: &models.ClusterWhereInput{
+				ID: &id,
+			},
+			Data: &models.ClusterUpdationParamsData{
+				IP:           &ip,
+				Username:     &username,
+				Password:     &password,
+				DatacenterID: &datacenterId,
+			},
+		}
+		ucp.Context = ctx
+		clusters, err := ct.Api.Cluster.UpdateCluster(ucp)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		err = waitClusterTasksFinish(ctx, ct, clusters.Payload)
+		if err != nil {
+			return diagFromTaskErr(err)
+		}
 	}
-	err = waitClusterTasksFinish(ctx, ct, clusters.Payload)
-	if err != nil {
-		return diag.FromErr(err)
+	if d.HasChange("labels_all") {
+		err := updateLabels(ctx, d, ct, labelTarget{cluster: &models.ClusterWhereInput{ID: &id}})
+		if err != nil {
+			return diagFromTaskErr(err)
+		}
 	}
 
 	return resourceClusterRead(ctx, d, meta)
@@ -148,6 +189,7 @@ func resourceClusterDelete(ctx context.Context, d *schema.ResourceData, meta int
 			ID: &id,
 		},
 	}
+	dcp.Context = ctx
 	clusters, err := ct.Api.Cluster.DeleteCluster(dcp)
 	if err != nil {
 		return diag.FromErr(err)
@@ -160,7 +202,7 @@ func resourceClusterDelete(ctx context.Context, d *schema.ResourceData, meta int
 	}
 	_, err = ct.WaitTasksFinish(ctx, taskIds)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromTaskErr(err)
 	}
 
 	d.SetId("")
@@ -177,3 +219,37 @@ func waitClusterTasksFinish(ctx context.Context, ct *cloudtower.Client, clusters
 	_, err := ct.WaitTasksFinish(ctx, taskIds)
 	return err
 }
+
+func clusterImportLookup(ctx context.Context, meta interface{}) *importLookup {
+	ct := meta.(*cloudtower.Client)
+	find := func(where *models.ClusterWhereInput) ([]string, error) {
+		gcp := cluster.NewGetClustersParams()
+		gcp.RequestBody = &models.GetClustersRequestBody{
+			Where: where,
+		}
+		gcp.Context = ctx
+		clusters, err := ct.Api.Cluster.GetClusters(gcp)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, 0, len(clusters.Payload))
+		for _, c := range clusters.Payload {
+			ids = append(ids, *c.ID)
+		}
+		return ids, nil
+	}
+	return &importLookup{
+		kind:   "cluster",
+		format: "cluster_name",
+		byId: func(id string) ([]string, error) {
+			return find(&models.ClusterWhereInput{
+				ID: &id,
+			})
+		},
+		byName: func(names []string) ([]string, error) {
+			return find(&models.ClusterWhereInput{
+				Name: namesAt(names, 0),
+			})
+		},
+	}
+}
@@ -0,0 +1,142 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/fake"
+)
+
+func TestAccResourceCluster(t *testing.T) {
+	srv := fake.NewServer(t)
+	config := func(datacenterId string) string {
+		return srv.ProviderConfig() + fmt.Sprintf(`
+resource "cloudtower_datacenter" "test" {
+  name = "tf-acc-dc"
+}
+
+resource "cloudtower_cluster" "test" {
+  ip            = "192.168.2.10"
+  username      = "root"
+  password      = "password"
+  datacenter_id = %s
+}
+`, datacenterId)
+	}
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckDestroy(srv, "cloudtower_cluster", "clusters"),
+		Steps: []resource.TestStep{
+			{
+				Config: config(fmt.Sprintf("%q", srv.Fixtures.DatacenterId)),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(srv, "cloudtower_cluster.test", "clusters"),
+					resource.TestCheckResourceAttr("cloudtower_cluster.test", "name", "cluster-192.168.2.10"),
+				),
+			},
+			{
+				Config: config("cloudtower_datacenter.test.id"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("cloudtower_cluster.test", "datacenter_id", "cloudtower_datacenter.test", "id"),
+				),
+			},
+			{
+				ResourceName:            "cloudtower_cluster.test",
+				ImportState:             true,
+				ImportStateId:           "cluster-192.168.2.10",
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"username", "password"},
+			},
+		},
+	})
+}
+
+func TestAccResourceCluster_timeout(t *testing.T) {
+	srv := fake.NewServer(t)
+	config := func(timeouts string) string {
+		return srv.ProviderConfig() + fmt.Sprintf(`
+resource "cloudtower_cluster" "test" {
+  ip       = "192.168.2.10"
+  username = "root"
+  password = "password"
+  %s
+}
+`, timeouts)
+	}
+	// the connection task does not finish before the create timeout
+	srv.TaskPolls = 100
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckDestroy(srv, "cloudtower_cluster", "clusters"),
+		Steps: []resource.TestStep{
+			{
+				Config: config(`timeouts {
+    create = "3s"
+  }`),
+				ExpectError: regexp.MustCompile("timeout while waiting for tasks"),
+			},
+			{
+				PreConfig: func() {
+					srv.TaskPolls = 2
+				},
+				Config: config(""),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(srv, "cloudtower_cluster.test", "clusters"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceCluster_labels(t *testing.T) {
+	srv := fake.NewServer(t)
+	name := "cloudtower_cluster.test"
+	provider := strings.Replace(srv.ProviderConfig(), `task_poll_interval = 1`, `task_poll_interval = 1
+  default_labels     = { env = "test" }`, 1)
+	config := func(labels string) string {
+		return provider + fmt.Sprintf(`
+resource "cloudtower_cluster" "test" {
+  ip       = "192.168.2.10"
+  username = "root"
+  password = "password"
+  labels   = %s
+}
+`, labels)
+	}
+	clusterLabels := func(want int) resource.TestCheckFunc {
+		return func(s *terraform.State) error {
+			id := s.RootModule().Resources[name].Primary.ID
+			if labels, _ := srv.Get("clusters", id)["labels"].([]any); len(labels) != want {
+				return fmt.Errorf("cluster %s has %d labels, want %d", id, len(labels), want)
+			}
+			return nil
+		}
+	}
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckDestroy(srv, "cloudtower_cluster", "clusters"),
+		Steps: []resource.TestStep{
+			{
+				Config: config(`{ team = "a" }`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "labels.%", "1"),
+					resource.TestCheckResourceAttr(name, "labels_all.%", "2"),
+					resource.TestCheckResourceAttr(name, "labels_all.env", "test"),
+					clusterLabels(2),
+				),
+			},
+			{
+				Config: config(`{ team = "b", env = "prod" }`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "labels_all.team", "b"),
+					resource.TestCheckResourceAttr(name, "labels_all.env", "prod"),
+					clusterLabels(2),
+				),
+			},
+		},
+	})
+}
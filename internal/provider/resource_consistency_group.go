@@ -0,0 +1,274 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/cloudtower"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/client/consistency_group"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/models"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceConsistencyGroup() *schema.Resource {
+	return &schema.Resource{
+		Description: "CloudTower consistency group resource, the VMs and vm volumes of a group are snapshotted at the same instant by cloudtower_consistency_group_snapshot.",
+
+		CreateContext: resourceConsistencyGroupCreate,
+		ReadContext:   resourceConsistencyGroupRead,
+		UpdateContext: resourceConsistencyGroupUpdate,
+		DeleteContext: resourceConsistencyGroupDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importState(consistencyGroupImportLookup),
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "consistency group's id",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "consistency group's name",
+			},
+			"cluster_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "the id of the cluster the consistency group is created in, its VMs and vm volumes belong to the cluster",
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "consistency group's description",
+			},
+			"vm_ids": {
+				Type:         schema.TypeSet,
+				Optional:     true,
+				Elem:         &schema.Schema{Type: schema.TypeString},
+				AtLeastOneOf: []string{"vm_ids", "vm_volume_ids"},
+				Description:  "the ids of the VMs in the consistency group, a VM is in one consistency group at most",
+			},
+			"vm_volume_ids": {
+				Type:         schema.TypeSet,
+				Optional:     true,
+				Elem:         &schema.Schema{Type: schema.TypeString},
+				AtLeastOneOf: []string{"vm_ids", "vm_volume_ids"},
+				Description:  "the ids of the vm volumes in the consistency group, a vm volume is in one consistency group at most",
+			},
+		},
+	}
+}
+
+func resourceConsistencyGroupCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	ct := meta.(*cloudtower.Client)
+	name := d.Get("name").(string)
+	clusterId := d.Get("cluster_id").(string)
+	description := d.Get("description").(string)
+	cgp := consistency_group.NewCreateConsistencyGroupParams()
+	cgp.RequestBody = []*models.ConsistencyGroupCreationParams{{
+		Name:        &name,
+		ClusterID:   &clusterId,
+		Description: &description,
+	}}
+	if vmIds := expandStringSet(d.Get("vm_ids")); len(vmIds) > 0 {
+		cgp.RequestBody[0].Vms = &models.VMWhereInput{
+			IDIn: vmIds,
+		}
+	}
+	if volumeIds := expandStringSet(d.Get("vm_volume_ids")); len(volumeIds) > 0 {
+		cgp.RequestBody[0].VMVolumes = &models.VMVolumeWhereInput{
+			IDIn: volumeIds,
+		}
+	}
+	cgp.Context = ctx
+	groups, err := ct.Api.ConsistencyGroup.CreateConsistencyGroup(cgp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(*groups.Payload[0].Data.ID)
+	_, err = ct.WaitTasksFinish(ctx, []string{*groups.Payload[0].TaskID})
+	if err != nil {
+		return diagFromTaskErr(err)
+	}
+
+	return resourceConsistencyGroupRead(ctx, d, meta)
+}
+
+func resourceConsistencyGroupRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	ct := meta.(*cloudtower.Client)
+
+	id := d.Id()
+	groups, err := getConsistencyGroups(ctx, ct, &models.ConsistencyGroupWhereInput{
+		ID: &id,
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if len(groups) < 1 {
+		d.SetId("")
+		return diags
+	}
+	group := groups[0]
+	if err = d.Set("name", group.Name); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("cluster_id", group.Cluster.ID); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("description", group.Description); err != nil {
+		return diag.FromErr(err)
+	}
+	vmIds := make([]string, 0, len(group.Vms))
+	for _, v := range group.Vms {
+		vmIds = append(vmIds, *v.ID)
+	}
+	if err = d.Set("vm_ids", vmIds); err != nil {
+		return diag.FromErr(err)
+	}
+	volumeIds := make([]string, 0, len(group.VMVolumes))
+	for _, v := range group.VMVolumes {
+		volumeIds = append(volumeIds, *v.ID)
+	}
+	if err = d.Set("vm_volume_ids", volumeIds); err != nil {
+		return diag.FromErr(err)
+	}
+	return diags
+}
+
+func resourceConsistencyGroupUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	ct := meta.(*cloudtower.Client)
+	id := d.Id()
+	name := d.Get("name").(string)
+	description := d.Get("description").(string)
+	ugp := consistency_group.NewUpdateConsistencyGroupParams()
+	ugp.RequestBody = &models.ConsistencyGroupUpdationParams{
+		Where: &models.ConsistencyGroupWhereInput{
+			ID: &id,
+		},
+		Data: &models.ConsistencyGroupUpdationParamsData{
+			Name:        &name,
+			Description: &description,
+		},
+	}
+	if vmIds := expandStringSet(d.Get("vm_ids")); len(vmIds) > 0 {
+		ugp.RequestBody.Data.Vms = &models.VMWhereInput{
+			IDIn: vmIds,
+		}
+	} else if d.HasChange("vm_ids") {
+		ugp.RequestBody.Data.Vms = vmWhereNone()
+	}
+	if volumeIds := expandStringSet(d.Get("vm_volume_ids")); len(volumeIds) > 0 {
+		ugp.RequestBody.Data.VMVolumes = &models.VMVolumeWhereInput{
+			IDIn: volumeIds,
+		}
+	} else if d.HasChange("vm_volume_ids") {
+		// an empty id_in is left out of the request and would match every vm volume
+		ugp.RequestBody.Data.VMVolumes = &models.VMVolumeWhereInput{IDIn: []string{""}}
+	}
+	ugp.Context = ctx
+	groups, err := ct.Api.ConsistencyGroup.UpdateConsistencyGroup(ugp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	taskIds := make([]string, 0)
+	for _, g := range groups.Payload {
+		if g.TaskID != nil {
+			taskIds = append(taskIds, *g.TaskID)
+		}
+	}
+	_, err = ct.WaitTasksFinish(ctx, taskIds)
+	if err != nil {
+		return diagFromTaskErr(err)
+	}
+
+	return resourceConsistencyGroupRead(ctx, d, meta)
+}
+
+func resourceConsistencyGroupDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	ct := meta.(*cloudtower.Client)
+	id := d.Id()
+	dgp := consistency_group.NewDeleteConsistencyGroupParams()
+	dgp.RequestBody = &models.ConsistencyGroupDeletionParams{
+		Where: &models.ConsistencyGroupWhereInput{
+			ID: &id,
+		},
+	}
+	dgp.Context = ctx
+	groups, err := ct.Api.ConsistencyGroup.DeleteConsistencyGroup(dgp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	taskIds := make([]string, 0)
+	for _, g := range groups.Payload {
+		if g.TaskID != nil {
+			taskIds = append(taskIds, *g.TaskID)
+		}
+	}
+	_, err = ct.WaitTasksFinish(ctx, taskIds)
+	if err != nil {
+		return diagFromTaskErr(err)
+	}
+	d.SetId("")
+	return diags
+}
+
+func getConsistencyGroups(ctx context.Context, ct *cloudtower.Client, where *models.ConsistencyGroupWhereInput) ([]*models.ConsistencyGroup, error) {
+	ggp := consistency_group.NewGetConsistencyGroupsParams()
+	ggp.RequestBody = &models.GetConsistencyGroupsRequestBody{
+		Where: where,
+	}
+	ggp.Context = ctx
+	groups, err := ct.Api.ConsistencyGroup.GetConsistencyGroups(ggp)
+	if err != nil {
+		return nil, err
+	}
+	return groups.Payload, nil
+}
+
+func consistencyGroupImportLookup(ctx context.Context, meta interface{}) *importLookup {
+	ct := meta.(*cloudtower.Client)
+	find := func(where *models.ConsistencyGroupWhereInput) ([]string, error) {
+		groups, err := getConsistencyGroups(ctx, ct, where)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, 0, len(groups))
+		for _, g := range groups {
+			ids = append(ids, *g.ID)
+		}
+		return ids, nil
+	}
+	return &importLookup{
+		kind:   "consistency group",
+		format: "[cluster_name/]group_name",
+		byId: func(id string) ([]string, error) {
+			return find(&models.ConsistencyGroupWhereInput{
+				ID: &id,
+			})
+		},
+		byName: func(names []string) ([]string, error) {
+			where := &models.ConsistencyGroupWhereInput{
+				Name: namesAt(names, 0),
+			}
+			if clusterName := namesAt(names, 1); clusterName != nil {
+				where.Cluster = &models.ClusterWhereInput{
+					Name: clusterName,
+				}
+			}
+			return find(where)
+		},
+	}
+}
@@ -0,0 +1,250 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/cloudtower"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/client/consistency_group_snapshot"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/client/vm_snapshot"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/client/vm_volume_snapshot"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/models"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceConsistencyGroupSnapshot() *schema.Resource {
+	return &schema.Resource{
+		Description: "CloudTower consistency group snapshot resource, a crash consistent snapshot of all the VMs and vm volumes of a cloudtower_consistency_group taken at the same instant. The snapshot of a member VM can be rebuilt by the create_effect.rebuild_from_snapshot of cloudtower_vm.",
+
+		CreateContext: resourceConsistencyGroupSnapshotCreate,
+		ReadContext:   resourceConsistencyGroupSnapshotRead,
+		DeleteContext: resourceConsistencyGroupSnapshotDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importState(consistencyGroupSnapshotImportLookup),
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(20 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "consistency group snapshot's id",
+			},
+			"consistency_group_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "the id of the snapshotted consistency group",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "consistency group snapshot's name",
+			},
+			"vm_snapshot_ids": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "the ids of the vm snapshots of the member VMs by their VM ids",
+			},
+			"vm_volume_snapshot_ids": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "the ids of the vm volume snapshots of the member vm volumes by their vm volume ids",
+			},
+		},
+	}
+}
+
+func resourceConsistencyGroupSnapshotCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	ct := meta.(*cloudtower.Client)
+	groupId := d.Get("consistency_group_id").(string)
+	name := d.Get("name").(string)
+	csp := consistency_group_snapshot.NewCreateConsistencyGroupSnapshotParams()
+	csp.RequestBody = []*models.ConsistencyGroupSnapshotCreationParams{{
+		ConsistencyGroupID: &groupId,
+		Name:               &name,
+	}}
+	csp.Context = ctx
+	snapshots, err := ct.Api.ConsistencyGroupSnapshot.CreateConsistencyGroupSnapshot(csp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(*snapshots.Payload[0].Data.ID)
+	_, err = ct.WaitTasksFinish(ctx, []string{*snapshots.Payload[0].TaskID})
+	if err != nil {
+		return diagFromTaskErr(err)
+	}
+
+	return resourceConsistencyGroupSnapshotRead(ctx, d, meta)
+}
+
+func resourceConsistencyGroupSnapshotRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	ct := meta.(*cloudtower.Client)
+
+	id := d.Id()
+	snapshots, err := getConsistencyGroupSnapshots(ctx, ct, &models.ConsistencyGroupSnapshotWhereInput{
+		ID: &id,
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if len(snapshots) < 1 {
+		d.SetId("")
+		return diags
+	}
+	snapshot := snapshots[0]
+	if err = d.Set("name", snapshot.Name); err != nil {
+		return diag.FromErr(err)
+	}
+	if snapshot.ConsistencyGroup != nil {
+		if err = d.Set("consistency_group_id", snapshot.ConsistencyGroup.ID); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	// the member snapshots only refer to their snapshots, which refer to the snapshotted VMs and volumes
+	vmSnapshotIds := make(map[string]interface{})
+	if len(snapshot.VMSnapshots) > 0 {
+		ids := make([]string, 0, len(snapshot.VMSnapshots))
+		for _, s := range snapshot.VMSnapshots {
+			ids = append(ids, *s.ID)
+		}
+		gvp := vm_snapshot.NewGetVMSnapshotsParams()
+		gvp.RequestBody = &models.GetVMSnapshotsRequestBody{
+			Where: &models.VMSnapshotWhereInput{
+				IDIn: ids,
+			},
+		}
+		gvp.Context = ctx
+		vmSnapshots, err := ct.Api.VMSnapshot.GetVMSnapshots(gvp)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		for _, s := range vmSnapshots.Payload {
+			if s.VM != nil {
+				vmSnapshotIds[*s.VM.ID] = *s.ID
+			}
+		}
+	}
+	if err = d.Set("vm_snapshot_ids", vmSnapshotIds); err != nil {
+		return diag.FromErr(err)
+	}
+	volumeSnapshotIds := make(map[string]interface{})
+	if len(snapshot.VMVolumeSnapshots) > 0 {
+		ids := make([]string, 0, len(snapshot.VMVolumeSnapshots))
+		for _, s := range snapshot.VMVolumeSnapshots {
+			ids = append(ids, *s.ID)
+		}
+		gvp := vm_volume_snapshot.NewGetVMVolumeSnapshotsParams()
+		gvp.RequestBody = &models.GetVMVolumeSnapshotsRequestBody{
+			Where: &models.VMVolumeSnapshotWhereInput{
+				IDIn: ids,
+			},
+		}
+		gvp.Context = ctx
+		volumeSnapshots, err := ct.Api.VMVolumeSnapshot.GetVMVolumeSnapshots(gvp)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		for _, s := range volumeSnapshots.Payload {
+			if s.VMVolume != nil {
+				volumeSnapshotIds[*s.VMVolume.ID] = *s.ID
+			}
+		}
+	}
+	if err = d.Set("vm_volume_snapshot_ids", volumeSnapshotIds); err != nil {
+		return diag.FromErr(err)
+	}
+	return diags
+}
+
+func resourceConsistencyGroupSnapshotDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	ct := meta.(*cloudtower.Client)
+	id := d.Id()
+	dsp := consistency_group_snapshot.NewDeleteConsistencyGroupSnapshotParams()
+	dsp.RequestBody = &models.ConsistencyGroupSnapshotDeletionParams{
+		Where: &models.ConsistencyGroupSnapshotWhereInput{
+			ID: &id,
+		},
+	}
+	dsp.Context = ctx
+	snapshots, err := ct.Api.ConsistencyGroupSnapshot.DeleteConsistencyGroupSnapshot(dsp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	taskIds := make([]string, 0)
+	for _, s := range snapshots.Payload {
+		if s.TaskID != nil {
+			taskIds = append(taskIds, *s.TaskID)
+		}
+	}
+	_, err = ct.WaitTasksFinish(ctx, taskIds)
+	if err != nil {
+		return diagFromTaskErr(err)
+	}
+	d.SetId("")
+	return diags
+}
+
+func getConsistencyGroupSnapshots(ctx context.Context, ct *cloudtower.Client, where *models.ConsistencyGroupSnapshotWhereInput) ([]*models.ConsistencyGroupSnapshot, error) {
+	gsp := consistency_group_snapshot.NewGetConsistencyGroupSnapshotsParams()
+	gsp.RequestBody = &models.GetConsistencyGroupSnapshotsRequestBody{
+		Where: where,
+	}
+	gsp.Context = ctx
+	snapshots, err := ct.Api.ConsistencyGroupSnapshot.GetConsistencyGroupSnapshots(gsp)
+	if err != nil {
+		return nil, err
+	}
+	return snapshots.Payload, nil
+}
+
+func consistencyGroupSnapshotImportLookup(ctx context.Context, meta interface{}) *importLookup {
+	ct := meta.(*cloudtower.Client)
+	find := func(where *models.ConsistencyGroupSnapshotWhereInput) ([]string, error) {
+		snapshots, err := getConsistencyGroupSnapshots(ctx, ct, where)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, 0, len(snapshots))
+		for _, s := range snapshots {
+			ids = append(ids, *s.ID)
+		}
+		return ids, nil
+	}
+	return &importLookup{
+		kind:   "consistency group snapshot",
+		format: "[[cluster_name/]group_name/]snapshot_name",
+		byId: func(id string) ([]string, error) {
+			return find(&models.ConsistencyGroupSnapshotWhereInput{
+				ID: &id,
+			})
+		},
+		byName: func(names []string) ([]string, error) {
+			where := &models.ConsistencyGroupSnapshotWhereInput{
+				Name: namesAt(names, 0),
+			}
+			if groupName := namesAt(names, 1); groupName != nil {
+				where.ConsistencyGroup = &models.ConsistencyGroupWhereInput{
+					Name: groupName,
+				}
+				if clusterName := namesAt(names, 2); clusterName != nil {
+					where.ConsistencyGroup.Cluster = &models.ClusterWhereInput{
+						Name: clusterName,
+					}
+				}
+			}
+			return find(where)
+		},
+	}
+}
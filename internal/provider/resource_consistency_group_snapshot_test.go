@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/fake"
+)
+
+func TestAccResourceConsistencyGroupSnapshot(t *testing.T) {
+	srv := fake.NewServer(t)
+	name := "cloudtower_consistency_group_snapshot.test"
+	config := testAccConsistencyGroupConfig(srv, `[cloudtower_vm.tf-acc-vm-a.id, cloudtower_vm.tf-acc-vm-b.id]`) + `
+resource "cloudtower_consistency_group_snapshot" "test" {
+  name                 = "tf-acc-group-snapshot"
+  consistency_group_id = cloudtower_consistency_group.test.id
+}
+`
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckDestroy(srv, "cloudtower_consistency_group_snapshot", "consistency-group-snapshots"),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(srv, name, "consistency-group-snapshots"),
+					resource.TestCheckResourceAttr(name, "vm_snapshot_ids.%", "2"),
+					resource.TestCheckResourceAttr(name, "vm_volume_snapshot_ids.%", "1"),
+				),
+			},
+			{
+				// a member of the group snapshot is rebuilt like any vm snapshot
+				Config: config + `
+resource "cloudtower_vm" "restored" {
+  name       = "tf-acc-vm-restored"
+  cluster_id = cloudtower_consistency_group.test.cluster_id
+  ha         = false
+  memory     = 2147483648
+
+  create_effect {
+    rebuild_from_snapshot = cloudtower_consistency_group_snapshot.test.vm_snapshot_ids[cloudtower_vm.tf-acc-vm-a.id]
+  }
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(srv, "cloudtower_vm.restored", "vms"),
+					resource.TestCheckResourceAttr("cloudtower_vm.restored", "name", "tf-acc-vm-restored"),
+				),
+			},
+			{
+				ResourceName:      name,
+				ImportState:       true,
+				ImportStateId:     srv.Fixtures.ClusterName + "/tf-acc-consistency-group/tf-acc-group-snapshot",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
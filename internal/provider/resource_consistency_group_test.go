@@ -0,0 +1,77 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/fake"
+)
+
+// testAccConsistencyGroupConfig is a consistency group of vmIds and the volume of testAccVmVolumeConfig
+func testAccConsistencyGroupConfig(srv *fake.Server, vmIds string) string {
+	return srv.ProviderConfig() + testAccVmConfig(srv, "tf-acc-vm-a") + testAccVmConfig(srv, "tf-acc-vm-b") +
+		testAccVmVolumeConfig(srv, "tf-acc-volume", 10737418240, "REPLICA_2_THIN_PROVISION") + fmt.Sprintf(`
+resource "cloudtower_consistency_group" "test" {
+  name          = "tf-acc-consistency-group"
+  cluster_id    = %q
+  vm_ids        = %s
+  vm_volume_ids = [cloudtower_vm_volume.test.id]
+}
+`, srv.Fixtures.ClusterId, vmIds)
+}
+
+func TestAccResourceConsistencyGroup(t *testing.T) {
+	srv := fake.NewServer(t)
+	name := "cloudtower_consistency_group.test"
+	groupMembers := func(field string, want int) resource.TestCheckFunc {
+		return func(s *terraform.State) error {
+			id := s.RootModule().Resources[name].Primary.ID
+			if members := srv.Get("consistency-groups", id)[field].([]any); len(members) != want {
+				return fmt.Errorf("consistency group %s has %d %s, want %d", id, len(members), field, want)
+			}
+			return nil
+		}
+	}
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckDestroy(srv, "cloudtower_consistency_group", "consistency-groups"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccConsistencyGroupConfig(srv, `[cloudtower_vm.tf-acc-vm-a.id]`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(srv, name, "consistency-groups"),
+					resource.TestCheckResourceAttr(name, "vm_ids.#", "1"),
+					resource.TestCheckTypeSetElemAttrPair(name, "vm_ids.*", "cloudtower_vm.tf-acc-vm-a", "id"),
+					resource.TestCheckTypeSetElemAttrPair(name, "vm_volume_ids.*", "cloudtower_vm_volume.test", "id"),
+				),
+			},
+			{
+				Config: testAccConsistencyGroupConfig(srv, `[cloudtower_vm.tf-acc-vm-a.id, cloudtower_vm.tf-acc-vm-b.id]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "vm_ids.#", "2"),
+					resource.TestCheckTypeSetElemAttrPair(name, "vm_ids.*", "cloudtower_vm.tf-acc-vm-b", "id"),
+				),
+			},
+			{
+				// no vm_volume_ids clears the vm volumes of the group instead of adding every vm volume
+				Config: strings.Replace(testAccConsistencyGroupConfig(srv, `[cloudtower_vm.tf-acc-vm-a.id]`),
+					"  vm_volume_ids = [cloudtower_vm_volume.test.id]\n", "", 1),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "vm_ids.#", "1"),
+					resource.TestCheckResourceAttr(name, "vm_volume_ids.#", "0"),
+					groupMembers("vms", 1),
+					groupMembers("vm_volumes", 0),
+				),
+			},
+			{
+				ResourceName:      name,
+				ImportState:       true,
+				ImportStateId:     srv.Fixtures.ClusterName + "/tf-acc-consistency-group",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/hashicorp/terraform-provider-cloudtower/internal/cloudtower"
 	"github.com/hashicorp/terraform-provider-cloudtower/internal/helper"
@@ -23,12 +24,22 @@ func resourceContentLibraryVmTemplate() *schema.Resource {
 		ReadContext:   resourceContentLibraryVmTemplateRead,
 		DeleteContext: resourceContentLibraryVmTemplateDelete,
 		UpdateContext: resourceContentLibraryVmTemplateUpdate,
+		Importer: &schema.ResourceImporter{
+			StateContext: importState(contentLibraryVmTemplateImportLookup),
+		},
+		CustomizeDiff: labelsCustomizeDiff,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(60 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
 
 		Schema: map[string]*schema.Schema{
 			"src_vm_id": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Id of source vm from created vm to be cloned from",
+				Type:             schema.TypeString,
+				Required:         true,
+				DiffSuppressFunc: suppressAfterCreate,
+				Description:      "Id of source vm from created vm to be cloned from, changes are ignored once the template is created",
 			},
 			"cluster_id": {
 				Type:     schema.TypeList,
@@ -55,6 +66,8 @@ func resourceContentLibraryVmTemplate() *schema.Resource {
 				Description: "VM template's description",
 				Optional:    true,
 			},
+			"labels":     labelsSchema("content library VM template"),
+			"labels_all": labelsAllSchema("content library VM template"),
 			"id": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -189,6 +202,7 @@ func resourceContentLibraryVmTemplateCreate(ctx context.Context, d *schema.Resou
 				},
 			},
 		}
+		cclvtfv.Context = ctx
 		response, err := ct.Api.ContentLibraryVMTemplate.CloneContentLibraryVMTemplateFromVM(cclvtfv)
 		if err != nil {
 			return diag.FromErr(err)
@@ -200,7 +214,11 @@ func resourceContentLibraryVmTemplateCreate(ctx context.Context, d *schema.Resou
 	d.SetId(*templates[0].Data.ID)
 	_, err := ct.WaitTasksFinish(ctx, []string{*templates[0].TaskID})
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromTaskErr(err)
+	}
+	err = updateLabels(ctx, d, ct, labelTarget{contentLibraryVmTemplate: &models.ContentLibraryVMTemplateWhereInput{ID: templates[0].Data.ID}})
+	if err != nil {
+		return diagFromTaskErr(err)
 	}
 	return resourceContentLibraryVmTemplateRead(ctx, d, meta)
 }
@@ -210,7 +228,6 @@ func resourceContentLibraryVmTemplateRead(ctx context.Context, d *schema.Resourc
 	ct := meta.(*cloudtower.Client)
 
 	id := d.Id()
-	num := int32(1)
 	gvtp := vm_template.NewGetVMTemplatesParams()
 	gvtp.RequestBody = &models.GetVMTemplatesRequestBody{
 		Where: &models.VMTemplateWhereInput{
@@ -218,8 +235,8 @@ func resourceContentLibraryVmTemplateRead(ctx context.Context, d *schema.Resourc
 				ID: &id,
 			},
 		},
-		First: &num,
 	}
+	gvtp.Context = ctx
 	vmTemplates, err := ct.Api.VMTemplate.GetVMTemplates(gvtp)
 	if err != nil {
 		return diag.FromErr(err)
@@ -232,6 +249,52 @@ func resourceContentLibraryVmTemplateRead(ctx context.Context, d *schema.Resourc
 	if err = d.Set("name", template.Name); err != nil {
 		diags = append(diags, diag.FromErr(err)...)
 	}
+	if err = d.Set("description", template.Description); err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+	}
+	if err = d.Set("cloud_init_supported", template.CloudInitSupported); err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+	}
+	// labels are on the content library template, not on the VM templates it is distributed as
+	gclvtp := content_library_vm_template.NewGetContentLibraryVMTemplatesParams()
+	gclvtp.RequestBody = &models.GetContentLibraryVMTemplatesRequestBody{
+		Where: &models.ContentLibraryVMTemplateWhereInput{
+			ID: &id,
+		},
+	}
+	gclvtp.Context = ctx
+	clTemplates, err := ct.Api.ContentLibraryVMTemplate.GetContentLibraryVMTemplates(gclvtp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if len(clTemplates.Payload) > 0 {
+		if err = setLabels(ctx, d, ct, clTemplates.Payload[0].Labels); err != nil {
+			diags = append(diags, diag.FromErr(err)...)
+		}
+	}
+	// the template is distributed as a VM template in each of its clusters,
+	// keep the configured order when the clusters are the same
+	clusterIds := make([]string, 0, len(vmTemplates.Payload))
+	distributed := make(map[string]bool, len(vmTemplates.Payload))
+	for _, t := range vmTemplates.Payload {
+		if t.Cluster != nil {
+			clusterIds = append(clusterIds, *t.Cluster.ID)
+			distributed[*t.Cluster.ID] = true
+		}
+	}
+	configured, clusterDiags := getClusterIds(d)
+	if clusterDiags != nil {
+		return append(diags, clusterDiags...)
+	}
+	sameClusters := len(*configured) == len(clusterIds)
+	for _, clusterId := range *configured {
+		sameClusters = sameClusters && distributed[clusterId]
+	}
+	if !sameClusters {
+		if err = d.Set("cluster_id", clusterIds); err != nil {
+			diags = append(diags, diag.FromErr(err)...)
+		}
+	}
 	var disks []map[string]interface{} = make([]map[string]interface{}, 0)
 	var cdroms []map[string]interface{} = make([]map[string]interface{}, 0)
 	for _, disk := range template.VMDisks {
@@ -311,6 +374,7 @@ func resourceContentLibraryVmTemplateDelete(ctx context.Context, d *schema.Resou
 			ID: &id,
 		},
 	}
+	dclvtp.Context = ctx
 	templates, err := ct.Api.ContentLibraryVMTemplate.DeleteContentLibraryVMTemplate(dclvtp)
 	if err != nil {
 		return diag.FromErr(err)
@@ -323,7 +387,7 @@ func resourceContentLibraryVmTemplateDelete(ctx context.Context, d *schema.Resou
 	}
 	_, err = ct.WaitTasksFinish(ctx, taskIds)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromTaskErr(err)
 	}
 	d.SetId("")
 	return diags
@@ -347,6 +411,7 @@ func resourceContentLibraryVmTemplateUpdate(ctx context.Context, d *schema.Resou
 				CloudInitSupported: &cloudInitSupported,
 			},
 		}
+		uvtp.Context = ctx
 		_, err := ct.Api.ContentLibraryVMTemplate.UpdateContentLibraryVMTemplate(uvtp)
 		if err != nil {
 			return diag.FromErr(err)
@@ -369,6 +434,12 @@ func resourceContentLibraryVmTemplateUpdate(ctx context.Context, d *schema.Resou
 			},
 		}
 	}
+	if d.HasChange("labels_all") {
+		err := updateLabels(ctx, d, ct, labelTarget{contentLibraryVmTemplate: &models.ContentLibraryVMTemplateWhereInput{ID: &id}})
+		if err != nil {
+			return diagFromTaskErr(err)
+		}
+	}
 	return resourceContentLibraryVmTemplateRead(ctx, d, meta)
 }
 
@@ -387,3 +458,37 @@ func getClusterIds(d *schema.ResourceData) (*[]string, diag.Diagnostics) {
 	}
 	return &cluster_ids, nil
 }
+
+func contentLibraryVmTemplateImportLookup(ctx context.Context, meta interface{}) *importLookup {
+	ct := meta.(*cloudtower.Client)
+	find := func(where *models.ContentLibraryVMTemplateWhereInput) ([]string, error) {
+		gcp := content_library_vm_template.NewGetContentLibraryVMTemplatesParams()
+		gcp.RequestBody = &models.GetContentLibraryVMTemplatesRequestBody{
+			Where: where,
+		}
+		gcp.Context = ctx
+		templates, err := ct.Api.ContentLibraryVMTemplate.GetContentLibraryVMTemplates(gcp)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, 0, len(templates.Payload))
+		for _, t := range templates.Payload {
+			ids = append(ids, *t.ID)
+		}
+		return ids, nil
+	}
+	return &importLookup{
+		kind:   "content library VM template",
+		format: "template_name",
+		byId: func(id string) ([]string, error) {
+			return find(&models.ContentLibraryVMTemplateWhereInput{
+				ID: &id,
+			})
+		},
+		byName: func(names []string) ([]string, error) {
+			return find(&models.ContentLibraryVMTemplateWhereInput{
+				Name: namesAt(names, 0),
+			})
+		},
+	}
+}
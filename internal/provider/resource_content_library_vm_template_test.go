@@ -0,0 +1,51 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/fake"
+)
+
+func TestAccResourceContentLibraryVmTemplate(t *testing.T) {
+	srv := fake.NewServer(t)
+	config := func(description string) string {
+		return srv.ProviderConfig() + testAccVmConfig(srv, "tf-acc-vm") + fmt.Sprintf(`
+resource "cloudtower_content_library_vm_template" "test" {
+  name                 = "tf-acc-clt"
+  src_vm_id            = cloudtower_vm.tf-acc-vm.id
+  cluster_id           = [%q]
+  cloud_init_supported = false
+  description          = %q
+}
+`, srv.Fixtures.ClusterId, description)
+	}
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckDestroy(srv, "cloudtower_content_library_vm_template", "content-library-vm-templates"),
+		Steps: []resource.TestStep{
+			{
+				Config: config("created by acceptance tests"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(srv, "cloudtower_content_library_vm_template.test", "content-library-vm-templates"),
+					resource.TestCheckResourceAttr("cloudtower_content_library_vm_template.test", "name", "tf-acc-clt"),
+					resource.TestCheckResourceAttr("cloudtower_content_library_vm_template.test", "disks.#", "1"),
+					resource.TestCheckResourceAttr("cloudtower_content_library_vm_template.test", "nics.#", "1"),
+				),
+			},
+			{
+				Config: config("updated by acceptance tests"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudtower_content_library_vm_template.test", "description", "updated by acceptance tests"),
+				),
+			},
+			{
+				ResourceName:            "cloudtower_content_library_vm_template.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"src_vm_id"},
+			},
+		},
+	})
+}
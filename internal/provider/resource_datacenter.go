@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"time"
 
 	"github.com/hashicorp/terraform-provider-cloudtower/internal/cloudtower"
 	"github.com/smartxworks/cloudtower-go-sdk/v2/client/datacenter"
@@ -20,6 +21,14 @@ func resourceDatacenter() *schema.Resource {
 		ReadContext:   resourceDatacenterRead,
 		UpdateContext: resourceDatacenterUpdate,
 		DeleteContext: resourceDatacenterDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importState(datacenterImportLookup),
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 
 		Schema: map[string]*schema.Schema{
 			"id": {
@@ -44,6 +53,7 @@ func resourceDatacenterCreate(ctx context.Context, d *schema.ResourceData, meta
 		Name:           &name,
 		OrganizationID: &ct.OrgId,
 	}}
+	cdp.Context = ctx
 	datacenters, err := ct.Api.Datacenter.CreateDatacenter(cdp)
 	if err != nil {
 		return diag.FromErr(err)
@@ -64,6 +74,7 @@ func resourceDatacenterRead(ctx context.Context, d *schema.ResourceData, meta in
 			ID: &id,
 		},
 	}
+	gdp.Context = ctx
 	datacenters, err := ct.Api.Datacenter.GetDatacenters(gdp)
 	if err != nil {
 		return diag.FromErr(err)
@@ -92,6 +103,7 @@ func resourceDatacenterUpdate(ctx context.Context, d *schema.ResourceData, meta
 			Name: &name,
 		},
 	}
+	udp.Context = ctx
 	_, err := ct.Api.Datacenter.UpdateDatacenter(udp)
 	if err != nil {
 		return diag.FromErr(err)
@@ -110,6 +122,7 @@ func resourceDatacenterDelete(ctx context.Context, d *schema.ResourceData, meta
 			ID: &id,
 		},
 	}
+	ddp.Context = ctx
 	_, err := ct.Api.Datacenter.DeleteDatacenter(ddp)
 	if err != nil {
 		return diag.FromErr(err)
@@ -118,3 +131,40 @@ func resourceDatacenterDelete(ctx context.Context, d *schema.ResourceData, meta
 	d.SetId("")
 	return diags
 }
+
+func datacenterImportLookup(ctx context.Context, meta interface{}) *importLookup {
+	ct := meta.(*cloudtower.Client)
+	find := func(where *models.DatacenterWhereInput) ([]string, error) {
+		gdp := datacenter.NewGetDatacentersParams()
+		gdp.RequestBody = &models.GetDatacentersRequestBody{
+			Where: where,
+		}
+		gdp.Context = ctx
+		datacenters, err := ct.Api.Datacenter.GetDatacenters(gdp)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, 0, len(datacenters.Payload))
+		for _, dc := range datacenters.Payload {
+			ids = append(ids, *dc.ID)
+		}
+		return ids, nil
+	}
+	return &importLookup{
+		kind:   "datacenter",
+		format: "datacenter_name",
+		byId: func(id string) ([]string, error) {
+			return find(&models.DatacenterWhereInput{
+				ID: &id,
+			})
+		},
+		byName: func(names []string) ([]string, error) {
+			return find(&models.DatacenterWhereInput{
+				Name: namesAt(names, 0),
+				Organization: &models.OrganizationWhereInput{
+					ID: &ct.OrgId,
+				},
+			})
+		},
+	}
+}
@@ -0,0 +1,46 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/fake"
+)
+
+func TestAccResourceDatacenter(t *testing.T) {
+	srv := fake.NewServer(t)
+	config := func(name string) string {
+		return srv.ProviderConfig() + fmt.Sprintf(`
+resource "cloudtower_datacenter" "test" {
+  name = %q
+}
+`, name)
+	}
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckDestroy(srv, "cloudtower_datacenter", "datacenters"),
+		Steps: []resource.TestStep{
+			{
+				Config: config("tf-acc-dc"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(srv, "cloudtower_datacenter.test", "datacenters"),
+					resource.TestCheckResourceAttr("cloudtower_datacenter.test", "name", "tf-acc-dc"),
+				),
+			},
+			{
+				Config: config("tf-acc-dc-renamed"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(srv, "cloudtower_datacenter.test", "datacenters"),
+					resource.TestCheckResourceAttr("cloudtower_datacenter.test", "name", "tf-acc-dc-renamed"),
+				),
+			},
+			{
+				ResourceName:      "cloudtower_datacenter.test",
+				ImportState:       true,
+				ImportStateId:     "tf-acc-dc-renamed",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
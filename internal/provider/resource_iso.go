@@ -0,0 +1,587 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/cloudtower"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/utils"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/client/elf_image"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/client/upload_task"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/models"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/id"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// sha256Regexp matches a hex encoded sha256 checksum
+var sha256Regexp = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+func resourceIso() *schema.Resource {
+	return &schema.Resource{
+		Description: "CloudTower iso resource, a local file uploaded as an ISO to one or more clusters. The file is uploaded in chunks, an upload interrupted by a failure is resumed by the next apply instead of being started over. An iso whose creation failed is tainted and uploaded again, unless it is untainted first.",
+
+		CreateContext: resourceIsoCreate,
+		ReadContext:   resourceIsoRead,
+		UpdateContext: resourceIsoUpdate,
+		DeleteContext: resourceIsoDelete,
+		CustomizeDiff: func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+			if d.Id() != "" && d.HasChange("cluster_ids") {
+				if err := d.SetNewComputed("image_ids"); err != nil {
+					return err
+				}
+				return d.SetNewComputed("upload_task_ids")
+			}
+			return nil
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(60 * time.Minute),
+			Update: schema.DefaultTimeout(60 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "iso's id",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "iso's name",
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "iso's description",
+			},
+			"source": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "the path of the local file to upload",
+			},
+			"sha256": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringMatch(sha256Regexp, "should be a hex encoded sha256 checksum"),
+				Description:  "the sha256 checksum of the source file, the file is not uploaded if it does not match. Set it to filesha256(source) to upload the file again when it changes",
+			},
+			"cluster_ids": {
+				Type:        schema.TypeSet,
+				Required:    true,
+				MinItems:    1,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "the ids of the clusters the iso is uploaded to, it is deleted from the clusters removed",
+			},
+			"chunk_size": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      4 * cloudtower.MiB,
+				ValidateFunc: validation.IntAtLeast(cloudtower.KiB),
+				Description:  "the size in bytes of the chunks the file is uploaded in, a resumed upload keeps the chunk size it was started with",
+			},
+			"size": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "iso's size in bytes",
+			},
+			"image_ids": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "the ids of the elf images of the iso by their cluster ids, to be used as iso_id of cd_rom in cloudtower_vm",
+			},
+			"upload_task_ids": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "the ids of the unfinished upload tasks of the iso by their cluster ids, only these uploads are resumed by the next apply",
+			},
+		},
+	}
+}
+
+func resourceIsoCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	ct := meta.(*cloudtower.Client)
+	source := d.Get("source").(string)
+	sum, size, err := fileSha256(source)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if expected := d.Get("sha256").(string); expected != "" && !strings.EqualFold(expected, sum) {
+		return diag.Errorf("the sha256 of %s is %s, expected %s", source, sum, expected)
+	}
+	d.SetId(id.UniqueId())
+	if err = d.Set("sha256", sum); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("size", size); err != nil {
+		return diag.FromErr(err)
+	}
+
+	// the images uploaded are kept in the state even if a later upload fails, so they are
+	// deleted when the tainted iso is replaced
+	imageIds := make(map[string]interface{})
+	for _, clusterId := range sortedStringSet(d.Get("cluster_ids")) {
+		imageId, err := uploadIso(ctx, ct, d, clusterId)
+		if err != nil {
+			_ = d.Set("image_ids", imageIds)
+			return diag.FromErr(err)
+		}
+		imageIds[clusterId] = imageId
+	}
+	if err = d.Set("image_ids", imageIds); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceIsoRead(ctx, d, meta)
+}
+
+func resourceIsoRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	ct := meta.(*cloudtower.Client)
+
+	ids := make([]string, 0)
+	for _, imageId := range d.Get("image_ids").(map[string]interface{}) {
+		ids = append(ids, imageId.(string))
+	}
+	if len(ids) == 0 {
+		return readUnuploadedIso(d)
+	}
+	images, err := getIsoImages(ctx, ct, &models.ElfImageWhereInput{
+		IDIn: ids,
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	// the clusters whose image is gone are dropped, so the iso is uploaded to them again
+	imageIds := make(map[string]interface{})
+	clusterIds := make([]string, 0, len(images))
+	for _, image := range images {
+		if image.Cluster == nil {
+			continue
+		}
+		imageIds[*image.Cluster.ID] = *image.ID
+		clusterIds = append(clusterIds, *image.Cluster.ID)
+	}
+	if len(imageIds) == 0 {
+		return readUnuploadedIso(d)
+	}
+	if err = d.Set("name", images[0].Name); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("description", images[0].Description); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("size", images[0].Size); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("cluster_ids", clusterIds); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("image_ids", imageIds); err != nil {
+		return diag.FromErr(err)
+	}
+	return diags
+}
+
+// readUnuploadedIso reads an iso which is in no cluster, it is gone unless an upload of it is
+// unfinished, the upload is resumed by the next apply then
+func readUnuploadedIso(d *schema.ResourceData) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if len(d.Get("upload_task_ids").(map[string]interface{})) == 0 {
+		d.SetId("")
+		return diags
+	}
+	if err := d.Set("cluster_ids", []string{}); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("image_ids", map[string]interface{}{}); err != nil {
+		return diag.FromErr(err)
+	}
+	return diags
+}
+
+func resourceIsoUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	ct := meta.(*cloudtower.Client)
+	oldImageIds, _ := d.GetChange("image_ids")
+	imageIds := make(map[string]interface{})
+	clusterIds := make(map[string]bool)
+	for _, clusterId := range expandStringSet(d.Get("cluster_ids")) {
+		clusterIds[clusterId] = true
+	}
+	removed := make([]string, 0)
+	kept := make([]string, 0)
+	for clusterId, imageId := range oldImageIds.(map[string]interface{}) {
+		if clusterIds[clusterId] {
+			imageIds[clusterId] = imageId
+			kept = append(kept, imageId.(string))
+		} else {
+			removed = append(removed, imageId.(string))
+		}
+	}
+
+	if len(removed) > 0 {
+		if err := deleteIsoImages(ctx, ct, removed); err != nil {
+			return diagFromTaskErr(err)
+		}
+	}
+	// the unfinished uploads to the clusters removed are not resumed
+	taskIds := d.Get("upload_task_ids").(map[string]interface{})
+	for clusterId := range taskIds {
+		if !clusterIds[clusterId] {
+			delete(taskIds, clusterId)
+		}
+	}
+	if err := d.Set("upload_task_ids", taskIds); err != nil {
+		return diag.FromErr(err)
+	}
+	if d.HasChanges("name", "description") && len(kept) > 0 {
+		name := d.Get("name").(string)
+		description := d.Get("description").(string)
+		uip := elf_image.NewUpdateElfImageParams()
+		uip.RequestBody = &models.ElfImageUpdationParams{
+			Where: &models.ElfImageWhereInput{
+				IDIn: kept,
+			},
+			Data: &models.ElfImageUpdationParamsData{
+				Name:        &name,
+				Description: &description,
+			},
+		}
+		uip.Context = ctx
+		images, err := ct.Api.ElfImage.UpdateElfImage(uip)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		taskIds := make([]string, 0)
+		for _, i := range images.Payload {
+			if i.TaskID != nil {
+				taskIds = append(taskIds, *i.TaskID)
+			}
+		}
+		_, err = ct.WaitTasksFinish(ctx, taskIds)
+		if err != nil {
+			return diagFromTaskErr(err)
+		}
+	}
+	for _, clusterId := range sortedStringSet(d.Get("cluster_ids")) {
+		if _, ok := imageIds[clusterId]; ok {
+			continue
+		}
+		// the source may have changed since it was uploaded to the other clusters
+		if sum, _, err := fileSha256(d.Get("source").(string)); err != nil {
+			return diag.FromErr(err)
+		} else if !strings.EqualFold(sum, d.Get("sha256").(string)) {
+			return diag.Errorf("the sha256 of %s is %s, expected %s", d.Get("source"), sum, d.Get("sha256"))
+		}
+		imageId, err := uploadIso(ctx, ct, d, clusterId)
+		if err != nil {
+			_ = d.Set("image_ids", imageIds)
+			return diag.FromErr(err)
+		}
+		imageIds[clusterId] = imageId
+	}
+	if err := d.Set("image_ids", imageIds); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceIsoRead(ctx, d, meta)
+}
+
+func resourceIsoDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	ct := meta.(*cloudtower.Client)
+	ids := make([]string, 0)
+	for _, imageId := range d.Get("image_ids").(map[string]interface{}) {
+		ids = append(ids, imageId.(string))
+	}
+	if len(ids) > 0 {
+		if err := deleteIsoImages(ctx, ct, ids); err != nil {
+			return diagFromTaskErr(err)
+		}
+	}
+	d.SetId("")
+	return diags
+}
+
+func deleteIsoImages(ctx context.Context, ct *cloudtower.Client, ids []string) error {
+	dip := elf_image.NewDeleteElfImageParams()
+	dip.RequestBody = &models.ElfImageDeletionParams{
+		Where: &models.ElfImageWhereInput{
+			IDIn: ids,
+		},
+	}
+	dip.Context = ctx
+	images, err := ct.Api.ElfImage.DeleteElfImage(dip)
+	if err != nil {
+		return err
+	}
+	taskIds := make([]string, 0)
+	for _, i := range images.Payload {
+		if i.TaskID != nil {
+			taskIds = append(taskIds, *i.TaskID)
+		}
+	}
+	_, err = ct.WaitTasksFinish(ctx, taskIds)
+	return err
+}
+
+func getIsoImages(ctx context.Context, ct *cloudtower.Client, where *models.ElfImageWhereInput) ([]*models.ElfImage, error) {
+	gip := elf_image.NewGetElfImagesParams()
+	gip.RequestBody = &models.GetElfImagesRequestBody{
+		Where: where,
+	}
+	gip.Context = ctx
+	images, err := ct.Api.ElfImage.GetElfImages(gip)
+	if err != nil {
+		return nil, err
+	}
+	return images.Payload, nil
+}
+
+// isoUpload is the upload of the source of an iso to a cluster
+type isoUpload struct {
+	clusterId   string
+	name        string
+	description string
+	size        int64
+	chunkSize   int64
+	task        *models.UploadTask
+}
+
+// uploadIso uploads the source of d to a cluster and returns the id of the elf image created.
+// The upload task is recorded in upload_task_ids of d until it finishes, an unfinished one is
+// resumed from the chunks it has received. The file is hashed as it is read, the elf image is
+// deleted if it does not match the sha256 of d.
+func uploadIso(ctx context.Context, ct *cloudtower.Client, d *schema.ResourceData, clusterId string) (string, error) {
+	source := d.Get("source").(string)
+	f, err := os.Open(source)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	upload := &isoUpload{
+		clusterId:   clusterId,
+		name:        d.Get("name").(string),
+		description: d.Get("description").(string),
+		size:        info.Size(),
+		chunkSize:   int64(d.Get("chunk_size").(int)),
+	}
+	taskIds := d.Get("upload_task_ids").(map[string]interface{})
+	if taskId, ok := taskIds[clusterId]; ok {
+		task, err := getIsoUploadTask(ctx, ct, taskId.(string))
+		if err != nil {
+			return "", err
+		}
+		// a task which failed, or of a file of another size, is started over
+		if task != nil && *task.Status == models.UploadTaskStatusUPLOADING &&
+			fmt.Sprint(task.Args["size"]) == strconv.FormatInt(upload.size, 10) {
+			upload.task = task
+			upload.chunkSize = *upload.task.ChunkSize
+			tflog.Info(ctx, "resuming iso upload", map[string]interface{}{
+				"name":       upload.name,
+				"cluster_id": clusterId,
+				"chunk":      *upload.task.CurrentChunk,
+			})
+		}
+	}
+
+	hash := sha256.New()
+	chunk := make([]byte, upload.chunkSize)
+	lastProgress := int64(-1)
+	for index := int64(0); index*upload.chunkSize < upload.size; index++ {
+		n, err := io.ReadFull(f, chunk)
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return "", fmt.Errorf("failed to read %s: %w", source, err)
+		}
+		hash.Write(chunk[:n])
+		if upload.task != nil && *upload.task.CurrentChunk > index {
+			continue
+		}
+		if upload.task, err = uploadIsoChunk(ctx, ct, upload, index, chunk[:n]); err != nil {
+			return "", err
+		}
+		if taskIds[clusterId] != *upload.task.ID {
+			taskIds[clusterId] = *upload.task.ID
+			if err = d.Set("upload_task_ids", taskIds); err != nil {
+				return "", err
+			}
+		}
+		progress := min(100, (index+1)*upload.chunkSize*100/upload.size)
+		if progress/10 > lastProgress/10 {
+			tflog.Info(ctx, "uploading iso", map[string]interface{}{
+				"name":       upload.name,
+				"cluster_id": clusterId,
+				"progress":   fmt.Sprintf("%d%%", progress),
+			})
+			lastProgress = progress
+		}
+	}
+	if err = waitIsoUploadTask(ctx, ct, upload); err != nil {
+		return "", err
+	}
+	delete(taskIds, clusterId)
+	if err = d.Set("upload_task_ids", taskIds); err != nil {
+		return "", err
+	}
+
+	// the elf image is taken from the finished task, other uploads of the same name may finish meanwhile
+	imageId, ok := upload.task.Args["elf_image_id"].(string)
+	if !ok || imageId == "" {
+		return "", fmt.Errorf("elf image %s is not found in upload task %s to cluster %s", upload.name, *upload.task.ID, clusterId)
+	}
+	// the upload task is finished either way, so the changed file is not resumed by the next apply
+	if sum := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(sum, d.Get("sha256").(string)) {
+		if err = deleteIsoImages(ctx, ct, []string{imageId}); err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("%s was changed while being uploaded, its sha256 is %s instead of %s", source, sum, d.Get("sha256"))
+	}
+	return imageId, nil
+}
+
+// errIsoUploadTaskGone is returned when the upload task disappears in the middle of an upload
+var errIsoUploadTaskGone = errors.New("upload task is not found")
+
+// uploadIsoChunk uploads the chunk at index, the first chunk starts the upload task.
+// A chunk failed with a transient error is retried, unless the upload task shows it was
+// received before the connection dropped. The upload fails if its task is gone, since
+// the following chunks can not start a new one.
+func uploadIsoChunk(ctx context.Context, ct *cloudtower.Client, upload *isoUpload, index int64, chunk []byte) (*models.UploadTask, error) {
+	opts := ct.RetryOptions
+	classifier := opts.Classifier
+	if classifier == nil {
+		classifier = utils.ClassifyError
+	}
+	opts.Classifier = func(err error) (bool, time.Duration) {
+		if errors.Is(err, errIsoUploadTaskGone) {
+			return false, 0
+		}
+		return classifier(err)
+	}
+	attempts := 0
+	return utils.RetryWithExponentialBackoff(ctx, func() (*models.UploadTask, error) {
+		attempts++
+		if attempts > 1 && upload.task != nil {
+			task, err := getIsoUploadTask(ctx, ct, *upload.task.ID)
+			if err != nil {
+				return nil, err
+			}
+			if task == nil && index > 0 {
+				return nil, fmt.Errorf("failed to upload chunk %d of %s to cluster %s: %w, the upload starts over on the next apply",
+					index, upload.name, upload.clusterId, errIsoUploadTaskGone)
+			}
+			if task != nil && *task.CurrentChunk > index {
+				return task, nil
+			}
+			upload.task = task
+		}
+		cip := elf_image.NewCreateElfImageParams()
+		cip.File = runtime.NamedReader(upload.name, bytes.NewReader(chunk))
+		cip.ClusterID = upload.clusterId
+		cip.Name = upload.name
+		cip.Size = strconv.FormatInt(upload.size, 10)
+		cip.Description = &upload.description
+		if upload.task != nil {
+			cip.UploadTaskID = upload.task.ID
+		}
+		cip.Context = ctx
+		tasks, err := ct.Api.ElfImage.CreateElfImage(cip)
+		if err != nil {
+			return nil, err
+		}
+		return tasks.Payload[0], nil
+	}, opts)
+}
+
+// getIsoUploadTask returns the upload task of id, nil if it is not found
+func getIsoUploadTask(ctx context.Context, ct *cloudtower.Client, id string) (*models.UploadTask, error) {
+	gup := upload_task.NewGetUploadTasksParams()
+	gup.RequestBody = &models.GetUploadTasksRequestBody{
+		Where: &models.UploadTaskWhereInput{
+			ID: &id,
+		},
+	}
+	gup.Context = ctx
+	tasks, err := ct.Api.UploadTask.GetUploadTasks(gup)
+	if err != nil {
+		return nil, err
+	}
+	if len(tasks.Payload) < 1 {
+		return nil, nil
+	}
+	return tasks.Payload[0], nil
+}
+
+// waitIsoUploadTask waits for the cluster to take the uploaded file as an elf image
+func waitIsoUploadTask(ctx context.Context, ct *cloudtower.Client, upload *isoUpload) error {
+	for {
+		switch *upload.task.Status {
+		case models.UploadTaskStatusSUCCESSED:
+			return nil
+		case models.UploadTaskStatusFAILED:
+			return fmt.Errorf("failed to upload %s to cluster %s", upload.name, upload.clusterId)
+		}
+		if err := utils.Sleep(ctx, time.Second); err != nil {
+			return err
+		}
+		task, err := getIsoUploadTask(ctx, ct, *upload.task.ID)
+		if err != nil {
+			return err
+		}
+		if task == nil {
+			return fmt.Errorf("upload task of %s to cluster %s is not found", upload.name, upload.clusterId)
+		}
+		upload.task = task
+	}
+}
+
+// fileSha256 returns the hex encoded sha256 and the size of a file
+func fileSha256(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, f)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if size == 0 {
+		return "", 0, fmt.Errorf("%s is empty", path)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), size, nil
+}
+
+// sortedStringSet expands a set of strings in order, so clusters are uploaded to in the same order every time
+func sortedStringSet(set interface{}) []string {
+	values := expandStringSet(set)
+	sort.Strings(values)
+	return values
+}
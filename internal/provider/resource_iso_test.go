@@ -0,0 +1,275 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/fake"
+)
+
+// testAccIsoFile writes a file of size bytes to a temporary directory, returns its path and sha256
+func testAccIsoFile(t *testing.T, size int) (string, string) {
+	content := make([]byte, size)
+	for i := range content {
+		content[i] = byte(i * 7)
+	}
+	path := filepath.Join(t.TempDir(), "installer.iso")
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(content)
+	return path, hex.EncodeToString(sum[:])
+}
+
+func testAccIsoConfig(srv *fake.Server, name string, path string, clusterIds string, extra string) string {
+	return srv.ProviderConfig() + fmt.Sprintf(`
+resource "cloudtower_iso" "test" {
+  name        = %q
+  source      = %q
+  cluster_ids = %s
+  chunk_size  = 1024
+%s
+}
+`, name, path, clusterIds, extra)
+}
+
+// testAccCheckIsoUploaded checks the elf image of the iso in the cluster has the content of the file
+func testAccCheckIsoUploaded(srv *fake.Server, clusterId string, sum string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources["cloudtower_iso.test"]
+		if !ok {
+			return fmt.Errorf("cloudtower_iso.test not found in state")
+		}
+		imageId := rs.Primary.Attributes["image_ids."+clusterId]
+		image := srv.Get("elf-images", imageId)
+		if image == nil {
+			return fmt.Errorf("elf image %q of cluster %s not found", imageId, clusterId)
+		}
+		if image["sha256"] != sum {
+			return fmt.Errorf("elf image %s has sha256 %v, expected %s", imageId, image["sha256"], sum)
+		}
+		return nil
+	}
+}
+
+func testAccCheckIsoDestroy(srv *fake.Server) resource.TestCheckFunc {
+	imageIdKey := regexp.MustCompile(`^image_ids\.[^%]`)
+	return func(s *terraform.State) error {
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "cloudtower_iso" {
+				continue
+			}
+			for k, v := range rs.Primary.Attributes {
+				if imageIdKey.MatchString(k) && srv.Get("elf-images", v) != nil {
+					return fmt.Errorf("elf image %s of cloudtower_iso %s still exists", v, rs.Primary.ID)
+				}
+			}
+		}
+		return nil
+	}
+}
+
+func TestAccResourceIso(t *testing.T) {
+	srv := fake.NewServer(t)
+	other := srv.AddCluster("fake-cluster-2", "192.168.2.10")
+	path, sum := testAccIsoFile(t, 10*1024+100)
+	name := "cloudtower_iso.test"
+	// a dropped chunk is retried in the same apply
+	srv.FailUploadChunks(3, 1)
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckIsoDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccIsoConfig(srv, "installer.iso", path, fmt.Sprintf("[%q]", srv.Fixtures.ClusterId), fmt.Sprintf("sha256 = %q", strings.Repeat("0", 64))),
+				ExpectError: regexp.MustCompile("the sha256 of .* is [0-9a-f]+, expected"),
+			},
+			{
+				Config: testAccIsoConfig(srv, "installer.iso", path, fmt.Sprintf("[%q]", srv.Fixtures.ClusterId), fmt.Sprintf("sha256 = %q", sum)),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "size", "10340"),
+					resource.TestCheckResourceAttr(name, "image_ids.%", "1"),
+					testAccCheckIsoUploaded(srv, srv.Fixtures.ClusterId, sum),
+				),
+			},
+			{
+				Config: testAccIsoConfig(srv, "installer-v2.iso", path, fmt.Sprintf("[%q, %q]", srv.Fixtures.ClusterId, other.ClusterId), ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "sha256", sum),
+					resource.TestCheckResourceAttr(name, "image_ids.%", "2"),
+					testAccCheckIsoUploaded(srv, srv.Fixtures.ClusterId, sum),
+					testAccCheckIsoUploaded(srv, other.ClusterId, sum),
+					func(s *terraform.State) error {
+						for _, image := range srv.List("elf-images", map[string]any{"sha256": sum}) {
+							if image["name"] != "installer-v2.iso" {
+								return fmt.Errorf("elf image %s is named %v", image["id"], image["name"])
+							}
+						}
+						return nil
+					},
+				),
+			},
+			{
+				Config: testAccIsoConfig(srv, "installer-v2.iso", path, fmt.Sprintf("[%q]", other.ClusterId), ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "image_ids.%", "1"),
+					testAccCheckIsoUploaded(srv, other.ClusterId, sum),
+					func(s *terraform.State) error {
+						if images := srv.List("elf-images", map[string]any{"sha256": sum}); len(images) != 1 {
+							return fmt.Errorf("%d elf images of the iso left, expected 1", len(images))
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceIso_resume(t *testing.T) {
+	srv := fake.NewServer(t)
+	other := srv.AddCluster("fake-cluster-2", "192.168.2.10")
+	path, sum := testAccIsoFile(t, 8*1024)
+	both := testAccIsoConfig(srv, "installer.iso", path, fmt.Sprintf("[%q, %q]", srv.Fixtures.ClusterId, other.ClusterId), "")
+	// an unfinished upload of the same file started by someone else
+	foreignTaskId := srv.Insert("upload-tasks", map[string]any{
+		"status":        "UPLOADING",
+		"resource_type": "ELF_IMAGE",
+		"size":          8 * 1024,
+		"chunk_size":    1024,
+		"current_chunk": 2,
+		"args": map[string]any{
+			"name":       "installer.iso",
+			"cluster_id": other.ClusterId,
+			"size":       "8192",
+		},
+	})
+	clusterTasks := func(clusterId string) []map[string]any {
+		tasks := make([]map[string]any, 0)
+		for _, task := range srv.List("upload-tasks", nil) {
+			if task["id"] != foreignTaskId && task["args"].(map[string]any)["cluster_id"] == clusterId {
+				tasks = append(tasks, task)
+			}
+		}
+		return tasks
+	}
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckIsoDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccIsoConfig(srv, "installer.iso", path, fmt.Sprintf("[%q]", srv.Fixtures.ClusterId), ""),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckIsoUploaded(srv, srv.Fixtures.ClusterId, sum),
+					resource.TestCheckResourceAttr("cloudtower_iso.test", "upload_task_ids.%", "0"),
+				),
+			},
+			{
+				// the upload fails for good after 4 chunks, once the retries of the 5th are exhausted
+				PreConfig:   func() { srv.FailUploadChunks(4, 3) },
+				Config:      both,
+				ExpectError: regexp.MustCompile("failed after 3 retries"),
+			},
+			{
+				Config: both,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckIsoUploaded(srv, other.ClusterId, sum),
+					resource.TestCheckResourceAttr("cloudtower_iso.test", "upload_task_ids.%", "0"),
+					func(s *terraform.State) error {
+						tasks := clusterTasks(other.ClusterId)
+						if len(tasks) != 1 {
+							return fmt.Errorf("%d upload tasks started, expected the first one to be resumed", len(tasks))
+						}
+						if tasks[0]["current_chunk"] != float64(8) {
+							return fmt.Errorf("upload task received %v chunks, expected 8", tasks[0]["current_chunk"])
+						}
+						if foreign := srv.Get("upload-tasks", foreignTaskId); foreign["current_chunk"] != float64(2) {
+							return fmt.Errorf("upload task %s of someone else received %v chunks, expected 2", foreignTaskId, foreign["current_chunk"])
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceIso_lostTask(t *testing.T) {
+	srv := fake.NewServer(t)
+	path, sum := testAccIsoFile(t, 8*1024)
+	config := testAccIsoConfig(srv, "installer.iso", path, fmt.Sprintf("[%q]", srv.Fixtures.ClusterId), "")
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckIsoDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				// the following chunks can not be sent as the first one of a new upload task
+				PreConfig:   func() { srv.LoseUploadTask(2) },
+				Config:      config,
+				ExpectError: regexp.MustCompile("upload task is not found, the upload starts over"),
+			},
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckIsoUploaded(srv, srv.Fixtures.ClusterId, sum),
+					resource.TestCheckResourceAttr("cloudtower_iso.test", "upload_task_ids.%", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceIso_changedSource(t *testing.T) {
+	srv := fake.NewServer(t)
+	other := srv.AddCluster("fake-cluster-2", "192.168.2.10")
+	path, sum := testAccIsoFile(t, 4*1024)
+	both := testAccIsoConfig(srv, "installer.iso", path, fmt.Sprintf("[%q, %q]", srv.Fixtures.ClusterId, other.ClusterId), "")
+	var content []byte
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckIsoDestroy(srv),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccIsoConfig(srv, "installer.iso", path, fmt.Sprintf("[%q]", srv.Fixtures.ClusterId), ""),
+				Check:  testAccCheckIsoUploaded(srv, srv.Fixtures.ClusterId, sum),
+			},
+			{
+				// the file grown since the first upload is not uploaded to the cluster added
+				PreConfig: func() {
+					var err error
+					if content, err = os.ReadFile(path); err != nil {
+						t.Fatal(err)
+					}
+					if err = os.WriteFile(path, append(content, 1, 2, 3), 0o600); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config:      both,
+				ExpectError: regexp.MustCompile("the sha256 of .* is [0-9a-f]+, expected " + sum),
+			},
+			{
+				PreConfig: func() {
+					if images := srv.List("elf-images", map[string]any{"cluster": map[string]any{"id": other.ClusterId}}); len(images) != 0 {
+						t.Fatalf("%d elf images uploaded to cluster %s, expected none", len(images), other.ClusterId)
+					}
+					if err := os.WriteFile(path, content, 0o600); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: both,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudtower_iso.test", "size", "4096"),
+					testAccCheckIsoUploaded(srv, other.ClusterId, sum),
+				),
+			},
+		},
+	})
+}
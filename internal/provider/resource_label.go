@@ -0,0 +1,230 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/cloudtower"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/client/label"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/models"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceLabel() *schema.Resource {
+	return &schema.Resource{
+		Description: "CloudTower label resource, a key and value which VMs, hosts, clusters, volumes and templates are tagged with.",
+
+		CreateContext: resourceLabelCreate,
+		ReadContext:   resourceLabelRead,
+		UpdateContext: resourceLabelUpdate,
+		DeleteContext: resourceLabelDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importState(labelImportLookup),
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "label's id",
+			},
+			"key": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "label's key",
+			},
+			"value": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "label's value",
+			},
+			"vm_num": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "number of VMs tagged with the label",
+			},
+			"total_num": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "number of all the objects tagged with the label",
+			},
+		},
+	}
+}
+
+func resourceLabelCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	ct := meta.(*cloudtower.Client)
+	key := d.Get("key").(string)
+	value := d.Get("value").(string)
+	clp := label.NewCreateLabelParams()
+	clp.RequestBody = []*models.LabelCreationParams{{
+		Key:   &key,
+		Value: &value,
+	}}
+	clp.Context = ctx
+	labels, err := ct.Api.Label.CreateLabel(clp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(*labels.Payload[0].Data.ID)
+	_, err = ct.WaitTasksFinish(ctx, []string{*labels.Payload[0].TaskID})
+	if err != nil {
+		return diagFromTaskErr(err)
+	}
+
+	return resourceLabelRead(ctx, d, meta)
+}
+
+func resourceLabelRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	ct := meta.(*cloudtower.Client)
+
+	id := d.Id()
+	glp := label.NewGetLabelsParams()
+	glp.RequestBody = &models.GetLabelsRequestBody{
+		Where: &models.LabelWhereInput{
+			ID: &id,
+		},
+	}
+	glp.Context = ctx
+	labels, err := ct.Api.Label.GetLabels(glp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if len(labels.Payload) < 1 {
+		d.SetId("")
+		return diags
+	}
+	l := labels.Payload[0]
+	if err = d.Set("key", l.Key); err != nil {
+		return diag.FromErr(err)
+	}
+	value := ""
+	if l.Value != nil {
+		value = *l.Value
+	}
+	if err = d.Set("value", value); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("vm_num", l.VMNum); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("total_num", l.TotalNum); err != nil {
+		return diag.FromErr(err)
+	}
+	return diags
+}
+
+func resourceLabelUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	ct := meta.(*cloudtower.Client)
+	id := d.Id()
+	data := &models.LabelUpdationParamsData{}
+	if d.HasChange("key") {
+		key := d.Get("key").(string)
+		data.Key = &key
+	}
+	if d.HasChange("value") {
+		value := d.Get("value").(string)
+		data.Value = &value
+	}
+	ulp := label.NewUpdateLabelParams()
+	ulp.RequestBody = &models.LabelUpdationParams{
+		Where: &models.LabelWhereInput{
+			ID: &id,
+		},
+		Data: data,
+	}
+	ulp.Context = ctx
+	labels, err := ct.Api.Label.UpdateLabel(ulp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	taskIds := make([]string, 0)
+	for _, l := range labels.Payload {
+		if l.TaskID != nil {
+			taskIds = append(taskIds, *l.TaskID)
+		}
+	}
+	_, err = ct.WaitTasksFinish(ctx, taskIds)
+	if err != nil {
+		return diagFromTaskErr(err)
+	}
+
+	return resourceLabelRead(ctx, d, meta)
+}
+
+func resourceLabelDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	ct := meta.(*cloudtower.Client)
+	id := d.Id()
+	dlp := label.NewDeleteLabelParams()
+	dlp.RequestBody = &models.LabelDeletionParams{
+		Where: &models.LabelWhereInput{
+			ID: &id,
+		},
+	}
+	dlp.Context = ctx
+	labels, err := ct.Api.Label.DeleteLabel(dlp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	taskIds := make([]string, 0)
+	for _, l := range labels.Payload {
+		if l.TaskID != nil {
+			taskIds = append(taskIds, *l.TaskID)
+		}
+	}
+	_, err = ct.WaitTasksFinish(ctx, taskIds)
+	if err != nil {
+		return diagFromTaskErr(err)
+	}
+	d.SetId("")
+	return diags
+}
+
+func labelImportLookup(ctx context.Context, meta interface{}) *importLookup {
+	ct := meta.(*cloudtower.Client)
+	find := func(where *models.LabelWhereInput) ([]string, error) {
+		glp := label.NewGetLabelsParams()
+		glp.RequestBody = &models.GetLabelsRequestBody{
+			Where: where,
+		}
+		glp.Context = ctx
+		labels, err := ct.Api.Label.GetLabels(glp)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, 0, len(labels.Payload))
+		for _, l := range labels.Payload {
+			ids = append(ids, *l.ID)
+		}
+		return ids, nil
+	}
+	return &importLookup{
+		kind:   "label",
+		format: "key[/value]",
+		byId: func(id string) ([]string, error) {
+			return find(&models.LabelWhereInput{
+				ID: &id,
+			})
+		},
+		// unlike other name paths, the key comes first and the value is optional
+		byName: func(names []string) ([]string, error) {
+			where := &models.LabelWhereInput{
+				Key: &names[0],
+			}
+			if len(names) > 1 {
+				where.Value = &names[1]
+			}
+			return find(where)
+		},
+	}
+}
@@ -0,0 +1,46 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/fake"
+)
+
+func TestAccResourceLabel(t *testing.T) {
+	srv := fake.NewServer(t)
+	config := func(key string, value string) string {
+		return srv.ProviderConfig() + fmt.Sprintf(`
+resource "cloudtower_label" "test" {
+  key   = %q
+  value = %q
+}
+`, key, value)
+	}
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckDestroy(srv, "cloudtower_label", "labels"),
+		Steps: []resource.TestStep{
+			{
+				Config: config("team", "infra"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(srv, "cloudtower_label.test", "labels"),
+					resource.TestCheckResourceAttr("cloudtower_label.test", "key", "team"),
+					resource.TestCheckResourceAttr("cloudtower_label.test", "value", "infra"),
+					resource.TestCheckResourceAttr("cloudtower_label.test", "total_num", "0"),
+				),
+			},
+			{
+				Config: config("team", "platform"),
+				Check:  resource.TestCheckResourceAttr("cloudtower_label.test", "value", "platform"),
+			},
+			{
+				ResourceName:      "cloudtower_label.test",
+				ImportState:       true,
+				ImportStateId:     "team/platform",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
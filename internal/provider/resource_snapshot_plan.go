@@ -0,0 +1,536 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/cloudtower"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/client/snapshot_plan"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/models"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// snapshotPlanFrequencies maps the frequencies of a snapshot plan schedule to their exec types
+var snapshotPlanFrequencies = map[string]models.SnapshotPlanExecType{
+	"HOURLY": models.SnapshotPlanExecTypeHOUR,
+	"DAILY":  models.SnapshotPlanExecTypeDAY,
+	"WEEKLY": models.SnapshotPlanExecTypeWEEK,
+}
+
+// weekDays are the days of a weekly snapshot plan, in the order of their exec days from 1
+var weekDays = []string{"MONDAY", "TUESDAY", "WEDNESDAY", "THURSDAY", "FRIDAY", "SATURDAY", "SUNDAY"}
+
+// windowTimeRegexp matches the HH:MM times of a snapshot plan window
+var windowTimeRegexp = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]$`)
+
+func resourceSnapshotPlan() *schema.Resource {
+	return &schema.Resource{
+		Description: "CloudTower snapshot plan resource, it snapshots its VMs on a schedule and keeps the latest snapshots, the snapshots it took are found by the snapshot_plan_id of the cloudtower_vm_snapshot data source.",
+
+		CreateContext: resourceSnapshotPlanCreate,
+		ReadContext:   resourceSnapshotPlanRead,
+		UpdateContext: resourceSnapshotPlanUpdate,
+		DeleteContext: resourceSnapshotPlanDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importState(snapshotPlanImportLookup),
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+		CustomizeDiff: snapshotPlanCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "snapshot plan's id",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "snapshot plan's name",
+			},
+			"cluster_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "the id of the cluster the snapshot plan is created in, its VMs belong to the cluster",
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "snapshot plan's description",
+			},
+			"vm_ids": {
+				Type:        schema.TypeSet,
+				Required:    true,
+				MinItems:    1,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "the ids of the VMs snapshotted by the snapshot plan",
+			},
+			"schedule": {
+				Type:        schema.TypeList,
+				Required:    true,
+				MaxItems:    1,
+				Description: "when the snapshot plan snapshots its VMs",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"frequency": {
+							Type:         schema.TypeString,
+							Required:     true,
+							Description:  "how often the VMs are snapshotted, HOURLY, DAILY or WEEKLY",
+							ValidateFunc: validation.StringInSlice([]string{"HOURLY", "DAILY", "WEEKLY"}, false),
+						},
+						"interval": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      1,
+							Description:  "the VMs are snapshotted every interval hours, days or weeks",
+							ValidateFunc: validation.IntAtLeast(1),
+						},
+						"hour": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      0,
+							Description:  "the hour of the day the VMs are snapshotted at, unused by an HOURLY schedule",
+							ValidateFunc: validation.IntBetween(0, 23),
+						},
+						"minute": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      0,
+							Description:  "the minute of the hour the VMs are snapshotted at",
+							ValidateFunc: validation.IntBetween(0, 59),
+						},
+						"week_days": {
+							Type:        schema.TypeSet,
+							Optional:    true,
+							Description: "the days of the week the VMs are snapshotted on, from MONDAY to SUNDAY, required by a WEEKLY schedule only",
+							Elem: &schema.Schema{
+								Type:         schema.TypeString,
+								ValidateFunc: validation.StringInSlice(weekDays, false),
+							},
+						},
+						"window_start": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "the HH:MM time an HOURLY schedule starts snapshotting the VMs every day, along with window_end",
+							ValidateFunc: validation.StringMatch(windowTimeRegexp, "should be a time of the day as HH:MM"),
+						},
+						"window_end": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  "the HH:MM time an HOURLY schedule stops snapshotting the VMs every day, along with window_start",
+							ValidateFunc: validation.StringMatch(windowTimeRegexp, "should be a time of the day as HH:MM"),
+						},
+					},
+				},
+			},
+			"retain_count": {
+				Type:         schema.TypeInt,
+				Required:     true,
+				Description:  "how many of the latest snapshots of every VM are kept, older ones are deleted",
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"consistent_type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      string(models.ConsistentTypeCRASHCONSISTENT),
+				Description:  "the consistent type of the snapshots, CRASH_CONSISTENT or FILE_SYSTEM_CONSISTENT",
+				ValidateFunc: validation.StringInSlice([]string{string(models.ConsistentTypeCRASHCONSISTENT), string(models.ConsistentTypeFILESYSTEMCONSISTENT)}, false),
+			},
+			"suspended": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "whether the snapshot plan is paused, no snapshot is taken while it is",
+			},
+			"status": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "snapshot plan's status",
+			},
+		},
+	}
+}
+
+// snapshotPlanCustomizeDiff checks the schedule fields which only fit some frequencies
+func snapshotPlanCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("schedule") {
+		return nil
+	}
+	schedules := d.Get("schedule").([]interface{})
+	if len(schedules) == 0 || schedules[0] == nil {
+		return nil
+	}
+	schedule := schedules[0].(map[string]interface{})
+	frequency := schedule["frequency"].(string)
+	weekly := schedule["week_days"].(*schema.Set).Len() > 0
+	if frequency == "WEEKLY" && !weekly {
+		return fmt.Errorf("a WEEKLY schedule requires week_days")
+	}
+	if frequency != "WEEKLY" && weekly {
+		return fmt.Errorf("week_days can only be set for a WEEKLY schedule, got %s", frequency)
+	}
+	windowStart, windowEnd := schedule["window_start"].(string), schedule["window_end"].(string)
+	if (windowStart == "") != (windowEnd == "") {
+		return fmt.Errorf("window_start and window_end should be set together")
+	}
+	if windowStart != "" && frequency != "HOURLY" {
+		return fmt.Errorf("a window can only be set for an HOURLY schedule, got %s", frequency)
+	}
+	return nil
+}
+
+// SnapshotPlanConfig is the schedule, retention and VMs of a snapshot plan
+type SnapshotPlanConfig struct {
+	Name              string
+	Description       string
+	VmIds             []string
+	ExecType          models.SnapshotPlanExecType
+	Interval          int32
+	Hour              int32
+	Minute            int32
+	ExecDays          []int32
+	EnableWindow      bool
+	WindowStart       *string
+	WindowEnd         *string
+	RemainSnapshotNum int32
+	ConsistentType    models.ConsistentType
+}
+
+func expandSnapshotPlanConfig(d *schema.ResourceData) *SnapshotPlanConfig {
+	schedule := d.Get("schedule").([]interface{})[0].(map[string]interface{})
+	config := &SnapshotPlanConfig{
+		Name:              d.Get("name").(string),
+		Description:       d.Get("description").(string),
+		VmIds:             expandStringSet(d.Get("vm_ids")),
+		ExecType:          snapshotPlanFrequencies[schedule["frequency"].(string)],
+		Interval:          int32(schedule["interval"].(int)),
+		Hour:              int32(schedule["hour"].(int)),
+		Minute:            int32(schedule["minute"].(int)),
+		ExecDays:          make([]int32, 0),
+		RemainSnapshotNum: int32(d.Get("retain_count").(int)),
+		ConsistentType:    models.ConsistentType(d.Get("consistent_type").(string)),
+	}
+	for i, day := range weekDays {
+		if schedule["week_days"].(*schema.Set).Contains(day) {
+			config.ExecDays = append(config.ExecDays, int32(i+1))
+		}
+	}
+	if windowStart := schedule["window_start"].(string); windowStart != "" {
+		windowEnd := schedule["window_end"].(string)
+		config.EnableWindow = true
+		config.WindowStart = &windowStart
+		config.WindowEnd = &windowEnd
+	}
+	return config
+}
+
+func resourceSnapshotPlanCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	ct := meta.(*cloudtower.Client)
+	clusterId := d.Get("cluster_id").(string)
+	config := expandSnapshotPlanConfig(d)
+	startTime := time.Now().UTC().Format(time.RFC3339)
+	cpp := snapshot_plan.NewCreateSnapshotPlanParams()
+	cpp.RequestBody = []*models.SnapshotPlanCreationParams{{
+		Name:        &config.Name,
+		ClusterID:   &clusterId,
+		Description: &config.Description,
+		Vms: &models.VMWhereInput{
+			IDIn: config.VmIds,
+		},
+		ExecType: &config.ExecType,
+		Interval: &config.Interval,
+		ExecHM: &models.SnapshotPlanExecHMParams{
+			Hour:   &config.Hour,
+			Minute: &config.Minute,
+		},
+		ExecDays:          config.ExecDays,
+		EnableWindow:      &config.EnableWindow,
+		WindowStart:       config.WindowStart,
+		WindowEnd:         config.WindowEnd,
+		RemainSnapshotNum: &config.RemainSnapshotNum,
+		ConsistentType:    &config.ConsistentType,
+		StartTime:         &startTime,
+	}}
+	cpp.Context = ctx
+	plans, err := ct.Api.SnapshotPlan.CreateSnapshotPlan(cpp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(*plans.Payload[0].Data.ID)
+	_, err = ct.WaitTasksFinish(ctx, []string{*plans.Payload[0].TaskID})
+	if err != nil {
+		return diagFromTaskErr(err)
+	}
+	if d.Get("suspended").(bool) {
+		if diags := suspendSnapshotPlan(ctx, ct, d.Id(), true); diags != nil {
+			return diags
+		}
+	}
+
+	return resourceSnapshotPlanRead(ctx, d, meta)
+}
+
+func resourceSnapshotPlanRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	ct := meta.(*cloudtower.Client)
+
+	id := d.Id()
+	plans, err := getSnapshotPlans(ctx, ct, &models.SnapshotPlanWhereInput{
+		ID: &id,
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if len(plans) < 1 {
+		d.SetId("")
+		return diags
+	}
+	plan := plans[0]
+	if err = d.Set("name", plan.Name); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("cluster_id", plan.Cluster.ID); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("description", plan.Description); err != nil {
+		return diag.FromErr(err)
+	}
+	vmIds := make([]string, 0, len(plan.Vms))
+	for _, v := range plan.Vms {
+		vmIds = append(vmIds, *v.ID)
+	}
+	if err = d.Set("vm_ids", vmIds); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("schedule", flattenSnapshotPlanSchedule(plan)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("retain_count", plan.RemainSnapshotNum); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("consistent_type", plan.ConsistentType); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("suspended", plan.Status != nil && *plan.Status == models.SnapshotPlanStatusSUSPENDED); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("status", plan.Status); err != nil {
+		return diag.FromErr(err)
+	}
+	return diags
+}
+
+func flattenSnapshotPlanSchedule(plan *models.SnapshotPlan) []map[string]interface{} {
+	schedule := map[string]interface{}{
+		"interval":     plan.Interval,
+		"week_days":    []string{},
+		"window_start": "",
+		"window_end":   "",
+	}
+	for frequency, execType := range snapshotPlanFrequencies {
+		if plan.ExecType != nil && *plan.ExecType == execType {
+			schedule["frequency"] = frequency
+		}
+	}
+	if plan.ExecHM != nil {
+		schedule["hour"] = plan.ExecHM.Hour
+		schedule["minute"] = plan.ExecHM.Minute
+	}
+	days := make([]string, 0, len(plan.ExecDays))
+	for _, day := range plan.ExecDays {
+		if day >= 1 && int(day) <= len(weekDays) {
+			days = append(days, weekDays[day-1])
+		}
+	}
+	schedule["week_days"] = days
+	if plan.EnableWindow != nil && *plan.EnableWindow {
+		schedule["window_start"] = plan.WindowStart
+		schedule["window_end"] = plan.WindowEnd
+	}
+	return []map[string]interface{}{schedule}
+}
+
+func resourceSnapshotPlanUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	ct := meta.(*cloudtower.Client)
+	id := d.Id()
+	if d.HasChanges("name", "description", "vm_ids", "schedule", "retain_count", "consistent_type") {
+		config := expandSnapshotPlanConfig(d)
+		upp := snapshot_plan.NewUpdateSnapshotPlanParams()
+		upp.RequestBody = &models.SnapshotPlanUpdationParams{
+			Where: &models.SnapshotPlanWhereInput{
+				ID: &id,
+			},
+			Data: &models.SnapshotPlanUpdationParamsData{
+				Name:        &config.Name,
+				Description: &config.Description,
+				Vms: &models.VMWhereInput{
+					IDIn: config.VmIds,
+				},
+				ExecType: &config.ExecType,
+				Interval: &config.Interval,
+				ExecHM: &models.SnapshotPlanExecHMParams{
+					Hour:   &config.Hour,
+					Minute: &config.Minute,
+				},
+				ExecDays:          config.ExecDays,
+				EnableWindow:      &config.EnableWindow,
+				WindowStart:       config.WindowStart,
+				WindowEnd:         config.WindowEnd,
+				RemainSnapshotNum: &config.RemainSnapshotNum,
+				ConsistentType:    &config.ConsistentType,
+			},
+		}
+		upp.Context = ctx
+		plans, err := ct.Api.SnapshotPlan.UpdateSnapshotPlan(upp)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		taskIds := make([]string, 0)
+		for _, p := range plans.Payload {
+			if p.TaskID != nil {
+				taskIds = append(taskIds, *p.TaskID)
+			}
+		}
+		_, err = ct.WaitTasksFinish(ctx, taskIds)
+		if err != nil {
+			return diagFromTaskErr(err)
+		}
+	}
+	if d.HasChange("suspended") {
+		if diags := suspendSnapshotPlan(ctx, ct, id, d.Get("suspended").(bool)); diags != nil {
+			return diags
+		}
+	}
+
+	return resourceSnapshotPlanRead(ctx, d, meta)
+}
+
+// suspendSnapshotPlan pauses a snapshot plan, or resumes it if suspend is false
+func suspendSnapshotPlan(ctx context.Context, ct *cloudtower.Client, id string, suspend bool) diag.Diagnostics {
+	where := &models.SnapshotPlanWhereInput{
+		ID: &id,
+	}
+	var plans []*models.WithTaskSnapshotPlan
+	if suspend {
+		spp := snapshot_plan.NewSuspendSnapshotPlanParams()
+		spp.RequestBody = &models.SnapshotPlanSuspendParams{
+			Where: where,
+		}
+		spp.Context = ctx
+		suspended, err := ct.Api.SnapshotPlan.SuspendSnapshotPlan(spp)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		plans = suspended.Payload
+	} else {
+		rpp := snapshot_plan.NewResumeSnapshotPlanParams()
+		rpp.RequestBody = &models.SnapshotPlanResumeParams{
+			Where: where,
+		}
+		rpp.Context = ctx
+		resumed, err := ct.Api.SnapshotPlan.ResumeSnapshotPlan(rpp)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		plans = resumed.Payload
+	}
+	taskIds := make([]string, 0)
+	for _, p := range plans {
+		if p.TaskID != nil {
+			taskIds = append(taskIds, *p.TaskID)
+		}
+	}
+	_, err := ct.WaitTasksFinish(ctx, taskIds)
+	if err != nil {
+		return diagFromTaskErr(err)
+	}
+	return nil
+}
+
+func resourceSnapshotPlanDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	ct := meta.(*cloudtower.Client)
+	id := d.Id()
+	dpp := snapshot_plan.NewDeleteSnapshotPlanParams()
+	dpp.RequestBody = &models.SnapshotPlanDeletionParams{
+		Where: &models.SnapshotPlanWhereInput{
+			ID: &id,
+		},
+	}
+	dpp.Context = ctx
+	plans, err := ct.Api.SnapshotPlan.DeleteSnapshotPlan(dpp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	taskIds := make([]string, 0)
+	for _, p := range plans.Payload {
+		if p.TaskID != nil {
+			taskIds = append(taskIds, *p.TaskID)
+		}
+	}
+	_, err = ct.WaitTasksFinish(ctx, taskIds)
+	if err != nil {
+		return diagFromTaskErr(err)
+	}
+	d.SetId("")
+	return diags
+}
+
+func getSnapshotPlans(ctx context.Context, ct *cloudtower.Client, where *models.SnapshotPlanWhereInput) ([]*models.SnapshotPlan, error) {
+	gpp := snapshot_plan.NewGetSnapshotPlansParams()
+	gpp.RequestBody = &models.GetSnapshotPlansRequestBody{
+		Where: where,
+	}
+	gpp.Context = ctx
+	plans, err := ct.Api.SnapshotPlan.GetSnapshotPlans(gpp)
+	if err != nil {
+		return nil, err
+	}
+	return plans.Payload, nil
+}
+
+func snapshotPlanImportLookup(ctx context.Context, meta interface{}) *importLookup {
+	ct := meta.(*cloudtower.Client)
+	find := func(where *models.SnapshotPlanWhereInput) ([]string, error) {
+		plans, err := getSnapshotPlans(ctx, ct, where)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, 0, len(plans))
+		for _, p := range plans {
+			ids = append(ids, *p.ID)
+		}
+		return ids, nil
+	}
+	return &importLookup{
+		kind:   "snapshot plan",
+		format: "[cluster_name/]plan_name",
+		byId: func(id string) ([]string, error) {
+			return find(&models.SnapshotPlanWhereInput{
+				ID: &id,
+			})
+		},
+		byName: func(names []string) ([]string, error) {
+			where := &models.SnapshotPlanWhereInput{
+				Name: namesAt(names, 0),
+			}
+			if clusterName := namesAt(names, 1); clusterName != nil {
+				where.Cluster = &models.ClusterWhereInput{
+					Name: clusterName,
+				}
+			}
+			return find(where)
+		},
+	}
+}
@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/fake"
+)
+
+// testAccSnapshotPlanConfig is a snapshot plan of the VM of testAccVmConfig
+func testAccSnapshotPlanConfig(srv *fake.Server, schedule string, extra string) string {
+	return srv.ProviderConfig() + testAccVmConfig(srv, "tf-acc-vm") + fmt.Sprintf(`
+resource "cloudtower_snapshot_plan" "test" {
+  name         = "tf-acc-snapshot-plan"
+  cluster_id   = %q
+  vm_ids       = [cloudtower_vm.tf-acc-vm.id]
+  retain_count = 2
+%s
+  schedule {
+%s
+  }
+}
+`, srv.Fixtures.ClusterId, extra, schedule)
+}
+
+func TestAccResourceSnapshotPlan(t *testing.T) {
+	srv := fake.NewServer(t)
+	name := "cloudtower_snapshot_plan.test"
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckDestroy(srv, "cloudtower_snapshot_plan", "snapshot-plans"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSnapshotPlanConfig(srv, `
+    frequency = "WEEKLY"
+    hour      = 2
+    minute    = 30
+    week_days = ["SATURDAY", "SUNDAY"]
+`, ""),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(srv, name, "snapshot-plans"),
+					resource.TestCheckResourceAttr(name, "vm_ids.#", "1"),
+					resource.TestCheckResourceAttr(name, "schedule.0.frequency", "WEEKLY"),
+					resource.TestCheckResourceAttr(name, "schedule.0.hour", "2"),
+					resource.TestCheckResourceAttr(name, "schedule.0.minute", "30"),
+					resource.TestCheckTypeSetElemAttr(name, "schedule.0.week_days.*", "SUNDAY"),
+					resource.TestCheckResourceAttr(name, "consistent_type", "CRASH_CONSISTENT"),
+					resource.TestCheckResourceAttr(name, "status", "NORMAL"),
+				),
+			},
+			{
+				Config: testAccSnapshotPlanConfig(srv, `
+    frequency    = "HOURLY"
+    interval     = 4
+    window_start = "08:00"
+    window_end   = "20:00"
+`, `  suspended    = true`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "schedule.0.frequency", "HOURLY"),
+					resource.TestCheckResourceAttr(name, "schedule.0.interval", "4"),
+					resource.TestCheckResourceAttr(name, "schedule.0.week_days.#", "0"),
+					resource.TestCheckResourceAttr(name, "schedule.0.window_start", "08:00"),
+					resource.TestCheckResourceAttr(name, "suspended", "true"),
+					resource.TestCheckResourceAttr(name, "status", "SUSPENDED"),
+				),
+			},
+			{
+				ResourceName:      name,
+				ImportState:       true,
+				ImportStateId:     srv.Fixtures.ClusterName + "/tf-acc-snapshot-plan",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccResourceSnapshotPlan_invalidSchedule(t *testing.T) {
+	srv := fake.NewServer(t)
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccSnapshotPlanConfig(srv, `    frequency = "WEEKLY"`, ""),
+				ExpectError: regexp.MustCompile(`a WEEKLY schedule requires week_days`),
+			},
+			{
+				Config: testAccSnapshotPlanConfig(srv, `
+    frequency    = "DAILY"
+    window_start = "08:00"
+    window_end   = "20:00"
+`, ""),
+				ExpectError: regexp.MustCompile(`a window can only be set for an HOURLY schedule`),
+			},
+		},
+	})
+}
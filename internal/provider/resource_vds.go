@@ -0,0 +1,247 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/cloudtower"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/client/vds"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/models"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceVds() *schema.Resource {
+	return &schema.Resource{
+		Description: "CloudTower vds resource, a virtual distributed switch that cloudtower_vlan networks are created on. The MTU is a setting of the host nics in CloudTower, it is not set on the vds.",
+
+		CreateContext: resourceVdsCreate,
+		ReadContext:   resourceVdsRead,
+		UpdateContext: resourceVdsUpdate,
+		DeleteContext: resourceVdsDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importState(vdsImportLookup),
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "vds's id",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "vds's name",
+			},
+			"cluster_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "the id of the cluster the vds is created in",
+			},
+			"bond_mode": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Description:  "vds's bond mode of the host nics it uses",
+				ValidateFunc: validation.StringInSlice([]string{"ACTIVE_BACKUP", "BALANCE_SLB", "BALANCE_TCP"}, false),
+			},
+			"nic_ids": {
+				Type:     schema.TypeSet,
+				Required: true,
+				MinItems: 1,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Description: "the ids of the host nics the vds uses as its uplinks, the nics of a host are bonded by bond_mode",
+			},
+		},
+	}
+}
+
+func resourceVdsCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	ct := meta.(*cloudtower.Client)
+	name := d.Get("name").(string)
+	clusterId := d.Get("cluster_id").(string)
+	params := &models.VdsCreationParams{
+		Name:      &name,
+		ClusterID: &clusterId,
+	}
+	if bondMode, ok := d.GetOk("bond_mode"); ok {
+		bm := bondMode.(string)
+		params.BondMode = &bm
+	}
+	params.NicIds = expandStringSet(d.Get("nic_ids"))
+	cvp := vds.NewCreateVdsParams()
+	cvp.RequestBody = []*models.VdsCreationParams{params}
+	cvp.Context = ctx
+	vdses, err := ct.Api.Vds.CreateVds(cvp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(*vdses.Payload[0].Data.ID)
+	_, err = ct.WaitTasksFinish(ctx, []string{*vdses.Payload[0].TaskID})
+	if err != nil {
+		return diagFromTaskErr(err)
+	}
+
+	return resourceVdsRead(ctx, d, meta)
+}
+
+func resourceVdsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	ct := meta.(*cloudtower.Client)
+
+	id := d.Id()
+	gvp := vds.NewGetVdsesParams()
+	gvp.RequestBody = &models.GetVdsesRequestBody{
+		Where: &models.VdsWhereInput{
+			ID: &id,
+		},
+	}
+	gvp.Context = ctx
+	vdses, err := ct.Api.Vds.GetVdses(gvp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if len(vdses.Payload) < 1 {
+		d.SetId("")
+		return diags
+	}
+	v := vdses.Payload[0]
+	if err = d.Set("name", v.Name); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("cluster_id", v.Cluster.ID); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("bond_mode", v.BondMode); err != nil {
+		return diag.FromErr(err)
+	}
+	nicIds := make([]string, 0, len(v.Nics))
+	for _, nic := range v.Nics {
+		nicIds = append(nicIds, *nic.ID)
+	}
+	if err = d.Set("nic_ids", nicIds); err != nil {
+		return diag.FromErr(err)
+	}
+	return diags
+}
+
+func resourceVdsUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	ct := meta.(*cloudtower.Client)
+	id := d.Id()
+	data := &models.VdsUpdationParamsData{}
+	if d.HasChange("name") {
+		name := d.Get("name").(string)
+		data.Name = &name
+	}
+	if d.HasChange("bond_mode") {
+		bondMode := d.Get("bond_mode").(string)
+		data.BondMode = &bondMode
+	}
+	if d.HasChange("nic_ids") {
+		data.NicIds = expandStringSet(d.Get("nic_ids"))
+	}
+	uvp := vds.NewUpdateVdsParams()
+	uvp.RequestBody = &models.VdsUpdationParams{
+		Where: &models.VdsWhereInput{
+			ID: &id,
+		},
+		Data: data,
+	}
+	uvp.Context = ctx
+	vdses, err := ct.Api.Vds.UpdateVds(uvp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	taskIds := make([]string, 0)
+	for _, v := range vdses.Payload {
+		if v.TaskID != nil {
+			taskIds = append(taskIds, *v.TaskID)
+		}
+	}
+	_, err = ct.WaitTasksFinish(ctx, taskIds)
+	if err != nil {
+		return diagFromTaskErr(err)
+	}
+
+	return resourceVdsRead(ctx, d, meta)
+}
+
+func resourceVdsDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	ct := meta.(*cloudtower.Client)
+	id := d.Id()
+	dvp := vds.NewDeleteVdsParams()
+	dvp.RequestBody = &models.VdsDeletionParams{
+		Where: &models.VdsWhereInput{
+			ID: &id,
+		},
+	}
+	dvp.Context = ctx
+	vdses, err := ct.Api.Vds.DeleteVds(dvp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	taskIds := make([]string, 0)
+	for _, v := range vdses.Payload {
+		if v.TaskID != nil {
+			taskIds = append(taskIds, *v.TaskID)
+		}
+	}
+	_, err = ct.WaitTasksFinish(ctx, taskIds)
+	if err != nil {
+		return diagFromTaskErr(err)
+	}
+	d.SetId("")
+	return diags
+}
+
+func vdsImportLookup(ctx context.Context, meta interface{}) *importLookup {
+	ct := meta.(*cloudtower.Client)
+	find := func(where *models.VdsWhereInput) ([]string, error) {
+		gvp := vds.NewGetVdsesParams()
+		gvp.RequestBody = &models.GetVdsesRequestBody{
+			Where: where,
+		}
+		gvp.Context = ctx
+		vdses, err := ct.Api.Vds.GetVdses(gvp)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, 0, len(vdses.Payload))
+		for _, v := range vdses.Payload {
+			ids = append(ids, *v.ID)
+		}
+		return ids, nil
+	}
+	return &importLookup{
+		kind:   "vds",
+		format: "[cluster_name/]vds_name",
+		byId: func(id string) ([]string, error) {
+			return find(&models.VdsWhereInput{
+				ID: &id,
+			})
+		},
+		byName: func(names []string) ([]string, error) {
+			where := &models.VdsWhereInput{
+				Name: namesAt(names, 0),
+			}
+			if clusterName := namesAt(names, 1); clusterName != nil {
+				where.Cluster = &models.ClusterWhereInput{
+					Name: clusterName,
+				}
+			}
+			return find(where)
+		},
+	}
+}
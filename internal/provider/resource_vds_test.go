@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/fake"
+)
+
+func TestAccResourceVds(t *testing.T) {
+	srv := fake.NewServer(t)
+	// the other nic of the host is used by the vds of the cluster
+	usedNicId := srv.List("nics", map[string]any{"host": map[string]any{"id": srv.Fixtures.HostId}, "id_not": srv.Fixtures.NicId})[0]["id"].(string)
+	config := func(name string, bondMode string, nicId string) string {
+		return srv.ProviderConfig() + fmt.Sprintf(`
+resource "cloudtower_vds" "test" {
+  name       = %q
+  cluster_id = %q
+  bond_mode  = %q
+  nic_ids    = [%q]
+}
+`, name, srv.Fixtures.ClusterId, bondMode, nicId)
+	}
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckDestroy(srv, "cloudtower_vds", "vdses"),
+		Steps: []resource.TestStep{
+			{
+				Config:      config("tf-acc-vds", "ACTIVE_BACKUP", usedNicId),
+				ExpectError: regexp.MustCompile("is used by vds"),
+			},
+			{
+				Config: config("tf-acc-vds", "ACTIVE_BACKUP", srv.Fixtures.NicId),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(srv, "cloudtower_vds.test", "vdses"),
+					resource.TestCheckResourceAttr("cloudtower_vds.test", "name", "tf-acc-vds"),
+					resource.TestCheckResourceAttr("cloudtower_vds.test", "cluster_id", srv.Fixtures.ClusterId),
+					resource.TestCheckResourceAttr("cloudtower_vds.test", "bond_mode", "ACTIVE_BACKUP"),
+					resource.TestCheckResourceAttr("cloudtower_vds.test", "nic_ids.#", "1"),
+					resource.TestCheckTypeSetElemAttr("cloudtower_vds.test", "nic_ids.*", srv.Fixtures.NicId),
+				),
+			},
+			{
+				Config: config("tf-acc-vds-renamed", "BALANCE_TCP", srv.Fixtures.NicId),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudtower_vds.test", "name", "tf-acc-vds-renamed"),
+					resource.TestCheckResourceAttr("cloudtower_vds.test", "bond_mode", "BALANCE_TCP"),
+				),
+			},
+			{
+				ResourceName:      "cloudtower_vds.test",
+				ImportState:       true,
+				ImportStateId:     srv.Fixtures.ClusterName + "/tf-acc-vds-renamed",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
@@ -0,0 +1,299 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/cloudtower"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/client/vlan"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/models"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceVlan() *schema.Resource {
+	return &schema.Resource{
+		Description: "CloudTower vlan resource, a VM network on a vds that VM nics connect to.",
+
+		CreateContext: resourceVlanCreate,
+		ReadContext:   resourceVlanRead,
+		UpdateContext: resourceVlanUpdate,
+		DeleteContext: resourceVlanDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importState(vlanImportLookup),
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "vlan's id",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "vlan's name",
+			},
+			"vds_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "the id of the vds the vlan is created on",
+			},
+			"vlan_id": {
+				Type:         schema.TypeInt,
+				Required:     true,
+				Description:  "vlan's vlan id, 0 means the vlan is untagged",
+				ValidateFunc: validation.IntBetween(0, 4094),
+			},
+			"type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "vlan's type, vlans created by the resource are always VM networks",
+			},
+			"cluster_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "the id of the cluster the vds of the vlan belongs to",
+			},
+			"qos_min_bandwidth": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				Description:  "vlan's minimum bandwidth guaranteed for each VM nic, in the unit of bps, 0 means no guarantee",
+				ValidateFunc: validation.FloatAtLeast(0),
+			},
+			"qos_max_bandwidth": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				Description:  "vlan's maximum bandwidth of each VM nic, in the unit of bps, 0 means no limit",
+				ValidateFunc: validation.FloatAtLeast(0),
+			},
+			"qos_burst": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				Description:  "vlan's burst size above qos_max_bandwidth of each VM nic, in the unit of byte",
+				ValidateFunc: validation.FloatAtLeast(0),
+			},
+		},
+	}
+}
+
+func resourceVlanCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	ct := meta.(*cloudtower.Client)
+	name := d.Get("name").(string)
+	vdsId := d.Get("vds_id").(string)
+	vlanId := int32(d.Get("vlan_id").(int))
+	params := &models.VMVlanCreationParams{
+		Name:   &name,
+		VdsID:  &vdsId,
+		VlanID: &vlanId,
+	}
+	if v, ok := d.GetOk("qos_min_bandwidth"); ok {
+		minBandwidth := v.(float64)
+		params.QosMinBandwidth = &minBandwidth
+	}
+	if v, ok := d.GetOk("qos_max_bandwidth"); ok {
+		maxBandwidth := v.(float64)
+		params.QosMaxBandwidth = &maxBandwidth
+	}
+	if v, ok := d.GetOk("qos_burst"); ok {
+		burst := v.(float64)
+		params.QosBurst = &burst
+	}
+	cvp := vlan.NewCreateVMVlanParams()
+	cvp.RequestBody = []*models.VMVlanCreationParams{params}
+	cvp.Context = ctx
+	vlans, err := ct.Api.Vlan.CreateVMVlan(cvp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(*vlans.Payload[0].Data.ID)
+	_, err = ct.WaitTasksFinish(ctx, []string{*vlans.Payload[0].TaskID})
+	if err != nil {
+		return diagFromTaskErr(err)
+	}
+
+	return resourceVlanRead(ctx, d, meta)
+}
+
+func resourceVlanRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	ct := meta.(*cloudtower.Client)
+
+	id := d.Id()
+	gvp := vlan.NewGetVlansParams()
+	gvp.RequestBody = &models.GetVlansRequestBody{
+		Where: &models.VlanWhereInput{
+			ID: &id,
+		},
+	}
+	gvp.Context = ctx
+	vlans, err := ct.Api.Vlan.GetVlans(gvp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if len(vlans.Payload) < 1 {
+		d.SetId("")
+		return diags
+	}
+	v := vlans.Payload[0]
+	if err = d.Set("name", v.Name); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("vds_id", v.Vds.ID); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("vlan_id", v.VlanID); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("type", v.Type); err != nil {
+		return diag.FromErr(err)
+	}
+	if v.Vds.Cluster != nil {
+		if err = d.Set("cluster_id", v.Vds.Cluster.ID); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	if err = d.Set("qos_min_bandwidth", v.QosMinBandwidth); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("qos_max_bandwidth", v.QosMaxBandwidth); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("qos_burst", v.QosBurst); err != nil {
+		return diag.FromErr(err)
+	}
+	return diags
+}
+
+func resourceVlanUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	ct := meta.(*cloudtower.Client)
+	id := d.Id()
+	data := &models.VMVlanUpdationParamsData{}
+	if d.HasChange("name") {
+		name := d.Get("name").(string)
+		data.Name = &name
+	}
+	if d.HasChange("vlan_id") {
+		vlanId := int32(d.Get("vlan_id").(int))
+		data.VlanID = &vlanId
+	}
+	// a removed qos limit is sent as 0, which lifts the limit
+	if d.HasChange("qos_min_bandwidth") {
+		minBandwidth := d.Get("qos_min_bandwidth").(float64)
+		data.QosMinBandwidth = &minBandwidth
+	}
+	if d.HasChange("qos_max_bandwidth") {
+		maxBandwidth := d.Get("qos_max_bandwidth").(float64)
+		data.QosMaxBandwidth = &maxBandwidth
+	}
+	if d.HasChange("qos_burst") {
+		burst := d.Get("qos_burst").(float64)
+		data.QosBurst = &burst
+	}
+	uvp := vlan.NewUpdateVMVlanParams()
+	uvp.RequestBody = &models.VMVlanUpdationParams{
+		Where: &models.VlanWhereInput{
+			ID: &id,
+		},
+		Data: data,
+	}
+	uvp.Context = ctx
+	vlans, err := ct.Api.Vlan.UpdateVMVlan(uvp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	taskIds := make([]string, 0)
+	for _, v := range vlans.Payload {
+		if v.TaskID != nil {
+			taskIds = append(taskIds, *v.TaskID)
+		}
+	}
+	_, err = ct.WaitTasksFinish(ctx, taskIds)
+	if err != nil {
+		return diagFromTaskErr(err)
+	}
+
+	return resourceVlanRead(ctx, d, meta)
+}
+
+func resourceVlanDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	ct := meta.(*cloudtower.Client)
+	id := d.Id()
+	dvp := vlan.NewDeleteVlanParams()
+	dvp.RequestBody = &models.VlanDeletionParams{
+		Where: &models.VlanWhereInput{
+			ID: &id,
+		},
+	}
+	dvp.Context = ctx
+	vlans, err := ct.Api.Vlan.DeleteVlan(dvp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	taskIds := make([]string, 0)
+	for _, v := range vlans.Payload {
+		if v.TaskID != nil {
+			taskIds = append(taskIds, *v.TaskID)
+		}
+	}
+	_, err = ct.WaitTasksFinish(ctx, taskIds)
+	if err != nil {
+		return diagFromTaskErr(err)
+	}
+	d.SetId("")
+	return diags
+}
+
+func vlanImportLookup(ctx context.Context, meta interface{}) *importLookup {
+	ct := meta.(*cloudtower.Client)
+	find := func(where *models.VlanWhereInput) ([]string, error) {
+		gvp := vlan.NewGetVlansParams()
+		gvp.RequestBody = &models.GetVlansRequestBody{
+			Where: where,
+		}
+		gvp.Context = ctx
+		vlans, err := ct.Api.Vlan.GetVlans(gvp)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, 0, len(vlans.Payload))
+		for _, v := range vlans.Payload {
+			ids = append(ids, *v.ID)
+		}
+		return ids, nil
+	}
+	return &importLookup{
+		kind:   "vlan",
+		format: "[[cluster_name/]vds_name/]vlan_name",
+		byId: func(id string) ([]string, error) {
+			return find(&models.VlanWhereInput{
+				ID: &id,
+			})
+		},
+		byName: func(names []string) ([]string, error) {
+			where := &models.VlanWhereInput{
+				Name: namesAt(names, 0),
+			}
+			if vdsName := namesAt(names, 1); vdsName != nil {
+				where.Vds = &models.VdsWhereInput{
+					Name: vdsName,
+				}
+				if clusterName := namesAt(names, 2); clusterName != nil {
+					where.Vds.Cluster = &models.ClusterWhereInput{
+						Name: clusterName,
+					}
+				}
+			}
+			return find(where)
+		},
+	}
+}
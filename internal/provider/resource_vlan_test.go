@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/fake"
+)
+
+func TestAccResourceVlan(t *testing.T) {
+	srv := fake.NewServer(t)
+	config := func(name string, vlanId int, qos string) string {
+		return srv.ProviderConfig() + fmt.Sprintf(`
+resource "cloudtower_vlan" "test" {
+  name    = %q
+  vds_id  = %q
+  vlan_id = %d
+  %s
+}
+`, name, srv.Fixtures.VdsId, vlanId, qos)
+	}
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckDestroy(srv, "cloudtower_vlan", "vlans"),
+		Steps: []resource.TestStep{
+			{
+				Config: config("tf-acc-vlan", 100, ""),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(srv, "cloudtower_vlan.test", "vlans"),
+					resource.TestCheckResourceAttr("cloudtower_vlan.test", "vlan_id", "100"),
+					resource.TestCheckResourceAttr("cloudtower_vlan.test", "type", "VM"),
+					resource.TestCheckResourceAttr("cloudtower_vlan.test", "cluster_id", srv.Fixtures.ClusterId),
+				),
+			},
+			{
+				Config: config("tf-acc-vlan-renamed", 200, "qos_max_bandwidth = 1000000000\n  qos_burst = 1048576"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudtower_vlan.test", "name", "tf-acc-vlan-renamed"),
+					resource.TestCheckResourceAttr("cloudtower_vlan.test", "vlan_id", "200"),
+					resource.TestCheckResourceAttr("cloudtower_vlan.test", "qos_max_bandwidth", "1000000000"),
+					resource.TestCheckResourceAttr("cloudtower_vlan.test", "qos_burst", "1048576"),
+				),
+			},
+			{
+				Config: config("tf-acc-vlan-renamed", 200, ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudtower_vlan.test", "qos_max_bandwidth", "0"),
+					resource.TestCheckResourceAttr("cloudtower_vlan.test", "qos_burst", "0"),
+				),
+			},
+			{
+				Config:      config("tf-acc-vlan-renamed", 4095, ""),
+				ExpectError: regexp.MustCompile("expected vlan_id to be in the range"),
+			},
+			{
+				ResourceName:      "cloudtower_vlan.test",
+				ImportState:       true,
+				ImportStateId:     srv.Fixtures.ClusterName + "/" + srv.Fixtures.ClusterName + "-vds/tf-acc-vlan-renamed",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccResourceVlan_onVds(t *testing.T) {
+	srv := fake.NewServer(t)
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckDestroy(srv, "cloudtower_vds", "vdses"),
+		Steps: []resource.TestStep{
+			{
+				Config: srv.ProviderConfig() + fmt.Sprintf(`
+resource "cloudtower_vds" "test" {
+  name       = "tf-acc-vds"
+  cluster_id = %q
+  nic_ids    = [%q]
+}
+
+resource "cloudtower_vlan" "test" {
+  name    = "tf-acc-vlan"
+  vds_id  = cloudtower_vds.test.id
+  vlan_id = 100
+}
+`, srv.Fixtures.ClusterId, srv.Fixtures.NicId),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(srv, "cloudtower_vlan.test", "vlans"),
+					resource.TestCheckResourceAttrPair("cloudtower_vlan.test", "vds_id", "cloudtower_vds.test", "id"),
+					resource.TestCheckResourceAttr("cloudtower_vds.test", "bond_mode", "ACTIVE_BACKUP"),
+				),
+			},
+		},
+	})
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/hashicorp/terraform-provider-cloudtower/internal/cloudtower"
@@ -36,6 +37,19 @@ func resourceVm() *schema.Resource {
 		ReadContext:   resourceVmRead,
 		UpdateContext: resourceVmUpdate,
 		DeleteContext: resourceVmDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importState(vmImportLookup),
+		},
+		CustomizeDiff: customdiff.All(
+			vmCpuCustomizeDiff,
+			vmDiskCustomizeDiff,
+			labelsCustomizeDiff,
+		),
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
+			Update: schema.DefaultTimeout(30 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
 
 		Schema: map[string]*schema.Schema{
 			"name": {
@@ -47,13 +61,13 @@ func resourceVm() *schema.Resource {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Computed:    true,
-				Description: "VM's cluster id",
+				Description: "VM's cluster id, changing it migrates the VM to that cluster, along with the host_id, the disks' storage_policy and the nics' vlan_id changed with it",
 			},
 			"vcpu": {
 				Type:        schema.TypeInt,
 				Optional:    true,
 				Computed:    true,
-				Description: "VM's vcpu",
+				Description: "VM's vcpu, equal to cpu_cores * cpu_sockets, the ones not configured are derived from the others at plan time, a vm created from a source keeps its sockets or cores when vcpu is divisible by them",
 			},
 			"memory": {
 				Type:        schema.TypeFloat,
@@ -115,7 +129,7 @@ func resourceVm() *schema.Resource {
 				Type:        schema.TypeList,
 				Optional:    true,
 				Computed:    true,
-				Description: "VM's virtual disks",
+				Description: "VM's virtual disks, the ones attached by cloudtower_vm_disk_attachment are left out",
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"boot": {
@@ -146,7 +160,7 @@ func resourceVm() *schema.Resource {
 									"storage_policy": {
 										Type:        schema.TypeString,
 										Required:    true,
-										Description: "the new VM volume's storage policy",
+										Description: "the new VM volume's storage policy, changing it migrates a mounted volume to the new storage policy in place",
 										ValidateFunc: validation.StringInSlice(
 											[]string{
 												"REPLICA_2_THIN_PROVISION",
@@ -164,7 +178,7 @@ func resourceVm() *schema.Resource {
 									"size": {
 										Type:        schema.TypeFloat,
 										Required:    true,
-										Description: "the new VM volume's size, in the unit of byte",
+										Description: "the new VM volume's size, in the unit of byte, a mounted volume can grow in place but can not shrink",
 									},
 									"path": {
 										Type:        schema.TypeString,
@@ -221,7 +235,7 @@ func resourceVm() *schema.Resource {
 				Type:        schema.TypeList,
 				Optional:    true,
 				Computed:    true,
-				Description: "VM's virtual nic",
+				Description: "VM's virtual nic, nics are matched by their position, so changing one only updates that nic and the others keep their mac address and order",
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"vlan_id": {
@@ -395,7 +409,7 @@ func resourceVm() *schema.Resource {
 							Optional:      true,
 							ForceNew:      true,
 							ConflictsWith: []string{"create_effect.0.clone_from_template", "create_effect.0.clone_from_vm", "create_effect.0.clone_from_vm"},
-							Description:   "Id of snapshot for created vm to be rebuilt from",
+							Description:   "Id of snapshot for created vm to be rebuilt from, either a cloudtower_vm_snapshot or a member of a cloudtower_consistency_group_snapshot from its vm_snapshot_ids",
 						},
 						"cloud_init": {
 							Type:        schema.TypeList,
@@ -538,16 +552,49 @@ func resourceVm() *schema.Resource {
 				Description: "Vm is going to rollback to target snapshot",
 				Optional:    true,
 			},
+			"wait_for_guest": {
+				Type:        schema.TypeList,
+				MaxItems:    1,
+				Optional:    true,
+				Description: "Wait for the guest OS of a running VM to be ready after create, VMTools running is always waited for",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"ip_address": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "wait until VMTools reports an IP address of the guest",
+						},
+						"hostname": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+							Description: "wait until VMTools reports the hostname set by hostname or cloud-init, which means cloud-init has finished",
+						},
+						"timeout": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "how long to wait for the guest, e.g. 5m, defaults to the create timeout, creating the VM fails when its guest is not ready in time",
+							ValidateDiagFunc: func(v interface{}, _ cty.Path) diag.Diagnostics {
+								if _, err := time.ParseDuration(v.(string)); err != nil {
+									return diag.Errorf("timeout should be a duration, e.g. 5m: %s", err)
+								}
+								return nil
+							},
+						},
+					},
+				},
+			},
 			// computed
 			"host_id": {
 				Type:        schema.TypeString,
-				Description: "VM's host id",
+				Description: "VM's host id, AUTO_SCHEDULE lets CloudTower choose the host, a VM in any enabled cloudtower_vm_placement_group is moved by CloudTower to a host satisfying its placement groups when its host breaks them, host_id stays AUTO_SCHEDULE otherwise",
 				Optional:    true,
 				Computed:    true,
 			},
 			"folder_id": {
 				Type:        schema.TypeString,
-				Description: "VM's folder id",
+				Description: "VM's folder id, changing it moves the VM to another folder of its cluster in place, an empty string takes it out of its folder",
 				Optional:    true,
 				Computed:    true,
 			},
@@ -557,6 +604,8 @@ func resourceVm() *schema.Resource {
 				Optional:    true,
 				Computed:    true,
 			},
+			"labels":     labelsSchema("VM"),
+			"labels_all": labelsAllSchema("VM"),
 			"guest_os_type": {
 				Type:         schema.TypeString,
 				Description:  "VM's guest OS type",
@@ -639,6 +688,7 @@ type CpuStruct struct {
 }
 type VmNicStruct struct {
 	Create []VmNicCreate `json:"create,omitempty"`
+	Update []VmNicUpdate `json:"update,omitempty"`
 	Delete []VmNicDelete `json:"delete,omitempty"`
 }
 type VmNicCreate struct {
@@ -657,6 +707,27 @@ type VmNicCreate struct {
 type VmNicDelete struct {
 	Id string `json:"id"`
 }
+
+type VmNicUpdate struct {
+	Where VmNicWhereInput `json:"where"`
+	Data  VmNicUpdateData `json:"data"`
+}
+
+type VmNicWhereInput struct {
+	Id string `json:"id"`
+}
+
+type VmNicUpdateData struct {
+	Enabled    *bool             `json:"enabled,omitempty"`
+	Mirror     *bool             `json:"mirror,omitempty"`
+	Gateway    string            `json:"gateway,omitempty"`
+	IPAddress  string            `json:"ip_address,omitempty"`
+	MacAddress string            `json:"mac_address,omitempty"`
+	SubnetMask string            `json:"subnet_mask,omitempty"`
+	Model      models.VMNicModel `json:"model,omitempty"`
+	Vlan       *ConnectStruct    `json:"vlan,omitempty"`
+	IpType     string            `json:"ip_type,omitempty"`
+}
 type VmDiskStruct struct {
 	Create []VmDiskCreate `json:"create,omitempty"`
 	Delete []VmDiskDelete `json:"delete,omitempty"`
@@ -698,6 +769,11 @@ type VmVolumeCreateConnectStruct struct {
 	Connect    *ConnectConnect      `json:"connect,omitempty"`
 	Disconnect *bool                `json:"disconnect,omitempty"`
 	Create     *VmVolumeCreateInput `json:"create,omitempty"`
+	Update     *VmVolumeUpdateInput `json:"update,omitempty"`
+}
+
+type VmVolumeUpdateInput struct {
+	Size int64 `json:"size,omitempty"`
 }
 
 type VmVolumeCreateInput struct {
@@ -733,6 +809,174 @@ var updateVm struct {
 	} `graphql:"updateVm(data: $data, effect: $effect, where:$where)"`
 }
 
+type VmCpuTopology struct {
+	Vcpu    int32
+	Cores   int32
+	Sockets int32
+}
+
+// resolveVmCpuTopology completes the configured vcpu, cores and sockets, the nil ones are derived from
+// the others and the current topology, which is nil on creation. It returns nil when it can not be resolved.
+func resolveVmCpuTopology(vcpu *int32, cores *int32, sockets *int32, current *VmCpuTopology) (*VmCpuTopology, error) {
+	switch {
+	case vcpu != nil && cores != nil && sockets != nil:
+		if *vcpu != *cores**sockets {
+			return nil, fmt.Errorf("vcpu %d does not match %d cpu cores in %d cpu sockets", *vcpu, *cores, *sockets)
+		}
+		return &VmCpuTopology{Vcpu: *vcpu, Cores: *cores, Sockets: *sockets}, nil
+	case vcpu != nil && sockets != nil:
+		if *vcpu%*sockets != 0 {
+			return nil, fmt.Errorf("vcpu %d must be divisible by number of cpu sockets %d", *vcpu, *sockets)
+		}
+		return &VmCpuTopology{Vcpu: *vcpu, Cores: *vcpu / *sockets, Sockets: *sockets}, nil
+	case vcpu != nil && cores != nil:
+		if *vcpu%*cores != 0 {
+			return nil, fmt.Errorf("vcpu %d must be divisible by number of cpu cores %d", *vcpu, *cores)
+		}
+		return &VmCpuTopology{Vcpu: *vcpu, Cores: *cores, Sockets: *vcpu / *cores}, nil
+	case vcpu != nil:
+		// keep the current sockets, or the current cores, when vcpu is divisible by them
+		switch {
+		case current == nil:
+			return &VmCpuTopology{Vcpu: *vcpu, Cores: 1, Sockets: *vcpu}, nil
+		case *vcpu%current.Sockets == 0:
+			return &VmCpuTopology{Vcpu: *vcpu, Cores: *vcpu / current.Sockets, Sockets: current.Sockets}, nil
+		case *vcpu%current.Cores == 0:
+			return &VmCpuTopology{Vcpu: *vcpu, Cores: current.Cores, Sockets: *vcpu / current.Cores}, nil
+		default:
+			return &VmCpuTopology{Vcpu: *vcpu, Cores: *vcpu, Sockets: 1}, nil
+		}
+	case current == nil:
+		// without vcpu, the topology of a new VM comes from its source
+		return nil, nil
+	case cores != nil && sockets != nil:
+		return &VmCpuTopology{Vcpu: *cores * *sockets, Cores: *cores, Sockets: *sockets}, nil
+	case sockets != nil:
+		return &VmCpuTopology{Vcpu: current.Cores * *sockets, Cores: current.Cores, Sockets: *sockets}, nil
+	case cores != nil:
+		return &VmCpuTopology{Vcpu: *cores * current.Sockets, Cores: *cores, Sockets: current.Sockets}, nil
+	default:
+		return current, nil
+	}
+}
+
+// vmCpuCustomizeDiff resolves the cpu topology from the configured vcpu, cpu_cores and cpu_sockets,
+// so conflicts are reported by plan and the derived values are known
+func vmCpuCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	raw := d.GetRawConfig()
+	if raw.IsNull() || !raw.IsKnown() {
+		return nil
+	}
+	keys := []string{"vcpu", "cpu_cores", "cpu_sockets"}
+	configured := make(map[string]*int32)
+	for _, key := range keys {
+		v := raw.GetAttr(key)
+		if !v.IsKnown() {
+			// resolve it once the configured value is known
+			return nil
+		}
+		if v.IsNull() {
+			continue
+		}
+		value := int32(d.Get(key).(int))
+		configured[key] = &value
+	}
+	var current *VmCpuTopology
+	if d.Id() != "" {
+		vcpu, _ := d.GetChange("vcpu")
+		cores, _ := d.GetChange("cpu_cores")
+		sockets, _ := d.GetChange("cpu_sockets")
+		current = &VmCpuTopology{
+			Vcpu:    int32(vcpu.(int)),
+			Cores:   int32(cores.(int)),
+			Sockets: int32(sockets.(int)),
+		}
+		if current.Cores == 0 || current.Sockets == 0 {
+			current = nil
+		}
+	}
+	if current == nil && configured["vcpu"] != nil && configured["cpu_cores"] == nil && configured["cpu_sockets"] == nil && vmCreatedFromSource(d) {
+		// the topology of the source is kept when vcpu is divisible by it, it is known once the vm is created
+		if err := d.SetNewComputed("cpu_cores"); err != nil {
+			return err
+		}
+		return d.SetNewComputed("cpu_sockets")
+	}
+	topology, err := resolveVmCpuTopology(configured["vcpu"], configured["cpu_cores"], configured["cpu_sockets"], current)
+	if err != nil || topology == nil {
+		return err
+	}
+	resolved := map[string]int32{
+		"vcpu":        topology.Vcpu,
+		"cpu_cores":   topology.Cores,
+		"cpu_sockets": topology.Sockets,
+	}
+	for _, key := range keys {
+		if configured[key] != nil || d.Get(key).(int) == int(resolved[key]) && d.NewValueKnown(key) {
+			continue
+		}
+		if err := d.SetNew(key, int(resolved[key])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// vmCreatedFromSource tells whether the vm is cloned from a vm or template, or rebuilt from a snapshot
+func vmCreatedFromSource(d *schema.ResourceDiff) bool {
+	for _, key := range []string{"clone_from_vm", "clone_from_template", "clone_from_content_library_template", "rebuild_from_snapshot"} {
+		if v, ok := d.GetOk("create_effect.0." + key); ok && v.(string) != "" || !d.NewValueKnown("create_effect.0."+key) {
+			return true
+		}
+	}
+	return false
+}
+
+// vmDiskCustomizeDiff rejects changes of mounted volumes which can not be applied in place,
+// a volume can only grow, and keeps its name
+func vmDiskCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" || !d.HasChange("disk") {
+		return nil
+	}
+	volumeOf := func(item interface{}) (string, map[string]interface{}) {
+		disk, ok := item.(map[string]interface{})
+		if !ok {
+			return "", nil
+		}
+		volumes, _ := disk["vm_volume"].([]interface{})
+		if len(volumes) == 0 {
+			return "", nil
+		}
+		volume, _ := volumes[0].(map[string]interface{})
+		id, _ := disk["vm_volume_id"].(string)
+		return id, volume
+	}
+	o, n := d.GetChange("disk")
+	origins := make(map[string]map[string]interface{})
+	for _, item := range o.([]interface{}) {
+		if id, volume := volumeOf(item); id != "" && volume != nil {
+			origins[id] = volume
+		}
+	}
+	for i, item := range n.([]interface{}) {
+		id, volume := volumeOf(item)
+		origin := origins[id]
+		if origin == nil || volume == nil {
+			continue
+		}
+		// values known only after apply read as zero values, they are checked once known
+		key := fmt.Sprintf("disk.%d.vm_volume.0", i)
+		name := origin["name"].(string)
+		if d.NewValueKnown(key+".name") && volume["name"].(string) != name {
+			return fmt.Errorf("mounted disk %s's name can not be changed", name)
+		}
+		if d.NewValueKnown(key+".size") && volume["size"].(float64) < origin["size"].(float64) {
+			return fmt.Errorf("disk %s's size can not shrink from %.0f to %.0f bytes", name, origin["size"].(float64), volume["size"].(float64))
+		}
+	}
+	return nil
+}
+
 func resourceVmCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	ct := meta.(*cloudtower.Client)
 	var vms []*models.WithTaskVM
@@ -752,7 +996,16 @@ func resourceVmCreate(ctx context.Context, d *schema.ResourceData, meta interfac
 	}
 	if count >= 2 {
 		return diag.Errorf("can only set one create effect")
-	} else if rebuildFrom != "" {
+	}
+	// VMs created without cluster_id land on the cluster of their source, which is not capped
+	if clusterId := d.Get("cluster_id").(string); clusterId != "" {
+		release, err := ct.AcquireClusterSlot(ctx, clusterId)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		defer release()
+	}
+	if rebuildFrom != "" {
 		vms, diags = rebuildVmFromSnapshot(rebuildFrom, ctx, d, ct)
 	} else if cloneFrom != "" {
 		vms, diags = cloneVmFromSourceVm(cloneFrom, ctx, d, ct)
@@ -767,6 +1020,12 @@ func resourceVmCreate(ctx context.Context, d *schema.ResourceData, meta interfac
 		return diags
 	}
 	d.SetId(*vms[0].Data.ID)
+	if err := updateLabels(ctx, d, ct, labelTarget{vm: &models.VMWhereInput{ID: vms[0].Data.ID}}); err != nil {
+		return diagFromTaskErr(err)
+	}
+	if err := waitVmGuestReady(ctx, d, ct); err != nil {
+		return diagFromTaskErr(err)
+	}
 	return resourceVmRead(ctx, d, meta)
 }
 
@@ -801,17 +1060,39 @@ func resourceVmRead(ctx context.Context, d *schema.ResourceData, meta interface{
 	if err := d.Set("status", v.Status); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := d.Set("host_id", v.Host.ID); err != nil {
-		return diag.FromErr(err)
+	// a stopped VM may not be placed on any host
+	hostId := ""
+	if v.Host != nil {
+		hostId = *v.Host.ID
 	}
-	if v.Folder != nil {
-		if err := d.Set("folder_id", v.Folder.ID); err != nil {
+	// an AUTO_SCHEDULE VM is left where it is while its host satisfies its placement groups,
+	// otherwise its host is read so the next apply schedules it again
+	if d.Get("host_id").(string) == "AUTO_SCHEDULE" {
+		breaks, err := vmBreaksPlacementGroups(ctx, ct, v)
+		if err != nil {
 			return diag.FromErr(err)
 		}
+		if !breaks {
+			hostId = "AUTO_SCHEDULE"
+		}
+	}
+	if err := d.Set("host_id", hostId); err != nil {
+		return diag.FromErr(err)
+	}
+	// a VM out of any folder has an empty folder_id, so folder moves made outside are detected
+	folderId := ""
+	if v.Folder != nil {
+		folderId = *v.Folder.ID
+	}
+	if err := d.Set("folder_id", folderId); err != nil {
+		return diag.FromErr(err)
 	}
 	if err := d.Set("description", v.Description); err != nil {
 		return diag.FromErr(err)
 	}
+	if err := setLabels(ctx, d, ct, v.Labels); err != nil {
+		return diag.FromErr(err)
+	}
 	if err := d.Set("guest_os_type", v.GuestOsType); err != nil {
 		return diag.FromErr(err)
 	}
@@ -861,9 +1142,17 @@ func resourceVmRead(ctx context.Context, d *schema.ResourceData, meta interface{
 	if diags != nil {
 		return diags
 	}
+	ownedIds, ownedNames := vmOwnedDiskVolumes(d)
 	var disks []map[string]interface{}
 	for idx, disk := range vmDisks {
+		if disk.VMVolume == nil {
+			continue
+		}
 		vmVolume := vmVolumes[idx]
+		// the disks attached by cloudtower_vm_disk_attachment are left to it
+		if ownedIds != nil && !ownedIds[*disk.VMVolume.ID] && (vmVolume == nil || !ownedNames[*vmVolume.Name]) {
+			continue
+		}
 		vmVolumeData := map[string]interface{}{
 			"id": disk.VMVolume.ID,
 		}
@@ -871,8 +1160,8 @@ func resourceVmRead(ctx context.Context, d *schema.ResourceData, meta interface{
 			vmVolumeData["name"] = vmVolume.Name
 			vmVolumeData["size"] = vmVolume.Size
 			vmVolumeData["path"] = vmVolume.Path
-			vmVolumeData["origin_path"] = d.Get(fmt.Sprintf("disk.%d.vm_volume.0.origin_path", idx))
-			vmVolumeData["storage_policy"] = vmVolume.ElfStoragePolicy
+			vmVolumeData["origin_path"] = d.Get(fmt.Sprintf("disk.%d.vm_volume.0.origin_path", len(disks)))
+			vmVolumeData["storage_policy"] = helper.GetVmVolumeElfStoragePolicy(vmVolume)
 		}
 		disks = append(disks, map[string]interface{}{
 			"id":   disk.ID,
@@ -910,6 +1199,39 @@ func resourceVmRead(ctx context.Context, d *schema.ResourceData, meta interface{
 	return diags
 }
 
+// vmOwnedDiskVolumes returns the ids and names of the volumes of the disks of the vm, the other
+// disks were attached to it by cloudtower_vm_disk_attachment. Both are nil when every disk is the
+// vm's, as it is created or imported
+func vmOwnedDiskVolumes(d *schema.ResourceData) (map[string]bool, map[string]bool) {
+	disks := d.Get("disk").([]interface{})
+	if d.IsNewResource() || len(disks) == 0 {
+		return nil, nil
+	}
+	ids := make(map[string]bool)
+	names := make(map[string]bool)
+	for _, item := range disks {
+		disk, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if id, _ := disk["vm_volume_id"].(string); id != "" {
+			ids[id] = true
+		}
+		volumes, _ := disk["vm_volume"].([]interface{})
+		for _, v := range volumes {
+			if volume, ok := v.(map[string]interface{}); ok {
+				if id, _ := volume["id"].(string); id != "" {
+					ids[id] = true
+				}
+				if name, _ := volume["name"].(string); name != "" {
+					names[name] = true
+				}
+			}
+		}
+	}
+	return ids, names
+}
+
 func resourceVmUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	ct := meta.(*cloudtower.Client)
 	id := d.Id()
@@ -942,81 +1264,12 @@ func resourceVmUpdate(ctx context.Context, d *schema.ResourceData, meta interfac
 		if basic.Description != nil {
 			updateParams.Description = *basic.Description
 		}
-		if basic.CpuCores == nil || basic.CpuSockets == nil || basic.Vcpu == nil {
-			if basic.CpuCores == nil {
-				if basic.CpuSockets == nil {
-					// do nothing if nothing of cpu config has changed
-					if basic.Vcpu != nil {
-						updateParams.VCpu = *basic.Vcpu
-						if *basic.Vcpu%*originalVm.CPU.Sockets == 0 {
-							newCore := *basic.Vcpu / *originalVm.CPU.Sockets
-							updateParams.Cpu = &CpuStruct{
-								Cores:   newCore,
-								Sockets: *originalVm.CPU.Sockets,
-							}
-						} else if *basic.Vcpu%*originalVm.CPU.Cores == 0 {
-							newSockets := *basic.Vcpu / *originalVm.CPU.Cores
-							updateParams.Cpu = &CpuStruct{
-								Cores:   *originalVm.CPU.Cores,
-								Sockets: newSockets,
-							}
-						} else {
-							updateParams.Cpu = &CpuStruct{
-								Cores:   *basic.Vcpu,
-								Sockets: 1,
-							}
-						}
-					}
-				} else {
-					if basic.Vcpu != nil {
-						if *basic.Vcpu%*basic.CpuSockets == 0 {
-							newCore := *basic.Vcpu / *basic.CpuSockets
-							updateParams.Cpu = &CpuStruct{
-								Cores:   newCore,
-								Sockets: *basic.CpuSockets,
-							}
-						} else {
-							return diag.Errorf("vcpu must be divisible by number of cpu sockets")
-						}
-					} else {
-						updateParams.VCpu = *basic.CpuSockets * *originalVm.CPU.Cores
-						updateParams.Cpu = &CpuStruct{
-							Cores:   *originalVm.CPU.Cores,
-							Sockets: *basic.CpuSockets,
-						}
-					}
-				}
-			} else if basic.CpuSockets == nil {
-				if basic.Vcpu == nil {
-					updateParams.VCpu = (*originalVm.CPU.Sockets) * (*basic.CpuCores)
-					updateParams.Cpu = &CpuStruct{
-						Cores:   *basic.CpuCores,
-						Sockets: *originalVm.CPU.Sockets,
-					}
-				} else {
-					updateParams.VCpu = *basic.Vcpu
-					if *basic.Vcpu%*basic.CpuCores == 0 {
-						newSocket := *basic.Vcpu / *basic.CpuCores
-						updateParams.Cpu = &CpuStruct{
-							Cores:   *basic.CpuCores,
-							Sockets: newSocket,
-						}
-					} else {
-						return diag.Errorf("vcpu must be divisible by number of cpu cores")
-					}
-				}
-			} else {
-				updateParams.VCpu = (*basic.CpuSockets) * (*basic.CpuCores)
-				updateParams.Cpu = &CpuStruct{
-					Cores:   *basic.CpuCores,
-					Sockets: *basic.CpuSockets,
-				}
-			}
-		} else {
-			updateParams.VCpu = *basic.Vcpu
+		// the topology is resolved and validated at plan time by vmCpuCustomizeDiff
+		if d.HasChanges("vcpu", "cpu_cores", "cpu_sockets") {
+			updateParams.VCpu = int32(d.Get("vcpu").(int))
 			updateParams.Cpu = &CpuStruct{
-				Cores:   *basic.CpuCores,
-				Sockets: *basic.CpuSockets,
+				Cores:   int32(d.Get("cpu_cores").(int)),
+				Sockets: int32(d.Get("cpu_sockets").(int)),
 			}
 		}
 	}
@@ -1044,344 +1297,27 @@ func resourceVmUpdate(ctx context.Context, d *schema.ResourceData, meta interfac
 	}
 
 	if d.HasChange("nic") {
-		// delete all previous vm nic
-		nicsToDelete := make([]VmNicDelete, 0)
-		nicsToCreate := make([]VmNicCreate, 0)
-		vmNics, diags := readVmNics(ctx, d, ct)
+		vmNicStruct, needConfigureIp, diags := expandVmNicUpdates(ctx, d, ct)
 		if diags != nil {
 			return diags
 		}
-		for _, n := range vmNics {
-			nicsToDelete = append(nicsToDelete, VmNicDelete{
-				Id: *n.ID,
-			})
-		}
-		var nics []*VmNic
-		bytes, err := json.Marshal(d.Get("nic"))
-		if err != nil {
-			return diag.FromErr(err)
-		}
-		err = json.Unmarshal(bytes, &nics)
-		if err != nil {
-			return diag.FromErr(err)
-		}
-		for _, n := range nics {
-			var nicId string = ""
-			if n.NicID != nil {
-				nicId = *n.ConnectVlanID
-			}
-			var vlanId string = ""
-			if n.VlanId != "" {
-				vlanId = n.VlanId
-			}
-			nicToCreate := VmNicCreate{
-				Enabled:    *n.Enabled,
-				Gateway:    *n.Gateway,
-				IPAddress:  *n.IPAddress,
-				LocalId:    "",
-				MacAddress: *n.MacAddress,
-				Model:      *n.Model,
-				SubnetMask: *n.SubnetMask,
-				Nic: &ConnectStruct{
-					Connect: &ConnectConnect{
-						Id: nicId,
-					},
-				},
-				Vlan: &ConnectStruct{
-					Connect: &ConnectConnect{
-						Id: vlanId,
-					},
-				},
-			}
-			if !needUpdateVmToolsAttribute && (n.IPAddress != nil || n.Gateway != nil || n.SubnetMask != nil) {
-				// to configure vm ip, need boot up vm
-				needUpdateVmToolsAttribute = true
-			}
-			if n.IPAddress != nil {
-				nicToCreate.IpType = "STATIC"
-			}
-			nicsToCreate = append(nicsToCreate, nicToCreate)
+		if needConfigureIp {
+			// to configure vm ip, need boot up vm
+			needUpdateVmToolsAttribute = true
 		}
 		if needUpdateVmToolsAttribute {
-			updateVmToolsAttributeParams.VmNics = &VmNicStruct{
-				Create: nicsToCreate,
-				Delete: nicsToDelete,
-			}
+			updateVmToolsAttributeParams.VmNics = vmNicStruct
 		} else {
-			updateParams.VmNics = &VmNicStruct{
-				Create: nicsToCreate,
-				Delete: nicsToDelete,
-			}
+			updateParams.VmNics = vmNicStruct
 		}
-
 	}
 
 	if d.HasChanges("cd_rom", "disk") {
-		diskToCreate := make([]VmDiskCreate, 0)
-		diskToUpdate := make([]VmDiskUpdate, 0)
-		diskToDelete := make([]VmDiskDelete, 0)
-		cdRoms, diags := readCdRoms(ctx, d, ct)
-		if diags != nil {
-			return diags
-		}
-		vmDisks, vmVolumes, diags := readVmDisks(ctx, d, ct)
+		vmDiskStruct, diags := expandVmDiskUpdates(ctx, d, ct)
 		if diags != nil {
 			return diags
 		}
-		curVolumeMap := make(map[string]*models.VMVolume, 0)
-		for _, v := range vmVolumes {
-			curVolumeMap[*v.ID] = v
-		}
-		if d.HasChange("cd_rom") {
-			curMap := make(map[string]*models.VMDisk, 0)
-			for _, v := range cdRoms {
-				curMap[*v.ID] = v
-			}
-			var cdRomsData []*CdRom
-			bytes, err := json.Marshal(d.Get("cd_rom"))
-			if err != nil {
-				return diag.FromErr(err)
-			}
-			err = json.Unmarshal(bytes, &cdRomsData)
-			if err != nil {
-				return diag.FromErr(err)
-			}
-			// push all current cd_rom to update, ignore deleted cd-rom, tower will handle it
-			for _, cr := range cdRomsData {
-				// for existing cd_rom, update it
-				origin := curMap[cr.Id]
-				if origin != nil {
-					var data = VmDiskUpdateData{}
-					data.Boot = int(cr.Boot)
-					data.Bus = *origin.Bus
-					data.Key = *origin.Key
-					data.Disabled = *origin.Disabled
-					data.Type = models.VMDiskTypeCDROM
-					if origin.ElfImage != nil && cr.IsoId == "" {
-						flag := true
-						data.ElfImage = &ConnectStruct{
-							Disconnect: &flag,
-						}
-					} else if !(cr.IsoId == "" && origin.ElfImage == nil) &&
-						!(origin.ElfImage != nil && cr.IsoId == *origin.ElfImage.ID) {
-						data.ElfImage = &ConnectStruct{
-							Connect: &ConnectConnect{
-								Id: cr.IsoId,
-							},
-						}
-					}
-					diskToUpdate = append(diskToUpdate, VmDiskUpdate{
-						Where: VmDiskWhereInput{Id: cr.Id},
-						Data:  data,
-					})
-				} else {
-					var data = VmDiskCreate{
-						Boot: int(cr.Boot),
-						Bus:  models.BusIDE,
-						Type: models.VMDiskTypeCDROM,
-					}
-					if cr.IsoId != "" {
-						data.ElfImage = &ConnectStruct{
-							Connect: &ConnectConnect{
-								Id: cr.IsoId,
-							},
-						}
-					}
-					diskToCreate = append(diskToCreate, data)
-				}
-			}
-		} else {
-			// keep original cd_rom
-			for _, v := range cdRoms {
-				diskToUpdate = append(diskToUpdate, VmDiskUpdate{
-					Where: VmDiskWhereInput{Id: *v.ID},
-					Data: VmDiskUpdateData{
-						Boot:     int(*v.Boot),
-						Bus:      *v.Bus,
-						Key:      *v.Key,
-						Type:     models.VMDiskTypeCDROM,
-						Disabled: *v.Disabled,
-					},
-				})
-			}
-		}
-		if d.HasChange("disk") {
-			curMap := make(map[string]*models.VMDisk, 0)
-			for _, v := range vmDisks {
-				// use volume id as key
-				curMap[*v.VMVolume.ID] = v
-			}
-			var disks []*VmDisk
-			bytes, err := json.Marshal(d.Get("disk"))
-			if err != nil {
-				return diag.FromErr(err)
-			}
-			err = json.Unmarshal(bytes, &disks)
-			if err != nil {
-				return diag.FromErr(err)
-			}
-			for _, vd := range disks {
-				origin := curMap[vd.VmVolumeId]
-				originVolume := curVolumeMap[vd.VmVolumeId]
-				if vd.VmVolumeId != "" {
-					if origin != nil {
-						// if given volume is mounted, update it
-						var data = VmDiskUpdateData{
-							Boot: vd.Boot,
-							Bus:  vd.Bus,
-							Type: *origin.Type,
-						}
-						if len(vd.VmVolume) > 0 {
-							newVolume := vd.VmVolume[0]
-							if newVolume.Name != *originVolume.Name {
-								//FIXME: move all invalid change detection to customizeDiffFunc
-								return diag.Errorf("mounted disk %s's name can not be changed", *originVolume.Name)
-							}
-							if newVolume.Size < *originVolume.Size {
-								return diag.Errorf("Disk %s's size can not shrink", *originVolume.Name)
-							}
-							if newVolume.StoragePolicy != *originVolume.ElfStoragePolicy {
-								return diag.Errorf("mounted disk %s's storage policy can not be changed", *originVolume.Name)
-							}
-							data.VmVolume = &VmVolumeCreateConnectStruct{
-								Create: &VmVolumeCreateInput{
-									Name:             *originVolume.Name,
-									Path:             newVolume.Path,
-									Size:             newVolume.Size,
-									ElfStoragePolicy: *originVolume.ElfStoragePolicy,
-									LocalCreatedAt:   "",
-									LocalId:          "",
-									Mounting:         *originVolume.Mounting,
-									Sharing:          *originVolume.Sharing,
-									Cluster: &ConnectStruct{
-										Connect: &ConnectConnect{
-											Id: "",
-										},
-									},
-								},
-							}
-						} else if originVolume != nil {
-							// use original volume
-							data.VmVolume = &VmVolumeCreateConnectStruct{
-								Create: &VmVolumeCreateInput{
-									Name:             *originVolume.Name,
-									Path:             *originVolume.Path,
-									Size:             *originVolume.Size,
-									ElfStoragePolicy: *originVolume.ElfStoragePolicy,
-									LocalCreatedAt:   "",
-									LocalId:          "",
-									Mounting:         *originVolume.Mounting,
-									Sharing:          *originVolume.Sharing,
-									Cluster: &ConnectStruct{
-										Connect: &ConnectConnect{
-											Id: "",
-										},
-									},
-								},
-							}
-						}
-						diskToUpdate = append(diskToUpdate, VmDiskUpdate{
-							Where: VmDiskWhereInput{
-								Id: vd.Id,
-							},
-							Data: data,
-						})
-						delete(curMap, vd.VmVolumeId)
-					} else {
-						// if giving volume is not mounted, mount it
-						var data = VmDiskCreate{
-							Boot: vd.Boot,
-							Bus:  vd.Bus,
-							Type: models.VMDiskTypeDISK,
-							VmVolume: &VmVolumeCreateConnectStruct{
-								Connect: &ConnectConnect{
-									Id: vd.VmVolumeId,
-								},
-							},
-						}
-						diskToCreate = append(diskToCreate, data)
-					}
-				} else if len(vd.VmVolume) > 0 {
-					// no volume is given, but VmVolume is configured mean we need to create one
-					var data = VmDiskCreate{
-						Boot: vd.Boot,
-						Bus:  vd.Bus,
-						Type: models.VMDiskTypeDISK,
-						VmVolume: &VmVolumeCreateConnectStruct{
-							Create: &VmVolumeCreateInput{
-								Name:             vd.VmVolume[0].Name,
-								Path:             "",
-								Size:             vd.VmVolume[0].Size,
-								ElfStoragePolicy: vd.VmVolume[0].StoragePolicy,
-								LocalCreatedAt:   "",
-								LocalId:          "",
-								Mounting:         true,
-								Sharing:          false,
-								Cluster: &ConnectStruct{
-									Connect: &ConnectConnect{
-										Id: "",
-									},
-								},
-							},
-						},
-					}
-					diskToCreate = append(diskToCreate, data)
-				}
-				for _, d := range curMap {
-					diskToDelete = append(diskToDelete, VmDiskDelete{
-						Id: *d.ID,
-					})
-				}
-			}
-		} else {
-			// keep original disks
-			for _, v := range vmDisks {
-				var originVmVolume = curVolumeMap[*v.VMVolume.ID]
-				if originVmVolume != nil {
-					var data = VmDiskUpdateData{
-						Boot: int(*v.Boot),
-						Bus:  *v.Bus,
-						Type: models.VMDiskTypeDISK,
-						VmVolume: &VmVolumeCreateConnectStruct{
-							Create: &VmVolumeCreateInput{
-								Name:             *originVmVolume.Name,
-								Path:             *originVmVolume.Path,
-								Size:             *originVmVolume.Size,
-								Sharing:          *originVmVolume.Sharing,
-								ElfStoragePolicy: *originVmVolume.ElfStoragePolicy,
-								LocalCreatedAt:   "",
-								LocalId:          "",
-								Mounting:         *originVmVolume.Mounting,
-								Cluster: &ConnectStruct{
-									Connect: &ConnectConnect{
-										Id: "",
-									},
-								},
-							},
-						},
-					}
-					diskToUpdate = append(diskToUpdate, VmDiskUpdate{
-						Where: VmDiskWhereInput{
-							Id: *v.ID,
-						},
-						Data: data,
-					})
-				}
-			}
-		}
-		sort.SliceStable(diskToCreate, func(i, j int) bool {
-			return diskToCreate[i].Boot < diskToCreate[j].Boot
-		})
-
-		sort.SliceStable(diskToUpdate, func(i, j int) bool {
-			return diskToUpdate[i].Data.Boot < diskToUpdate[j].Data.Boot
-		})
-
-		updateParams.VmDisks = &VmDiskStruct{
-			Create: diskToCreate,
-			Update: diskToUpdate,
-			Delete: diskToDelete,
-		}
+		updateParams.VmDisks = vmDiskStruct
 	}
 
 	var statusChangeFunc func() error
@@ -1420,7 +1356,7 @@ func resourceVmUpdate(ctx context.Context, d *schema.ResourceData, meta interfac
 					uvp.Context = ctx
 					vms, err := utils.RetryWithExponentialBackoff(ctx, func() (*vm.StartVMOK, error) {
 						return ct.Api.VM.StartVM(uvp)
-					}, utils.RetryWithExponentialBackoffOptions{})
+					}, ct.RetryOptions)
 					if err != nil {
 						return err
 					}
@@ -1442,7 +1378,7 @@ func resourceVmUpdate(ctx context.Context, d *schema.ResourceData, meta interfac
 					uvp.Context = ctx
 					vms, err := utils.RetryWithExponentialBackoff(ctx, func() (*vm.ResumeVMOK, error) {
 						return ct.Api.VM.ResumeVM(uvp)
-					}, utils.RetryWithExponentialBackoffOptions{})
+					}, ct.RetryOptions)
 					if err != nil {
 						return err
 					}
@@ -1473,7 +1409,7 @@ func resourceVmUpdate(ctx context.Context, d *schema.ResourceData, meta interfac
 						uvp.Context = ctx
 						vms, err := utils.RetryWithExponentialBackoff(ctx, func() (*vm.PoweroffVMOK, error) {
 							return ct.Api.VM.PoweroffVM(uvp)
-						}, utils.RetryWithExponentialBackoffOptions{})
+						}, ct.RetryOptions)
 						if err != nil {
 							return err
 						}
@@ -1569,15 +1505,81 @@ func resourceVmUpdate(ctx context.Context, d *schema.ResourceData, meta interfac
 		}
 	}
 
+	// migrate the vm to another cluster, the target host is chosen with it
+	migratedAcrossCluster := false
 	if d.HasChange("cluster_id") {
-		return diag.Errorf("cross cluster migration is not supported yet")
+		migration, err := expandVmClusterMigrationConfig(d)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		vlanMappings := make([]*models.VMMigrateAcrossClusterVlanMapping, 0)
+		for src, dest := range migration.VlanMapping {
+			src, dest := src, dest
+			vlanMappings = append(vlanMappings, &models.VMMigrateAcrossClusterVlanMapping{
+				SrcVlanID:  &src,
+				DestVlanID: &dest,
+			})
+		}
+		mp := vm.NewMigrateVMAcrossClusterParams()
+		mp.RequestBody = &models.VMMigrateAcrossClusterParams{
+			Where: &models.VMWhereInput{
+				ID: &id,
+			},
+			Data: &models.VMMigrateAcrossClusterParamsData{
+				ElfClusterID:     &migration.ClusterId,
+				HostID:           migration.HostId,
+				ElfStoragePolicy: migration.StoragePolicy,
+				VlanMappings:     vlanMappings,
+			},
+		}
+		mp.Context = ctx
+		vms, err := ct.Api.VM.MigrateVMAcrossCluster(mp)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		err = waitVmTasksFinish(ctx, ct, vms.Payload)
+		if err != nil {
+			return diagFromTaskErr(err)
+		}
+		migratedAcrossCluster = true
+
+		// the migration recreates the nics in the target cluster and applies the vlan changes,
+		// so the nic and disk updates built from the vm before are rebuilt from what it has now
+		if d.HasChange("nic") {
+			vmNicStruct, _, diags := expandVmNicUpdates(ctx, d, ct)
+			if diags != nil {
+				return diags
+			}
+			if updateVmToolsAttributeParams.VmNics != nil {
+				updateVmToolsAttributeParams.VmNics = vmNicStruct
+			} else {
+				updateParams.VmNics = vmNicStruct
+			}
+		}
+		if d.HasChanges("cd_rom", "disk") {
+			vmDiskStruct, diags := expandVmDiskUpdates(ctx, d, ct)
+			if diags != nil {
+				return diags
+			}
+			updateParams.VmDisks = vmDiskStruct
+		}
+	}
+
+	// the storage policy of volumes is changed by the cross cluster migration,
+	// otherwise migrate the volumes whose storage policy changed in place
+	if d.HasChange("disk") && !migratedAcrossCluster {
+		diags := updateVmVolumesStoragePolicy(ctx, d, ct)
+		if diags != nil {
+			return diags
+		}
 	}
 
 	// then migrate the vm if needed
-	if d.HasChange("host_id") {
+	if d.HasChange("host_id") && !migratedAcrossCluster {
 		hostId := d.Get("host_id").(string)
 		var mvpd *models.VMMigrateParamsData = nil
-		// if set to AUTO_SCHEDULE, try to auto migrate to the proper host
+		// if set to AUTO_SCHEDULE, try to auto migrate to the proper host,
+		// CloudTower schedules the vm by its placement groups and the free resources of the hosts
 		if hostId != "" && hostId != "AUTO_SCHEDULE" {
 			mvpd = &models.VMMigrateParamsData{
 				HostID: &hostId,
@@ -1597,7 +1599,54 @@ func resourceVmUpdate(ctx context.Context, d *schema.ResourceData, meta interfac
 		}
 		err = waitVmTasksFinish(ctx, ct, vms.Payload)
 		if err != nil {
-			return diag.FromErr(err)
+			return diagFromTaskErr(err)
+		}
+	}
+
+	// move the vm between folders after any migration, folders belong to a cluster
+	if d.HasChange("folder_id") {
+		folderId := d.Get("folder_id").(string)
+		if folderId != "" {
+			ap := vm.NewAddVMToFolderParams()
+			ap.RequestBody = &models.VMAddFolderParams{
+				Where: &models.VMWhereInput{
+					ID: &id,
+				},
+				Data: &models.VMAddFolderParamsData{
+					FolderID: &folderId,
+				},
+			}
+			ap.Context = ctx
+			vms, err := ct.Api.VM.AddVMToFolder(ap)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+			err = waitVmTasksFinish(ctx, ct, vms.Payload)
+			if err != nil {
+				return diagFromTaskErr(err)
+			}
+		} else {
+			rp := vm.NewRemoveVMToFolderParams()
+			rp.RequestBody = &models.VMOperateParams{
+				Where: &models.VMWhereInput{
+					ID: &id,
+				},
+			}
+			rp.Context = ctx
+			vms, err := ct.Api.VM.RemoveVMToFolder(rp)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+			err = waitVmTasksFinish(ctx, ct, vms.Payload)
+			if err != nil {
+				return diagFromTaskErr(err)
+			}
+		}
+	}
+
+	if d.HasChange("labels_all") {
+		if err := updateLabels(ctx, d, ct, labelTarget{vm: &models.VMWhereInput{ID: &id}}); err != nil {
+			return diagFromTaskErr(err)
 		}
 	}
 
@@ -1612,25 +1661,25 @@ func resourceVmUpdate(ctx context.Context, d *schema.ResourceData, meta interfac
 					"id": d.Id(),
 				},
 			}, graphql.OperationName("updateVm"))
-		}, utils.RetryWithExponentialBackoffOptions{})
+		}, ct.RetryOptions)
 		if err != nil {
 			return diag.FromErr(err)
 		}
 		_, err = ct.WaitTaskForResource(ctx, d.Id(), "updateVm")
 		if err != nil {
-			return diag.FromErr(err)
+			return diagFromTaskErr(err)
 		}
 	}
 	if runStatusChangeFirst && statusChangeFunc != nil {
 		err := statusChangeFunc()
 		if err != nil {
-			return diag.FromErr(err)
+			return diagFromTaskErr(err)
 		}
 	}
 	if needUpdateVmToolsAttribute {
 		_, err := helper.WaitVmToolsRunning(ctx, ct, d.Id())
 		if err != nil {
-			return diag.FromErr(err)
+			return diagFromTaskErr(err)
 		}
 		_, err = utils.RetryWithExponentialBackoff(ctx, func() (interface{}, error) {
 			return nil, ct.GraphqlApi.Mutate(ctx, &updateVm, map[string]interface{}{
@@ -1640,13 +1689,13 @@ func resourceVmUpdate(ctx context.Context, d *schema.ResourceData, meta interfac
 					"id": d.Id(),
 				},
 			}, graphql.OperationName("updateVm"))
-		}, utils.RetryWithExponentialBackoffOptions{})
+		}, ct.RetryOptions)
 		if err != nil {
 			return diag.FromErr(err)
 		}
 		_, err = ct.WaitTaskForResource(ctx, d.Id(), "updateVm")
 		if err != nil {
-			return diag.FromErr(err)
+			return diagFromTaskErr(err)
 		}
 		if needUpdateDnsServers {
 			_, err = utils.RetryWithExponentialBackoff(ctx, func() (interface{}, error) {
@@ -1657,20 +1706,20 @@ func resourceVmUpdate(ctx context.Context, d *schema.ResourceData, meta interfac
 						"id": d.Id(),
 					},
 				}, graphql.OperationName("updateVm"))
-			}, utils.RetryWithExponentialBackoffOptions{})
+			}, ct.RetryOptions)
 			if err != nil {
 				return diag.FromErr(err)
 			}
 			_, err = ct.WaitTaskForResource(ctx, d.Id(), "updateVm")
 			if err != nil {
-				return diag.FromErr(err)
+				return diagFromTaskErr(err)
 			}
 		}
 	}
 	if !runStatusChangeFirst && statusChangeFunc != nil {
 		err := statusChangeFunc()
 		if err != nil {
-			return diag.FromErr(err)
+			return diagFromTaskErr(err)
 		}
 	}
 
@@ -1700,13 +1749,79 @@ func resourceVmDelete(ctx context.Context, d *schema.ResourceData, meta interfac
 	}
 	_, err = ct.WaitTasksFinish(ctx, taskIds)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromTaskErr(err)
 	}
 
 	d.SetId("")
 	return diags
 }
 
+// updateVmVolumesStoragePolicy migrates the mounted volumes whose storage_policy changed to their new
+// storage policy, one at a time to limit the data moved at once
+func updateVmVolumesStoragePolicy(ctx context.Context, d *schema.ResourceData, ct *cloudtower.Client) diag.Diagnostics {
+	for volumeId, policy := range changedVmVolumeStoragePolicies(d) {
+		volumeId, policy := volumeId, policy
+		uvp := vm_volume.NewUpdateVMVolumeParams()
+		uvp.RequestBody = &models.VMVolumeUpdationParams{
+			Where: &models.VMVolumeWhereInput{
+				ID: &volumeId,
+			},
+			Data: &models.VMVolumeUpdationParamsData{
+				ElfStoragePolicy: &policy,
+			},
+		}
+		uvp.Context = ctx
+		volumes, err := ct.Api.VMVolume.UpdateVMVolume(uvp)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		taskIds := make([]string, 0)
+		for _, v := range volumes.Payload {
+			if v.TaskID != nil {
+				taskIds = append(taskIds, *v.TaskID)
+			}
+		}
+		_, err = ct.WaitTasksFinish(ctx, taskIds)
+		if err != nil {
+			return diagFromTaskErr(err)
+		}
+	}
+	return nil
+}
+
+// changedVmVolumeStoragePolicies maps the volumes mounted both before and after the change of disk
+// to their new storage_policy, disks are matched by vm_volume_id as they may be added, removed or reordered
+func changedVmVolumeStoragePolicies(d *schema.ResourceData) map[string]models.VMVolumeElfStoragePolicyType {
+	o, n := d.GetChange("disk")
+	before := vmDiskStoragePolicies(o.([]interface{}))
+	changed := make(map[string]models.VMVolumeElfStoragePolicyType)
+	for volumeId, policy := range vmDiskStoragePolicies(n.([]interface{})) {
+		if origin, ok := before[volumeId]; ok && policy != "" && policy != origin {
+			changed[volumeId] = models.VMVolumeElfStoragePolicyType(policy)
+		}
+	}
+	return changed
+}
+
+func vmDiskStoragePolicies(disks []interface{}) map[string]string {
+	policies := make(map[string]string)
+	for _, item := range disks {
+		disk, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		volumeId, _ := disk["vm_volume_id"].(string)
+		volumes, _ := disk["vm_volume"].([]interface{})
+		if volumeId == "" || len(volumes) == 0 {
+			continue
+		}
+		if volume, ok := volumes[0].(map[string]interface{}); ok {
+			policies[volumeId], _ = volume["storage_policy"].(string)
+		}
+	}
+	return policies
+}
+
 func waitVmTasksFinish(ctx context.Context, ct *cloudtower.Client, vms []*models.WithTaskVM) error {
 	taskIds := make([]string, 0)
 	for _, v := range vms {
@@ -1910,6 +2025,7 @@ func expandVmToolsConfig(d *schema.ResourceData, vmId string, common *VmCreateCo
 		return nil, nil
 	}
 
+	toolsConfig.cloudInitHostname = d.Get("create_effect.0.cloud_init.0.hostname").(string)
 	return &toolsConfig, nil
 }
 
@@ -1935,6 +2051,461 @@ func expandVmStatusConfig(d *schema.ResourceData) (*VmStatusConfig, error) {
 	}, nil
 }
 
+// expandVmNicUpdates matches the configured nics to the ones the vm has by the id kept in their
+// position, or by their mac address once a cross cluster migration recreated them, only changed
+// nics are updated, so the others keep their mac address and order. It also returns whether the
+// IP of a nic is configured, which needs VMTools
+func expandVmNicUpdates(ctx context.Context, d *schema.ResourceData, ct *cloudtower.Client) (*VmNicStruct, bool, diag.Diagnostics) {
+	nicsToDelete := make([]VmNicDelete, 0)
+	nicsToCreate := make([]VmNicCreate, 0)
+	nicsToUpdate := make([]VmNicUpdate, 0)
+	vmNics, diags := readVmNics(ctx, d, ct)
+	if diags != nil {
+		return nil, false, diags
+	}
+	curMap := make(map[string]*models.VMNic, 0)
+	macIds := make(map[string]string, 0)
+	for _, n := range vmNics {
+		curMap[*n.ID] = n
+		if n.MacAddress != nil {
+			macIds[*n.MacAddress] = *n.ID
+		}
+	}
+	var nics []*VmNic
+	bytes, err := json.Marshal(d.Get("nic"))
+	if err != nil {
+		return nil, false, diag.FromErr(err)
+	}
+	err = json.Unmarshal(bytes, &nics)
+	if err != nil {
+		return nil, false, diag.FromErr(err)
+	}
+	needConfigureIp := false
+	for idx, n := range nics {
+		origin := curMap[n.Id]
+		if origin == nil && n.MacAddress != nil && *n.MacAddress != "" {
+			origin = curMap[macIds[*n.MacAddress]]
+		}
+		if origin != nil {
+			delete(curMap, *origin.ID)
+			data, ipChanged := expandVmNicUpdateData(d, idx, n)
+			if data == nil {
+				continue
+			}
+			needConfigureIp = needConfigureIp || ipChanged
+			nicsToUpdate = append(nicsToUpdate, VmNicUpdate{
+				Where: VmNicWhereInput{Id: *origin.ID},
+				Data:  *data,
+			})
+			continue
+		}
+		nicToCreate := VmNicCreate{
+			Enabled:    *n.Enabled,
+			Gateway:    *n.Gateway,
+			IPAddress:  *n.IPAddress,
+			LocalId:    "",
+			MacAddress: *n.MacAddress,
+			Model:      *n.Model,
+			SubnetMask: *n.SubnetMask,
+			Vlan: &ConnectStruct{
+				Connect: &ConnectConnect{
+					Id: n.VlanId,
+				},
+			},
+		}
+		if *n.IPAddress != "" {
+			nicToCreate.IpType = "STATIC"
+			needConfigureIp = true
+		}
+		nicsToCreate = append(nicsToCreate, nicToCreate)
+	}
+	for _, n := range vmNics {
+		if curMap[*n.ID] != nil {
+			nicsToDelete = append(nicsToDelete, VmNicDelete{
+				Id: *n.ID,
+			})
+		}
+	}
+	return &VmNicStruct{
+		Create: nicsToCreate,
+		Update: nicsToUpdate,
+		Delete: nicsToDelete,
+	}, needConfigureIp, nil
+}
+
+// expandVmDiskUpdates builds the updates of the cd-roms and disks of the vm from the ones it has
+func expandVmDiskUpdates(ctx context.Context, d *schema.ResourceData, ct *cloudtower.Client) (*VmDiskStruct, diag.Diagnostics) {
+	diskToCreate := make([]VmDiskCreate, 0)
+	diskToUpdate := make([]VmDiskUpdate, 0)
+	diskToDelete := make([]VmDiskDelete, 0)
+	cdRoms, diags := readCdRoms(ctx, d, ct)
+	if diags != nil {
+		return nil, diags
+	}
+	vmDisks, vmVolumes, diags := readVmDisks(ctx, d, ct)
+	if diags != nil {
+		return nil, diags
+	}
+	curVolumeMap := make(map[string]*models.VMVolume, 0)
+	for _, v := range vmVolumes {
+		curVolumeMap[*v.ID] = v
+	}
+	if d.HasChange("cd_rom") {
+		curMap := make(map[string]*models.VMDisk, 0)
+		for _, v := range cdRoms {
+			curMap[*v.ID] = v
+		}
+		var cdRomsData []*CdRom
+		bytes, err := json.Marshal(d.Get("cd_rom"))
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+		err = json.Unmarshal(bytes, &cdRomsData)
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+		// push all current cd_rom to update, ignore deleted cd-rom, tower will handle it
+		for _, cr := range cdRomsData {
+			// for existing cd_rom, update it
+			origin := curMap[cr.Id]
+			if origin != nil {
+				var data = VmDiskUpdateData{}
+				data.Boot = int(cr.Boot)
+				data.Bus = *origin.Bus
+				data.Key = *origin.Key
+				data.Disabled = *origin.Disabled
+				data.Type = models.VMDiskTypeCDROM
+				if origin.ElfImage != nil && cr.IsoId == "" {
+					flag := true
+					data.ElfImage = &ConnectStruct{
+						Disconnect: &flag,
+					}
+				} else if !(cr.IsoId == "" && origin.ElfImage == nil) &&
+					!(origin.ElfImage != nil && cr.IsoId == *origin.ElfImage.ID) {
+					data.ElfImage = &ConnectStruct{
+						Connect: &ConnectConnect{
+							Id: cr.IsoId,
+						},
+					}
+				}
+				diskToUpdate = append(diskToUpdate, VmDiskUpdate{
+					Where: VmDiskWhereInput{Id: cr.Id},
+					Data:  data,
+				})
+			} else {
+				var data = VmDiskCreate{
+					Boot: int(cr.Boot),
+					Bus:  models.BusIDE,
+					Type: models.VMDiskTypeCDROM,
+				}
+				if cr.IsoId != "" {
+					data.ElfImage = &ConnectStruct{
+						Connect: &ConnectConnect{
+							Id: cr.IsoId,
+						},
+					}
+				}
+				diskToCreate = append(diskToCreate, data)
+			}
+		}
+	} else {
+		// keep original cd_rom
+		for _, v := range cdRoms {
+			diskToUpdate = append(diskToUpdate, VmDiskUpdate{
+				Where: VmDiskWhereInput{Id: *v.ID},
+				Data: VmDiskUpdateData{
+					Boot:     int(*v.Boot),
+					Bus:      *v.Bus,
+					Key:      *v.Key,
+					Type:     models.VMDiskTypeCDROM,
+					Disabled: *v.Disabled,
+				},
+			})
+		}
+	}
+	if d.HasChange("disk") {
+		curMap := make(map[string]*models.VMDisk, 0)
+		for _, v := range vmDisks {
+			// use volume id as key, a disk without a volume can not be matched
+			if v.VMVolume == nil {
+				continue
+			}
+			curMap[*v.VMVolume.ID] = v
+		}
+		var disks []*VmDisk
+		bytes, err := json.Marshal(d.Get("disk"))
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+		err = json.Unmarshal(bytes, &disks)
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+		for _, vd := range disks {
+			origin := curMap[vd.VmVolumeId]
+			originVolume := curVolumeMap[vd.VmVolumeId]
+			if vd.VmVolumeId != "" {
+				if origin != nil {
+					// if given volume is mounted, update the disk in place and only
+					// expand the volume when it grows, invalid changes are rejected at plan time
+					var data = VmDiskUpdateData{
+						Boot: vd.Boot,
+						Bus:  vd.Bus,
+						Type: *origin.Type,
+					}
+					if len(vd.VmVolume) > 0 && originVolume != nil && vd.VmVolume[0].Size > *originVolume.Size {
+						data.VmVolume = &VmVolumeCreateConnectStruct{
+							Update: &VmVolumeUpdateInput{
+								Size: vd.VmVolume[0].Size,
+							},
+						}
+					}
+					// the disk id in the state is stale once the disk was recreated
+					// by a migration across clusters
+					diskToUpdate = append(diskToUpdate, VmDiskUpdate{
+						Where: VmDiskWhereInput{
+							Id: *origin.ID,
+						},
+						Data: data,
+					})
+					delete(curMap, vd.VmVolumeId)
+				} else {
+					// if giving volume is not mounted, mount it
+					var data = VmDiskCreate{
+						Boot: vd.Boot,
+						Bus:  vd.Bus,
+						Type: models.VMDiskTypeDISK,
+						VmVolume: &VmVolumeCreateConnectStruct{
+							Connect: &ConnectConnect{
+								Id: vd.VmVolumeId,
+							},
+						},
+					}
+					diskToCreate = append(diskToCreate, data)
+				}
+			} else if len(vd.VmVolume) > 0 {
+				// no volume is given, but VmVolume is configured mean we need to create one
+				var data = VmDiskCreate{
+					Boot: vd.Boot,
+					Bus:  vd.Bus,
+					Type: models.VMDiskTypeDISK,
+					VmVolume: &VmVolumeCreateConnectStruct{
+						Create: &VmVolumeCreateInput{
+							Name:             vd.VmVolume[0].Name,
+							Path:             "",
+							Size:             vd.VmVolume[0].Size,
+							ElfStoragePolicy: vd.VmVolume[0].StoragePolicy,
+							LocalCreatedAt:   "",
+							LocalId:          "",
+							Mounting:         true,
+							Sharing:          false,
+							Cluster: &ConnectStruct{
+								Connect: &ConnectConnect{
+									Id: "",
+								},
+							},
+						},
+					},
+				}
+				diskToCreate = append(diskToCreate, data)
+			}
+		}
+		// only the disks the vm had are detached, not the ones of cloudtower_vm_disk_attachment
+		o, _ := d.GetChange("disk")
+		had := make(map[string]bool)
+		for _, item := range o.([]interface{}) {
+			if disk, ok := item.(map[string]interface{}); ok {
+				had[disk["vm_volume_id"].(string)] = true
+			}
+		}
+		for _, d := range vmDisks {
+			// cd-roms and disks without a volume are not in curMap
+			if d.VMVolume != nil && curMap[*d.VMVolume.ID] != nil && had[*d.VMVolume.ID] {
+				diskToDelete = append(diskToDelete, VmDiskDelete{
+					Id: *d.ID,
+				})
+			}
+		}
+	} else {
+		// keep original disks untouched
+		for _, v := range vmDisks {
+			diskToUpdate = append(diskToUpdate, VmDiskUpdate{
+				Where: VmDiskWhereInput{
+					Id: *v.ID,
+				},
+				Data: VmDiskUpdateData{
+					Boot: int(*v.Boot),
+					Bus:  *v.Bus,
+					Type: models.VMDiskTypeDISK,
+				},
+			})
+		}
+	}
+	sort.SliceStable(diskToCreate, func(i, j int) bool {
+		return diskToCreate[i].Boot < diskToCreate[j].Boot
+	})
+
+	sort.SliceStable(diskToUpdate, func(i, j int) bool {
+		return diskToUpdate[i].Data.Boot < diskToUpdate[j].Data.Boot
+	})
+
+	return &VmDiskStruct{
+		Create: diskToCreate,
+		Update: diskToUpdate,
+		Delete: diskToDelete,
+	}, nil
+}
+
+// expandVmNicUpdateData returns the changed attributes of the nic at idx, nil when
+// nothing changed, and whether its IP configuration changed, which needs VMTools
+func expandVmNicUpdateData(d *schema.ResourceData, idx int, n *VmNic) (*VmNicUpdateData, bool) {
+	changed := func(key string) bool {
+		return d.HasChange(fmt.Sprintf("nic.%d.%s", idx, key))
+	}
+	data := &VmNicUpdateData{}
+	updated := false
+	if changed("vlan_id") {
+		data.Vlan = &ConnectStruct{
+			Connect: &ConnectConnect{
+				Id: n.VlanId,
+			},
+		}
+		updated = true
+	}
+	if changed("enabled") {
+		data.Enabled = n.Enabled
+		updated = true
+	}
+	if changed("mirror") {
+		data.Mirror = n.Mirror
+		updated = true
+	}
+	if changed("model") && *n.Model != "" {
+		data.Model = *n.Model
+		updated = true
+	}
+	if changed("mac_address") && *n.MacAddress != "" {
+		data.MacAddress = *n.MacAddress
+		updated = true
+	}
+	ipChanged := false
+	if changed("ip_address") || changed("subnet_mask") || changed("gateway") {
+		if *n.IPAddress != "" {
+			data.IPAddress = *n.IPAddress
+			data.SubnetMask = *n.SubnetMask
+			data.Gateway = *n.Gateway
+			data.IpType = "STATIC"
+			ipChanged = true
+			updated = true
+		}
+	}
+	if !updated {
+		return nil, false
+	}
+	return data, ipChanged
+}
+
+type VmClusterMigrationConfig struct {
+	ClusterId     string
+	HostId        *string
+	StoragePolicy *models.VMVolumeElfStoragePolicyType
+	// VlanMapping maps the vlan of a nic in the current cluster to the one in the target cluster
+	VlanMapping map[string]string
+}
+
+// expandVmClusterMigrationConfig takes the target of a cross cluster migration from the
+// host, disks and nics changed along with cluster_id
+func expandVmClusterMigrationConfig(d *schema.ResourceData) (*VmClusterMigrationConfig, error) {
+	config := &VmClusterMigrationConfig{
+		ClusterId:   d.Get("cluster_id").(string),
+		VlanMapping: make(map[string]string),
+	}
+	if hostId := d.Get("host_id").(string); d.HasChange("host_id") && hostId != "" && hostId != "AUTO_SCHEDULE" {
+		config.HostId = &hostId
+	}
+	for _, policy := range changedVmVolumeStoragePolicies(d) {
+		policy := policy
+		if config.StoragePolicy != nil && *config.StoragePolicy != policy {
+			return nil, fmt.Errorf("disks migrated to another cluster must use the same storage policy, got %s and %s", *config.StoragePolicy, policy)
+		}
+		config.StoragePolicy = &policy
+	}
+	o, n := d.GetChange("nic")
+	oldNics, newNics := o.([]interface{}), n.([]interface{})
+	for idx := 0; idx < len(oldNics) && idx < len(newNics); idx++ {
+		src := oldNics[idx].(map[string]interface{})["vlan_id"].(string)
+		dest := newNics[idx].(map[string]interface{})["vlan_id"].(string)
+		if src == dest {
+			continue
+		}
+		if mapped, ok := config.VlanMapping[src]; ok && mapped != dest {
+			return nil, fmt.Errorf("nics on vlan %s must be migrated to the same vlan, got %s and %s", src, mapped, dest)
+		}
+		config.VlanMapping[src] = dest
+	}
+	return config, nil
+}
+
+type VmWaitForGuestConfig struct {
+	readiness helper.GuestReadiness
+	timeout   time.Duration
+}
+
+// expandVmWaitForGuestConfig returns nil when wait_for_guest is not configured
+func expandVmWaitForGuestConfig(d *schema.ResourceData) (*VmWaitForGuestConfig, error) {
+	if _, ok := d.GetOk("wait_for_guest"); !ok {
+		return nil, nil
+	}
+	config := &VmWaitForGuestConfig{
+		readiness: helper.GuestReadiness{
+			IPAddress: d.Get("wait_for_guest.0.ip_address").(bool),
+		},
+	}
+	if d.Get("wait_for_guest.0.hostname").(bool) {
+		config.readiness.Hostname = expectedGuestHostname(d)
+	}
+	if timeout := d.Get("wait_for_guest.0.timeout").(string); timeout != "" {
+		var err error
+		if config.timeout, err = time.ParseDuration(timeout); err != nil {
+			return nil, err
+		}
+	}
+	return config, nil
+}
+
+// expectedGuestHostname is the hostname VMTools reports once it is applied,
+// hostname is applied by VMTools after cloud-init sets its own
+func expectedGuestHostname(d *schema.ResourceData) string {
+	if hostname, ok := d.GetOk("hostname"); ok {
+		return hostname.(string)
+	}
+	return d.Get("create_effect.0.cloud_init.0.hostname").(string)
+}
+
+// waitVmGuestReady waits for the guest of a running VM as configured by wait_for_guest
+func waitVmGuestReady(ctx context.Context, d *schema.ResourceData, ct *cloudtower.Client) error {
+	config, err := expandVmWaitForGuestConfig(d)
+	if err != nil || config == nil {
+		return err
+	}
+	v, diags := readVm(ctx, d, ct)
+	if diags.HasError() {
+		return fmt.Errorf("failed to read VM %s: %s", d.Id(), diags[0].Summary)
+	}
+	if v == nil || v.Status == nil || *v.Status != models.VMStatusRUNNING {
+		// only a running guest can get ready
+		return nil
+	}
+	// without a timeout of its own, the wait is bounded by the create timeout in ctx
+	if config.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.timeout)
+		defer cancel()
+	}
+	_, err = helper.WaitVmGuestReady(ctx, ct, d.Id(), config.readiness)
+	return err
+}
+
 func readVm(ctx context.Context, d *schema.ResourceData, ct *cloudtower.Client) (*models.VM, diag.Diagnostics) {
 	var diags diag.Diagnostics
 	id := d.Id()
@@ -2005,6 +2576,7 @@ func readVmDisks(ctx context.Context, d *schema.ResourceData, ct *cloudtower.Cli
 			},
 		},
 	}
+	gp2.Context = ctx
 	vmVolumes, err := ct.Api.VMVolume.GetVMVolumes(gp2)
 	if err != nil {
 		return nil, nil, diag.FromErr(err)
@@ -2013,8 +2585,12 @@ func readVmDisks(ctx context.Context, d *schema.ResourceData, ct *cloudtower.Cli
 	for _, v := range vmVolumes.Payload {
 		vmVolumeMap[*v.ID] = v
 	}
+	sortVmDisksByBoot(vmDisks.Payload)
 	vmVolumesSlice := make([]*models.VMVolume, len(vmDisks.Payload))
 	for idx, v := range vmDisks.Payload {
+		if v.VMVolume == nil {
+			continue
+		}
 		vmVolume := vmVolumeMap[*v.VMVolume.ID]
 		vmVolumesSlice[idx] = vmVolume
 	}
@@ -2060,6 +2636,7 @@ func readVmDisksFromContentLibraryTemplate(ctx context.Context, d *schema.Resour
 			ID: contentLibraryVmTemplates.Payload[0].VMTemplates[0].ID,
 		},
 	}
+	gp.Context = ctx
 	vmTemplates, err := ct.Api.VMTemplate.GetVMTemplates(gp)
 	if err != nil {
 		return nil, diag.FromErr(err)
@@ -2087,6 +2664,102 @@ func readVmDisksFromSnapshot(ctx context.Context, d *schema.ResourceData, ct *cl
 	return snapshots.Payload[0].VMDisks, nil
 }
 
+// readVmCreateSourceCpu reads the cpu of the vm, template or snapshot a new vm is created from
+func readVmCreateSourceCpu(ctx context.Context, d *schema.ResourceData, ct *cloudtower.Client) (*models.NestedCPU, diag.Diagnostics) {
+	if id := d.Get("create_effect.0.clone_from_vm").(string); id != "" {
+		gp := vm.NewGetVmsParams()
+		gp.RequestBody = &models.GetVmsRequestBody{
+			Where: &models.VMWhereInput{
+				ID: &id,
+			},
+		}
+		gp.Context = ctx
+		vms, err := ct.Api.VM.GetVms(gp)
+		if err != nil {
+			return nil, diag.FromErr(err)
+		} else if len(vms.Payload) == 0 {
+			return nil, diag.Errorf("vm %s not found", id)
+		}
+		return vms.Payload[0].CPU, nil
+	}
+	if id := d.Get("create_effect.0.clone_from_template").(string); id != "" {
+		gp := vm_template.NewGetVMTemplatesParams()
+		gp.RequestBody = &models.GetVMTemplatesRequestBody{
+			Where: &models.VMTemplateWhereInput{
+				ID: &id,
+			},
+		}
+		gp.Context = ctx
+		vmTemplates, err := ct.Api.VMTemplate.GetVMTemplates(gp)
+		if err != nil {
+			return nil, diag.FromErr(err)
+		} else if len(vmTemplates.Payload) == 0 {
+			return nil, diag.Errorf("template %s not found", id)
+		}
+		return vmTemplates.Payload[0].CPU, nil
+	}
+	if id := d.Get("create_effect.0.clone_from_content_library_template").(string); id != "" {
+		gcp := content_library_vm_template.NewGetContentLibraryVMTemplatesParams()
+		gcp.RequestBody = &models.GetContentLibraryVMTemplatesRequestBody{
+			Where: &models.ContentLibraryVMTemplateWhereInput{
+				ID: &id,
+			},
+		}
+		gcp.Context = ctx
+		contentLibraryVmTemplates, err := ct.Api.ContentLibraryVMTemplate.GetContentLibraryVMTemplates(gcp)
+		if err != nil {
+			return nil, diag.FromErr(err)
+		} else if len(contentLibraryVmTemplates.Payload) == 0 {
+			return nil, diag.Errorf("content library template %s not found", id)
+		}
+		return contentLibraryVmTemplates.Payload[0].CPU, nil
+	}
+	if id := d.Get("create_effect.0.rebuild_from_snapshot").(string); id != "" {
+		gp := vm_snapshot.NewGetVMSnapshotsParams()
+		gp.RequestBody = &models.GetVMSnapshotsRequestBody{
+			Where: &models.VMSnapshotWhereInput{
+				ID: &id,
+			},
+		}
+		gp.Context = ctx
+		snapshots, err := ct.Api.VMSnapshot.GetVMSnapshots(gp)
+		if err != nil {
+			return nil, diag.FromErr(err)
+		} else if len(snapshots.Payload) == 0 {
+			return nil, diag.Errorf("snapshot %s not found", id)
+		}
+		return snapshots.Payload[0].CPU, nil
+	}
+	return nil, nil
+}
+
+// resolveVmCreateSourceCpuTopology completes the topology of a vm created from another one when
+// only vcpu is configured, the cores or sockets of the source are kept when vcpu is divisible by them
+func resolveVmCreateSourceCpuTopology(ctx context.Context, d *schema.ResourceData, ct *cloudtower.Client, basic *VmBasicConfig) diag.Diagnostics {
+	if basic.Vcpu == nil || basic.CpuCores != nil || basic.CpuSockets != nil {
+		return nil
+	}
+	cpu, diags := readVmCreateSourceCpu(ctx, d, ct)
+	if diags != nil {
+		return diags
+	}
+	var source *VmCpuTopology
+	if cpu != nil && cpu.Cores != nil && cpu.Sockets != nil && *cpu.Cores > 0 && *cpu.Sockets > 0 {
+		source = &VmCpuTopology{
+			Vcpu:    *cpu.Cores * *cpu.Sockets,
+			Cores:   *cpu.Cores,
+			Sockets: *cpu.Sockets,
+		}
+	}
+	topology, err := resolveVmCpuTopology(basic.Vcpu, nil, nil, source)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	basic.CpuCores = &topology.Cores
+	basic.CpuSockets = &topology.Sockets
+	return nil
+}
+
 func readCdRoms(ctx context.Context, d *schema.ResourceData, ct *cloudtower.Client) ([]*models.VMDisk, diag.Diagnostics) {
 	id := d.Id()
 	gp := vm_disk.NewGetVMDisksParams()
@@ -2104,9 +2777,17 @@ func readCdRoms(ctx context.Context, d *schema.ResourceData, ct *cloudtower.Clie
 	if err != nil {
 		return nil, diag.FromErr(err)
 	}
+	sortVmDisksByBoot(cdRoms.Payload)
 	return cdRoms.Payload, nil
 }
 
+// sortVmDisksByBoot orders disks as they are configured, CloudTower does not list them in a stable order
+func sortVmDisksByBoot(disks []*models.VMDisk) {
+	sort.SliceStable(disks, func(i, j int) bool {
+		return *disks[i].Boot < *disks[j].Boot
+	})
+}
+
 type VmCreateCommon struct {
 	basic               *VmBasicConfig
 	clusterId           *string
@@ -2280,6 +2961,9 @@ func rebuildVmFromSnapshot(rebuildFrom string, ctx context.Context, d *schema.Re
 	if diags != nil {
 		return nil, diags
 	}
+	if diags := resolveVmCreateSourceCpuTopology(ctx, d, ct, common.basic); diags != nil {
+		return nil, diags
+	}
 	rp := vm.NewRebuildVMParams()
 	vmDisks, diags := readVmDisksFromSnapshot(ctx, d, ct)
 	if diags != nil {
@@ -2333,7 +3017,7 @@ func rebuildVmFromSnapshot(rebuildFrom string, ctx context.Context, d *schema.Re
 	}
 	err = waitVmTasksFinish(ctx, ct, response.Payload)
 	if err != nil {
-		return nil, diag.FromErr(err)
+		return nil, diagFromTaskErr(err)
 	}
 	toolsConfig, err := expandVmToolsConfig(d, *response.Payload[0].Data.ID, common)
 	if err != nil {
@@ -2341,7 +3025,7 @@ func rebuildVmFromSnapshot(rebuildFrom string, ctx context.Context, d *schema.Re
 	}
 	err = configureVmToolsAttributeAfterCreate(ctx, ct, toolsConfig)
 	if err != nil {
-		return nil, diag.FromErr(err)
+		return nil, diagFromTaskErr(err)
 	}
 	return response.Payload, nil
 }
@@ -2351,6 +3035,9 @@ func cloneVmFromSourceVm(cloneFrom string, ctx context.Context, d *schema.Resour
 	if diags != nil {
 		return nil, diags
 	}
+	if diags := resolveVmCreateSourceCpuTopology(ctx, d, ct, common.basic); diags != nil {
+		return nil, diags
+	}
 	cp := vm.NewCloneVMParams()
 	vmDisks, vmVolumes, diags := readVmDisks(ctx, d, ct)
 	var pathVolumeMap = make(map[string]*models.VMVolume)
@@ -2412,7 +3099,7 @@ func cloneVmFromSourceVm(cloneFrom string, ctx context.Context, d *schema.Resour
 	}
 	err = waitVmTasksFinish(ctx, ct, response.Payload)
 	if err != nil {
-		return nil, diag.FromErr(err)
+		return nil, diagFromTaskErr(err)
 	}
 	toolsConfig, err := expandVmToolsConfig(d, *response.Payload[0].Data.ID, common)
 	if err != nil {
@@ -2420,7 +3107,7 @@ func cloneVmFromSourceVm(cloneFrom string, ctx context.Context, d *schema.Resour
 	}
 	err = configureVmToolsAttributeAfterCreate(ctx, ct, toolsConfig)
 	if err != nil {
-		return nil, diag.FromErr(err)
+		return nil, diagFromTaskErr(err)
 	}
 	return response.Payload, nil
 }
@@ -2430,6 +3117,9 @@ func cloneVmFromVmTemplate(cloneFromTemplate string, ctx context.Context, d *sch
 	if diags != nil {
 		return nil, diags
 	}
+	if diags := resolveVmCreateSourceCpuTopology(ctx, d, ct, common.basic); diags != nil {
+		return nil, diags
+	}
 	isFullCopyRes, ok := d.GetOkExists("create_effect.0.is_full_copy")
 	if !ok {
 		return nil, diag.Errorf("when create from template, please set is_full_copy")
@@ -2502,7 +3192,7 @@ func cloneVmFromVmTemplate(cloneFromTemplate string, ctx context.Context, d *sch
 	}
 	err = waitVmTasksFinish(ctx, ct, response.Payload)
 	if err != nil {
-		return nil, diag.FromErr(err)
+		return nil, diagFromTaskErr(err)
 	}
 	toolsConfig, err := expandVmToolsConfig(d, *response.Payload[0].Data.ID, common)
 	if err != nil {
@@ -2510,7 +3200,7 @@ func cloneVmFromVmTemplate(cloneFromTemplate string, ctx context.Context, d *sch
 	}
 	err = configureVmToolsAttributeAfterCreate(ctx, ct, toolsConfig)
 	if err != nil {
-		return nil, diag.FromErr(err)
+		return nil, diagFromTaskErr(err)
 	}
 	return response.Payload, nil
 }
@@ -2520,6 +3210,9 @@ func cloneVmFromContentLibraryVmTemplate(cloneFromContentLibraryTemplate string,
 	if diags != nil {
 		return nil, diags
 	}
+	if diags := resolveVmCreateSourceCpuTopology(ctx, d, ct, common.basic); diags != nil {
+		return nil, diags
+	}
 	isFullCopyRes, ok := d.GetOkExists("create_effect.0.is_full_copy")
 	if !ok {
 		return nil, diag.Errorf("when create from template, please set is_full_copy")
@@ -2592,7 +3285,7 @@ func cloneVmFromContentLibraryVmTemplate(cloneFromContentLibraryTemplate string,
 	}
 	err = waitVmTasksFinish(ctx, ct, response.Payload)
 	if err != nil {
-		return nil, diag.FromErr(err)
+		return nil, diagFromTaskErr(err)
 	}
 	toolsConfig, err := expandVmToolsConfig(d, *response.Payload[0].Data.ID, common)
 	if err != nil {
@@ -2600,7 +3293,7 @@ func cloneVmFromContentLibraryVmTemplate(cloneFromContentLibraryTemplate string,
 	}
 	err = configureVmToolsAttributeAfterCreate(ctx, ct, toolsConfig)
 	if err != nil {
-		return nil, diag.FromErr(err)
+		return nil, diagFromTaskErr(err)
 	}
 	return response.Payload, nil
 }
@@ -2663,14 +3356,12 @@ func createBlankVm(ctx context.Context, d *schema.ResourceData, ct *cloudtower.C
 	if len(vmToolsParams) > 0 {
 		return nil, diag.Errorf("Create blank VM with vm tools specific config is not supported, need vm tools to be installed, forbidden fields: %v", vmToolsParams)
 	}
-	if common.basic.CpuCores == nil {
-		var core int32 = 1
-		common.basic.CpuCores = &core
-	}
-	if common.basic.CpuSockets == nil {
-		socket := *common.basic.Vcpu / *common.basic.CpuCores
-		common.basic.CpuSockets = &socket
+	topology, err := resolveVmCpuTopology(common.basic.Vcpu, common.basic.CpuCores, common.basic.CpuSockets, nil)
+	if err != nil {
+		return nil, diag.FromErr(err)
 	}
+	common.basic.CpuCores = &topology.Cores
+	common.basic.CpuSockets = &topology.Sockets
 	cvp.RequestBody = []*models.VMCreationParams{{
 		Name:        &common.basic.Name,
 		ClusterID:   common.clusterId,
@@ -2699,7 +3390,7 @@ func createBlankVm(ctx context.Context, d *schema.ResourceData, ct *cloudtower.C
 	}
 	err = waitVmTasksFinish(ctx, ct, response.Payload)
 	if err != nil {
-		return nil, diag.FromErr(err)
+		return nil, diagFromTaskErr(err)
 	}
 	return response.Payload, nil
 }
@@ -2709,7 +3400,7 @@ type ConfigureVmToolsAttributeAfterCreateParams struct {
 	nics              []*models.VMNicParams
 	hostname          string
 	dnsServers        string
-	containsCloudInit bool
+	cloudInitHostname string
 	guestOsUsername   string
 	guestOsPassword   string
 }
@@ -2723,23 +3414,21 @@ func configureVmToolsAttributeAfterCreate(ctx context.Context, ct *cloudtower.Cl
 		return err
 	}
 	defer stopFunc()
-	resp, err := helper.WaitVmToolsRunning(ctx, ct, params.vmId)
-	if err != nil {
+	// the guest is configured once VMTools reports its network is up, and the hostname
+	// cloud-init sets if any, which means cloud-init has finished, until the deadline of
+	// ctx, which is the timeout of the resource operation
+	readiness := helper.GuestReadiness{
+		IPAddress: true,
+		Hostname:  params.cloudInitHostname,
+	}
+	if _, err = helper.WaitVmGuestReady(ctx, ct, params.vmId, readiness); err != nil {
 		return err
 	}
-	if params.containsCloudInit {
-		// cloud init may take some times to do configure or reboot, need wait cloudinit finishing
-		// we dont have way to detect if cloud init is run or finished, wait for a while.
-		time.Sleep(2 * time.Minute)
-	} else {
-		// if no cloud init data is injected,
-		time.Sleep(1 * time.Minute)
-	}
 	vmNicParams := vm_nic.NewGetVMNicsParams()
 	vmNicParams.RequestBody = &models.GetVMNicsRequestBody{
 		Where: &models.VMNicWhereInput{
 			VM: &models.VMWhereInput{
-				ID: resp.ID,
+				ID: &params.vmId,
 			},
 		},
 		OrderBy: models.NewVMNicOrderByInput(models.VMNicOrderByInputOrderASC),
@@ -2747,7 +3436,7 @@ func configureVmToolsAttributeAfterCreate(ctx context.Context, ct *cloudtower.Cl
 	originalNics, err := utils.RetryWithExponentialBackoff(ctx, func() (*vm_nic.GetVMNicsOK, error) {
 		vmNicParams.Context = ctx
 		return ct.Api.VMNic.GetVMNics(vmNicParams)
-	}, utils.RetryWithExponentialBackoffOptions{})
+	}, ct.RetryOptions)
 	if err != nil {
 		return err
 	}
@@ -2810,7 +3499,7 @@ func configureVmToolsAttributeAfterCreate(ctx context.Context, ct *cloudtower.Cl
 				"id": params.vmId,
 			},
 		}, graphql.OperationName("updateVm"))
-	}, utils.RetryWithExponentialBackoffOptions{})
+	}, ct.RetryOptions)
 	if err != nil {
 		return err
 	}
@@ -2829,7 +3518,7 @@ func configureVmToolsAttributeAfterCreate(ctx context.Context, ct *cloudtower.Cl
 					"id": params.vmId,
 				},
 			}, graphql.OperationName("updateVm"))
-		}, utils.RetryWithExponentialBackoffOptions{})
+		}, ct.RetryOptions)
 		if err != nil {
 			return err
 		}
@@ -2859,3 +3548,43 @@ func configureVmToolsAttributeAfterCreate(ctx context.Context, ct *cloudtower.Cl
 	}
 	return nil
 }
+
+func vmImportLookup(ctx context.Context, meta interface{}) *importLookup {
+	ct := meta.(*cloudtower.Client)
+	find := func(where *models.VMWhereInput) ([]string, error) {
+		gcp := vm.NewGetVmsParams()
+		gcp.RequestBody = &models.GetVmsRequestBody{
+			Where: where,
+		}
+		gcp.Context = ctx
+		vms, err := ct.Api.VM.GetVms(gcp)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, 0, len(vms.Payload))
+		for _, v := range vms.Payload {
+			ids = append(ids, *v.ID)
+		}
+		return ids, nil
+	}
+	return &importLookup{
+		kind:   "VM",
+		format: "[cluster_name/]vm_name",
+		byId: func(id string) ([]string, error) {
+			return find(&models.VMWhereInput{
+				ID: &id,
+			})
+		},
+		byName: func(names []string) ([]string, error) {
+			where := &models.VMWhereInput{
+				Name: namesAt(names, 0),
+			}
+			if clusterName := namesAt(names, 1); clusterName != nil {
+				where.Cluster = &models.ClusterWhereInput{
+					Name: clusterName,
+				}
+			}
+			return find(where)
+		},
+	}
+}
@@ -0,0 +1,228 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/cloudtower"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/client/vm"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/client/vm_disk"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/models"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceVmDiskAttachment() *schema.Resource {
+	return &schema.Resource{
+		Description: "CloudTower vm disk attachment resource, it attaches a cloudtower_vm_volume to a VM as a disk, and detaches it without deleting the volume.",
+
+		CreateContext: resourceVmDiskAttachmentCreate,
+		ReadContext:   resourceVmDiskAttachmentRead,
+		DeleteContext: resourceVmDiskAttachmentDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importState(vmDiskAttachmentImportLookup),
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "the id of the VM disk of the attachment",
+			},
+			"vm_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "the id of the VM the volume is attached to",
+			},
+			"vm_volume_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "the id of the attached vm volume, a volume attached to several VMs must be sharing",
+			},
+			"bus": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  "VM disk's bus",
+				ValidateFunc: validation.StringInSlice([]string{"IDE", "SCSI", "VIRTIO"}, false),
+			},
+			"boot": {
+				Type:        schema.TypeInt,
+				Required:    true,
+				ForceNew:    true,
+				Description: "VM disk's boot order",
+			},
+		},
+	}
+}
+
+func resourceVmDiskAttachmentCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	ct := meta.(*cloudtower.Client)
+	vmId := d.Get("vm_id").(string)
+	volumeId := d.Get("vm_volume_id").(string)
+	bus := models.Bus(d.Get("bus").(string))
+	boot := int32(d.Get("boot").(int))
+	avp := vm.NewAddVMDiskParams()
+	avp.RequestBody = &models.VMAddDiskParams{
+		Where: &models.VMWhereInput{
+			ID: &vmId,
+		},
+		Data: &models.VMAddDiskParamsData{
+			VMDisks: &models.VMAddDiskParamsDataVMDisks{
+				MountDisks: []*models.MountDisksParams{{
+					Boot:       &boot,
+					Bus:        &bus,
+					VMVolumeID: &volumeId,
+				}},
+			},
+		},
+	}
+	avp.Context = ctx
+	vms, err := ct.Api.VM.AddVMDisk(avp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = waitVmTasksFinish(ctx, ct, vms.Payload)
+	if err != nil {
+		return diagFromTaskErr(err)
+	}
+	disks, err := getVmDisks(ctx, ct, &models.VMDiskWhereInput{
+		VM: &models.VMWhereInput{
+			ID: &vmId,
+		},
+		VMVolume: &models.VMVolumeWhereInput{
+			ID: &volumeId,
+		},
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if len(disks) < 1 {
+		return diag.Errorf("vm volume %s is not attached to VM %s", volumeId, vmId)
+	}
+	d.SetId(*disks[0].ID)
+
+	return resourceVmDiskAttachmentRead(ctx, d, meta)
+}
+
+func resourceVmDiskAttachmentRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	ct := meta.(*cloudtower.Client)
+
+	id := d.Id()
+	disks, err := getVmDisks(ctx, ct, &models.VMDiskWhereInput{
+		ID: &id,
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if len(disks) < 1 || disks[0].VMVolume == nil {
+		d.SetId("")
+		return diags
+	}
+	disk := disks[0]
+	if err = d.Set("vm_id", disk.VM.ID); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("vm_volume_id", disk.VMVolume.ID); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("bus", disk.Bus); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("boot", disk.Boot); err != nil {
+		return diag.FromErr(err)
+	}
+	return diags
+}
+
+func resourceVmDiskAttachmentDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	ct := meta.(*cloudtower.Client)
+	vmId := d.Get("vm_id").(string)
+	rvp := vm.NewRemoveVMDiskParams()
+	rvp.RequestBody = &models.VMRemoveDiskParams{
+		Where: &models.VMWhereInput{
+			ID: &vmId,
+		},
+		Data: &models.VMRemoveDiskParamsData{
+			DiskIds: []string{d.Id()},
+		},
+	}
+	rvp.Context = ctx
+	vms, err := ct.Api.VM.RemoveVMDisk(rvp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = waitVmTasksFinish(ctx, ct, vms.Payload)
+	if err != nil {
+		return diagFromTaskErr(err)
+	}
+	d.SetId("")
+	return diags
+}
+
+func getVmDisks(ctx context.Context, ct *cloudtower.Client, where *models.VMDiskWhereInput) ([]*models.VMDisk, error) {
+	gdp := vm_disk.NewGetVMDisksParams()
+	gdp.RequestBody = &models.GetVMDisksRequestBody{
+		Where: where,
+	}
+	gdp.Context = ctx
+	disks, err := ct.Api.VMDisk.GetVMDisks(gdp)
+	if err != nil {
+		return nil, err
+	}
+	return disks.Payload, nil
+}
+
+func vmDiskAttachmentImportLookup(ctx context.Context, meta interface{}) *importLookup {
+	ct := meta.(*cloudtower.Client)
+	find := func(where *models.VMDiskWhereInput) ([]string, error) {
+		disks, err := getVmDisks(ctx, ct, where)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, 0, len(disks))
+		for _, disk := range disks {
+			if disk.VMVolume != nil {
+				ids = append(ids, *disk.ID)
+			}
+		}
+		return ids, nil
+	}
+	return &importLookup{
+		kind:   "VM disk attachment",
+		format: "[[cluster_name/]vm_name/]volume_name",
+		byId: func(id string) ([]string, error) {
+			return find(&models.VMDiskWhereInput{
+				ID: &id,
+			})
+		},
+		byName: func(names []string) ([]string, error) {
+			where := &models.VMDiskWhereInput{
+				VMVolume: &models.VMVolumeWhereInput{
+					Name: namesAt(names, 0),
+				},
+			}
+			if vmName := namesAt(names, 1); vmName != nil {
+				where.VM = &models.VMWhereInput{
+					Name: vmName,
+				}
+				if clusterName := namesAt(names, 2); clusterName != nil {
+					where.VM.Cluster = &models.ClusterWhereInput{
+						Name: clusterName,
+					}
+				}
+			}
+			return find(where)
+		},
+	}
+}
@@ -0,0 +1,113 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/fake"
+)
+
+func TestAccResourceVmDiskAttachment(t *testing.T) {
+	srv := fake.NewServer(t)
+	volumes := fmt.Sprintf(`
+resource "cloudtower_vm_volume" "data" {
+  name           = "tf-acc-data"
+  cluster_id     = %[1]q
+  size           = 10737418240
+  storage_policy = "REPLICA_2_THIN_PROVISION"
+}
+
+resource "cloudtower_vm_volume" "shared" {
+  name           = "tf-acc-shared"
+  cluster_id     = %[1]q
+  size           = 10737418240
+  storage_policy = "REPLICA_2_THIN_PROVISION"
+  sharing        = true
+}
+
+resource "cloudtower_vm_disk_attachment" "shared-a" {
+  vm_id        = cloudtower_vm.tf-acc-vm-a.id
+  vm_volume_id = cloudtower_vm_volume.shared.id
+  bus          = "SCSI"
+  boot         = 3
+}
+
+resource "cloudtower_vm_disk_attachment" "shared-b" {
+  vm_id        = cloudtower_vm.tf-acc-vm-b.id
+  vm_volume_id = cloudtower_vm_volume.shared.id
+  bus          = "SCSI"
+  boot         = 3
+}
+`, srv.Fixtures.ClusterId)
+	config := srv.ProviderConfig() + testAccVmConfig(srv, "tf-acc-vm-a") + testAccVmConfig(srv, "tf-acc-vm-b") + volumes
+	attached := config + `
+resource "cloudtower_vm_disk_attachment" "data" {
+  vm_id        = cloudtower_vm.tf-acc-vm-a.id
+  vm_volume_id = cloudtower_vm_volume.data.id
+  bus          = "VIRTIO"
+  boot         = 4
+}
+`
+	// grow expands the disk of tf-acc-vm-a
+	grow := func(config string) string {
+		return strings.Replace(config, "size           = 10737418240\n    }", "size           = 21474836480\n    }", 1)
+	}
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			testAccCheckDestroy(srv, "cloudtower_vm_disk_attachment", "vm-disks"),
+			testAccCheckDestroy(srv, "cloudtower_vm_volume", "vm-volumes"),
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: attached,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(srv, "cloudtower_vm_disk_attachment.data", "vm-disks"),
+					testAccCheckExists(srv, "cloudtower_vm_disk_attachment.shared-a", "vm-disks"),
+					testAccCheckExists(srv, "cloudtower_vm_disk_attachment.shared-b", "vm-disks"),
+					resource.TestCheckResourceAttrPair("cloudtower_vm_disk_attachment.data", "vm_volume_id", "cloudtower_vm_volume.data", "id"),
+					resource.TestCheckResourceAttr("cloudtower_vm_disk_attachment.data", "bus", "VIRTIO"),
+					resource.TestCheckResourceAttr("cloudtower_vm_disk_attachment.data", "boot", "4"),
+					resource.TestCheckResourceAttr("cloudtower_vm.tf-acc-vm-a", "disk.#", "1"),
+				),
+			},
+			{
+				// the disks of the vm are updated without detaching the attached ones
+				Config: grow(attached),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudtower_vm.tf-acc-vm-a", "disk.#", "1"),
+					resource.TestCheckResourceAttr("cloudtower_vm.tf-acc-vm-a", "disk.0.vm_volume.0.size", "21474836480"),
+					testAccCheckExists(srv, "cloudtower_vm_disk_attachment.data", "vm-disks"),
+					testAccCheckExists(srv, "cloudtower_vm_disk_attachment.shared-a", "vm-disks"),
+				),
+			},
+			{
+				ResourceName:      "cloudtower_vm_disk_attachment.data",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:      "cloudtower_vm_disk_attachment.data",
+				ImportState:       true,
+				ImportStateId:     "tf-acc-vm-a/tf-acc-data",
+				ImportStateVerify: true,
+			},
+			{
+				// the detached volume outlives the attachment
+				Config: grow(config),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(srv, "cloudtower_vm_volume.data", "vm-volumes"),
+					func(*terraform.State) error {
+						if disks := srv.List("vm-disks", map[string]any{"vm_volume": map[string]any{"name": "tf-acc-data"}}); len(disks) != 0 {
+							return fmt.Errorf("tf-acc-data is still attached by %d disks", len(disks))
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,213 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/cloudtower"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/client/vm_folder"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/models"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceVmFolder() *schema.Resource {
+	return &schema.Resource{
+		Description: "CloudTower vm folder resource, VMs are placed in a folder of their cluster by the folder_id of cloudtower_vm. CloudTower folders are flat, a folder can not be placed in another one.",
+
+		CreateContext: resourceVmFolderCreate,
+		ReadContext:   resourceVmFolderRead,
+		UpdateContext: resourceVmFolderUpdate,
+		DeleteContext: resourceVmFolderDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importState(vmFolderImportLookup),
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "vm folder's id",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "vm folder's name",
+			},
+			"cluster_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "the id of the cluster the vm folder is created in, only VMs of the cluster can be placed in it",
+			},
+			"vm_num": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "number of VMs in the vm folder",
+			},
+		},
+	}
+}
+
+func resourceVmFolderCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	ct := meta.(*cloudtower.Client)
+	name := d.Get("name").(string)
+	clusterId := d.Get("cluster_id").(string)
+	cfp := vm_folder.NewCreateVMFolderParams()
+	cfp.RequestBody = []*models.VMFolderCreationParams{{
+		Name:      &name,
+		ClusterID: &clusterId,
+	}}
+	cfp.Context = ctx
+	folders, err := ct.Api.VMFolder.CreateVMFolder(cfp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(*folders.Payload[0].Data.ID)
+	_, err = ct.WaitTasksFinish(ctx, []string{*folders.Payload[0].TaskID})
+	if err != nil {
+		return diagFromTaskErr(err)
+	}
+
+	return resourceVmFolderRead(ctx, d, meta)
+}
+
+func resourceVmFolderRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	ct := meta.(*cloudtower.Client)
+
+	id := d.Id()
+	gfp := vm_folder.NewGetVMFoldersParams()
+	gfp.RequestBody = &models.GetVMFoldersRequestBody{
+		Where: &models.VMFolderWhereInput{
+			ID: &id,
+		},
+	}
+	gfp.Context = ctx
+	folders, err := ct.Api.VMFolder.GetVMFolders(gfp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if len(folders.Payload) < 1 {
+		d.SetId("")
+		return diags
+	}
+	folder := folders.Payload[0]
+	if err = d.Set("name", folder.Name); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("cluster_id", folder.Cluster.ID); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("vm_num", folder.VMNum); err != nil {
+		return diag.FromErr(err)
+	}
+	return diags
+}
+
+func resourceVmFolderUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	ct := meta.(*cloudtower.Client)
+	id := d.Id()
+	name := d.Get("name").(string)
+	ufp := vm_folder.NewUpdateVMFolderParams()
+	ufp.RequestBody = &models.VMFolderUpdationParams{
+		Where: &models.VMFolderWhereInput{
+			ID: &id,
+		},
+		Data: &models.VMFolderUpdationParamsData{
+			Name: &name,
+		},
+	}
+	ufp.Context = ctx
+	folders, err := ct.Api.VMFolder.UpdateVMFolder(ufp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	taskIds := make([]string, 0)
+	for _, f := range folders.Payload {
+		if f.TaskID != nil {
+			taskIds = append(taskIds, *f.TaskID)
+		}
+	}
+	_, err = ct.WaitTasksFinish(ctx, taskIds)
+	if err != nil {
+		return diagFromTaskErr(err)
+	}
+
+	return resourceVmFolderRead(ctx, d, meta)
+}
+
+func resourceVmFolderDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	ct := meta.(*cloudtower.Client)
+	id := d.Id()
+	dfp := vm_folder.NewDeleteVMFolderParams()
+	dfp.RequestBody = &models.VMFolderDeletionParams{
+		Where: &models.VMFolderWhereInput{
+			ID: &id,
+		},
+	}
+	dfp.Context = ctx
+	folders, err := ct.Api.VMFolder.DeleteVMFolder(dfp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	taskIds := make([]string, 0)
+	for _, f := range folders.Payload {
+		if f.TaskID != nil {
+			taskIds = append(taskIds, *f.TaskID)
+		}
+	}
+	_, err = ct.WaitTasksFinish(ctx, taskIds)
+	if err != nil {
+		return diagFromTaskErr(err)
+	}
+	d.SetId("")
+	return diags
+}
+
+func vmFolderImportLookup(ctx context.Context, meta interface{}) *importLookup {
+	ct := meta.(*cloudtower.Client)
+	find := func(where *models.VMFolderWhereInput) ([]string, error) {
+		gfp := vm_folder.NewGetVMFoldersParams()
+		gfp.RequestBody = &models.GetVMFoldersRequestBody{
+			Where: where,
+		}
+		gfp.Context = ctx
+		folders, err := ct.Api.VMFolder.GetVMFolders(gfp)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, 0, len(folders.Payload))
+		for _, f := range folders.Payload {
+			ids = append(ids, *f.ID)
+		}
+		return ids, nil
+	}
+	return &importLookup{
+		kind:   "VM folder",
+		format: "[cluster_name/]folder_name",
+		byId: func(id string) ([]string, error) {
+			return find(&models.VMFolderWhereInput{
+				ID: &id,
+			})
+		},
+		byName: func(names []string) ([]string, error) {
+			where := &models.VMFolderWhereInput{
+				Name: namesAt(names, 0),
+			}
+			if clusterName := namesAt(names, 1); clusterName != nil {
+				where.Cluster = &models.ClusterWhereInput{
+					Name: clusterName,
+				}
+			}
+			return find(where)
+		},
+	}
+}
@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/fake"
+)
+
+func TestAccResourceVmFolder(t *testing.T) {
+	srv := fake.NewServer(t)
+	config := func(name string) string {
+		return srv.ProviderConfig() + fmt.Sprintf(`
+resource "cloudtower_vm_folder" "test" {
+  name       = %q
+  cluster_id = %q
+}
+`, name, srv.Fixtures.ClusterId)
+	}
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckDestroy(srv, "cloudtower_vm_folder", "vm-folders"),
+		Steps: []resource.TestStep{
+			{
+				Config: config("tf-acc-folder"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(srv, "cloudtower_vm_folder.test", "vm-folders"),
+					resource.TestCheckResourceAttr("cloudtower_vm_folder.test", "cluster_id", srv.Fixtures.ClusterId),
+					resource.TestCheckResourceAttr("cloudtower_vm_folder.test", "vm_num", "0"),
+				),
+			},
+			{
+				Config: config("tf-acc-folder-renamed"),
+				Check:  resource.TestCheckResourceAttr("cloudtower_vm_folder.test", "name", "tf-acc-folder-renamed"),
+			},
+			{
+				ResourceName:      "cloudtower_vm_folder.test",
+				ImportState:       true,
+				ImportStateId:     srv.Fixtures.ClusterName + "/tf-acc-folder-renamed",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
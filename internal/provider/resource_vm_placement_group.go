@@ -0,0 +1,475 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/cloudtower"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/client/vm"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/client/vm_placement_group"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/models"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceVmPlacementGroup() *schema.Resource {
+	return &schema.Resource{
+		Description: "CloudTower vm placement group resource, it keeps its VMs together or apart, and on or off some hosts of their cluster. Its VMs are not moved when it changes, cloudtower_vm with host_id set to AUTO_SCHEDULE is moved to a host satisfying it.",
+
+		CreateContext: resourceVmPlacementGroupCreate,
+		ReadContext:   resourceVmPlacementGroupRead,
+		UpdateContext: resourceVmPlacementGroupUpdate,
+		DeleteContext: resourceVmPlacementGroupDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importState(vmPlacementGroupImportLookup),
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "vm placement group's id",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "vm placement group's name",
+			},
+			"cluster_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "the id of the cluster the vm placement group is created in, its VMs and hosts belong to the cluster",
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "vm placement group's description",
+			},
+			"enabled": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "whether the policies of the vm placement group are enforced",
+			},
+			"vm_ids": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "the ids of the VMs in the vm placement group",
+			},
+			"vm_vm_policy": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "how the VMs of the vm placement group are placed relative to each other, VMs of a MUST_SAME or PREFER_SAME group run on the same host, VMs of a MUST_DIFFERENT or PREFER_DIFFERENT group run on different hosts, no vm vm policy is applied if not set",
+				ValidateFunc: validation.StringInSlice([]string{"MUST_SAME", "MUST_DIFFERENT", "PREFER_SAME", "PREFER_DIFFERENT"}, false),
+			},
+			"vm_host_must_policy":   vmHostPolicySchema("must"),
+			"vm_host_prefer_policy": vmHostPolicySchema("should"),
+		},
+	}
+}
+
+func vmHostPolicySchema(verb string) *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: fmt.Sprintf("the hosts the VMs of the vm placement group %s run on or not, no such vm host policy is applied if not set", verb),
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"policy": {
+					Type:         schema.TypeString,
+					Required:     true,
+					Description:  fmt.Sprintf("RUN_ON if the VMs %s run on one of the hosts, NOT_RUN_ON if they %s not", verb, verb),
+					ValidateFunc: validation.StringInSlice([]string{"RUN_ON", "NOT_RUN_ON"}, false),
+				},
+				"host_ids": {
+					Type:        schema.TypeSet,
+					Required:    true,
+					MinItems:    1,
+					Elem:        &schema.Schema{Type: schema.TypeString},
+					Description: "the ids of the hosts of the policy",
+				},
+			},
+		},
+	}
+}
+
+// VmPlacementGroupConfig is the policies and VMs of a vm placement group
+type VmPlacementGroupConfig struct {
+	Name              string
+	Description       string
+	Enabled           bool
+	VmIds             []string
+	VmVmPolicyEnabled bool
+	VmVmPolicy        *models.VMVMPolicy
+	MustEnabled       bool
+	MustRunOn         bool
+	MustHostIds       []string
+	PreferEnabled     bool
+	PreferRunOn       bool
+	PreferHostIds     []string
+}
+
+func expandVmPlacementGroupConfig(d *schema.ResourceData) *VmPlacementGroupConfig {
+	config := &VmPlacementGroupConfig{
+		Name:          d.Get("name").(string),
+		Description:   d.Get("description").(string),
+		Enabled:       d.Get("enabled").(bool),
+		VmIds:         expandStringSet(d.Get("vm_ids")),
+		MustHostIds:   make([]string, 0),
+		PreferHostIds: make([]string, 0),
+	}
+	if policy := d.Get("vm_vm_policy").(string); policy != "" {
+		config.VmVmPolicyEnabled = true
+		config.VmVmPolicy = models.VMVMPolicy(policy).Pointer()
+	}
+	if policies := d.Get("vm_host_must_policy").([]interface{}); len(policies) > 0 {
+		policy := policies[0].(map[string]interface{})
+		config.MustEnabled = true
+		config.MustRunOn = policy["policy"].(string) == "RUN_ON"
+		config.MustHostIds = expandStringSet(policy["host_ids"])
+	}
+	if policies := d.Get("vm_host_prefer_policy").([]interface{}); len(policies) > 0 {
+		policy := policies[0].(map[string]interface{})
+		config.PreferEnabled = true
+		config.PreferRunOn = policy["policy"].(string) == "RUN_ON"
+		config.PreferHostIds = expandStringSet(policy["host_ids"])
+	}
+	return config
+}
+
+// vmWhereNone matches no VM, an empty id_in is left out of the request and would match every VM
+func vmWhereNone() *models.VMWhereInput {
+	return &models.VMWhereInput{IDIn: []string{""}}
+}
+
+func expandStringSet(set interface{}) []string {
+	items := set.(*schema.Set).List()
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		values = append(values, item.(string))
+	}
+	return values
+}
+
+func flattenVmHostPolicy(enabled *bool, runOn *bool, hosts []*models.NestedHost) []map[string]interface{} {
+	if enabled == nil || !*enabled {
+		return []map[string]interface{}{}
+	}
+	policy := "NOT_RUN_ON"
+	if runOn != nil && *runOn {
+		policy = "RUN_ON"
+	}
+	hostIds := make([]string, 0, len(hosts))
+	for _, h := range hosts {
+		hostIds = append(hostIds, *h.ID)
+	}
+	return []map[string]interface{}{{
+		"policy":   policy,
+		"host_ids": hostIds,
+	}}
+}
+
+func resourceVmPlacementGroupCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	ct := meta.(*cloudtower.Client)
+	clusterId := d.Get("cluster_id").(string)
+	config := expandVmPlacementGroupConfig(d)
+	cgp := vm_placement_group.NewCreateVMPlacementGroupParams()
+	cgp.RequestBody = []*models.VMPlacementGroupCreationParams{{
+		Name:                  &config.Name,
+		ClusterID:             &clusterId,
+		Description:           &config.Description,
+		Enabled:               &config.Enabled,
+		VMVMPolicyEnabled:     &config.VmVmPolicyEnabled,
+		VMVMPolicy:            config.VmVmPolicy,
+		VMHostMustEnabled:     &config.MustEnabled,
+		VMHostMustPolicy:      &config.MustRunOn,
+		VMHostMustHostUuids:   config.MustHostIds,
+		VMHostPreferEnabled:   &config.PreferEnabled,
+		VMHostPreferPolicy:    &config.PreferRunOn,
+		VMHostPreferHostUuids: config.PreferHostIds,
+	}}
+	if len(config.VmIds) > 0 {
+		cgp.RequestBody[0].Vms = &models.VMWhereInput{
+			IDIn: config.VmIds,
+		}
+	}
+	cgp.Context = ctx
+	groups, err := ct.Api.VMPlacementGroup.CreateVMPlacementGroup(cgp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(*groups.Payload[0].Data.ID)
+	_, err = ct.WaitTasksFinish(ctx, []string{*groups.Payload[0].TaskID})
+	if err != nil {
+		return diagFromTaskErr(err)
+	}
+
+	return resourceVmPlacementGroupRead(ctx, d, meta)
+}
+
+func resourceVmPlacementGroupRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	ct := meta.(*cloudtower.Client)
+
+	id := d.Id()
+	groups, err := getVmPlacementGroups(ctx, ct, &models.VMPlacementGroupWhereInput{
+		ID: &id,
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if len(groups) < 1 {
+		d.SetId("")
+		return diags
+	}
+	group := groups[0]
+	if err = d.Set("name", group.Name); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("cluster_id", group.Cluster.ID); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("description", group.Description); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("enabled", group.Enabled); err != nil {
+		return diag.FromErr(err)
+	}
+	vmIds := make([]string, 0, len(group.Vms))
+	for _, v := range group.Vms {
+		vmIds = append(vmIds, *v.ID)
+	}
+	if err = d.Set("vm_ids", vmIds); err != nil {
+		return diag.FromErr(err)
+	}
+	vmVmPolicy := ""
+	if group.VMVMPolicyEnabled != nil && *group.VMVMPolicyEnabled && group.VMVMPolicy != nil {
+		vmVmPolicy = string(*group.VMVMPolicy)
+	}
+	if err = d.Set("vm_vm_policy", vmVmPolicy); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("vm_host_must_policy", flattenVmHostPolicy(group.VMHostMustEnabled, group.VMHostMustPolicy, group.VMHostMustHostUuids)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("vm_host_prefer_policy", flattenVmHostPolicy(group.VMHostPreferEnabled, group.VMHostPreferPolicy, group.VMHostPreferHostUuids)); err != nil {
+		return diag.FromErr(err)
+	}
+	return diags
+}
+
+func resourceVmPlacementGroupUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	ct := meta.(*cloudtower.Client)
+	id := d.Id()
+	config := expandVmPlacementGroupConfig(d)
+	ugp := vm_placement_group.NewUpdateVMPlacementGroupParams()
+	ugp.RequestBody = &models.VMPlacementGroupUpdationParams{
+		Where: &models.VMPlacementGroupWhereInput{
+			ID: &id,
+		},
+		Data: &models.VMPlacementGroupUpdationParamsData{
+			Name:                  &config.Name,
+			Description:           &config.Description,
+			Enabled:               &config.Enabled,
+			VMVMPolicyEnabled:     &config.VmVmPolicyEnabled,
+			VMVMPolicy:            config.VmVmPolicy,
+			VMHostMustEnabled:     &config.MustEnabled,
+			VMHostMustPolicy:      &config.MustRunOn,
+			VMHostMustHostUuids:   config.MustHostIds,
+			VMHostPreferEnabled:   &config.PreferEnabled,
+			VMHostPreferPolicy:    &config.PreferRunOn,
+			VMHostPreferHostUuids: config.PreferHostIds,
+		},
+	}
+	if len(config.VmIds) > 0 {
+		ugp.RequestBody.Data.Vms = &models.VMWhereInput{
+			IDIn: config.VmIds,
+		}
+	} else if d.HasChange("vm_ids") {
+		ugp.RequestBody.Data.Vms = vmWhereNone()
+	}
+	ugp.Context = ctx
+	groups, err := ct.Api.VMPlacementGroup.UpdateVMPlacementGroup(ugp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	taskIds := make([]string, 0)
+	for _, g := range groups.Payload {
+		if g.TaskID != nil {
+			taskIds = append(taskIds, *g.TaskID)
+		}
+	}
+	_, err = ct.WaitTasksFinish(ctx, taskIds)
+	if err != nil {
+		return diagFromTaskErr(err)
+	}
+
+	return resourceVmPlacementGroupRead(ctx, d, meta)
+}
+
+func resourceVmPlacementGroupDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	ct := meta.(*cloudtower.Client)
+	id := d.Id()
+	dgp := vm_placement_group.NewDeleteVMPlacementGroupParams()
+	dgp.RequestBody = &models.VMPlacementGroupDeletionParams{
+		Where: &models.VMPlacementGroupWhereInput{
+			ID: &id,
+		},
+	}
+	dgp.Context = ctx
+	groups, err := ct.Api.VMPlacementGroup.DeleteVMPlacementGroup(dgp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	taskIds := make([]string, 0)
+	for _, g := range groups.Payload {
+		if g.TaskID != nil {
+			taskIds = append(taskIds, *g.TaskID)
+		}
+	}
+	_, err = ct.WaitTasksFinish(ctx, taskIds)
+	if err != nil {
+		return diagFromTaskErr(err)
+	}
+	d.SetId("")
+	return diags
+}
+
+func getVmPlacementGroups(ctx context.Context, ct *cloudtower.Client, where *models.VMPlacementGroupWhereInput) ([]*models.VMPlacementGroup, error) {
+	ggp := vm_placement_group.NewGetVMPlacementGroupsParams()
+	ggp.RequestBody = &models.GetVMPlacementGroupsRequestBody{
+		Where: where,
+	}
+	ggp.Context = ctx
+	groups, err := ct.Api.VMPlacementGroup.GetVMPlacementGroups(ggp)
+	if err != nil {
+		return nil, err
+	}
+	return groups.Payload, nil
+}
+
+// vmBreaksPlacementGroups tells whether the host of a VM breaks a must policy of the enabled
+// placement groups of the VM, the prefer policies are left to the scheduler of CloudTower
+func vmBreaksPlacementGroups(ctx context.Context, ct *cloudtower.Client, v *models.VM) (bool, error) {
+	if v.Host == nil {
+		return false, nil
+	}
+	enabled := true
+	groups, err := getVmPlacementGroups(ctx, ct, &models.VMPlacementGroupWhereInput{
+		Enabled: &enabled,
+		VmsSome: &models.VMWhereInput{
+			ID: v.ID,
+		},
+	})
+	if err != nil || len(groups) == 0 {
+		return false, err
+	}
+	hostId := *v.Host.ID
+	memberIds := make([]string, 0)
+	for _, g := range groups {
+		if g.VMHostMustEnabled != nil && *g.VMHostMustEnabled && g.VMHostMustPolicy != nil {
+			inHosts := false
+			for _, h := range g.VMHostMustHostUuids {
+				inHosts = inHosts || *h.ID == hostId
+			}
+			if inHosts != *g.VMHostMustPolicy {
+				return true, nil
+			}
+		}
+		for _, m := range g.Vms {
+			if *m.ID != *v.ID {
+				memberIds = append(memberIds, *m.ID)
+			}
+		}
+	}
+	if len(memberIds) == 0 {
+		return false, nil
+	}
+	gvp := vm.NewGetVmsParams()
+	gvp.RequestBody = &models.GetVmsRequestBody{
+		Where: &models.VMWhereInput{
+			IDIn: memberIds,
+		},
+	}
+	gvp.Context = ctx
+	members, err := ct.Api.VM.GetVms(gvp)
+	if err != nil {
+		return false, err
+	}
+	memberHosts := make(map[string]string)
+	for _, m := range members.Payload {
+		if m.Host != nil {
+			memberHosts[*m.ID] = *m.Host.ID
+		}
+	}
+	for _, g := range groups {
+		if g.VMVMPolicyEnabled == nil || !*g.VMVMPolicyEnabled || g.VMVMPolicy == nil {
+			continue
+		}
+		for _, m := range g.Vms {
+			memberHost, ok := memberHosts[*m.ID]
+			if *m.ID == *v.ID || !ok {
+				continue
+			}
+			switch *g.VMVMPolicy {
+			case models.VMVMPolicyMUSTSAME:
+				if memberHost != hostId {
+					return true, nil
+				}
+			case models.VMVMPolicyMUSTDIFFERENT:
+				if memberHost == hostId {
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+func vmPlacementGroupImportLookup(ctx context.Context, meta interface{}) *importLookup {
+	ct := meta.(*cloudtower.Client)
+	find := func(where *models.VMPlacementGroupWhereInput) ([]string, error) {
+		groups, err := getVmPlacementGroups(ctx, ct, where)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, 0, len(groups))
+		for _, g := range groups {
+			ids = append(ids, *g.ID)
+		}
+		return ids, nil
+	}
+	return &importLookup{
+		kind:   "VM placement group",
+		format: "[cluster_name/]group_name",
+		byId: func(id string) ([]string, error) {
+			return find(&models.VMPlacementGroupWhereInput{
+				ID: &id,
+			})
+		},
+		byName: func(names []string) ([]string, error) {
+			where := &models.VMPlacementGroupWhereInput{
+				Name: namesAt(names, 0),
+			}
+			if clusterName := namesAt(names, 1); clusterName != nil {
+				where.Cluster = &models.ClusterWhereInput{
+					Name: clusterName,
+				}
+			}
+			return find(where)
+		},
+	}
+}
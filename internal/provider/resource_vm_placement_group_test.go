@@ -0,0 +1,133 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/fake"
+)
+
+// testAccAddHosts adds n healthy hosts to the cluster of the fixtures, the host of the
+// fixtures comes first in the returned ids
+func testAccAddHosts(srv *fake.Server, n int) []string {
+	hostIds := []string{srv.Fixtures.HostId}
+	for i := 1; i <= n; i++ {
+		hostIds = append(hostIds, srv.Insert("hosts", map[string]any{
+			"name":    fmt.Sprintf("%s-host-%d", srv.Fixtures.ClusterName, i),
+			"status":  "CONNECTED_HEALTHY",
+			"cluster": map[string]any{"id": srv.Fixtures.ClusterId, "name": srv.Fixtures.ClusterName},
+		}))
+	}
+	return hostIds
+}
+
+func TestAccResourceVmPlacementGroup(t *testing.T) {
+	srv := fake.NewServer(t)
+	hostIds := testAccAddHosts(srv, 2)
+	name := "cloudtower_vm_placement_group.test"
+	vms := testAccVmConfig(srv, "tf-acc-vm-a") + testAccVmConfig(srv, "tf-acc-vm-b")
+	configVms := func(vmIds string, policies string) string {
+		return srv.ProviderConfig() + vms + fmt.Sprintf(`
+resource "cloudtower_vm_placement_group" "test" {
+  name       = "tf-acc-placement-group"
+  cluster_id = %q
+%s
+%s
+}
+`, srv.Fixtures.ClusterId, vmIds, policies)
+	}
+	config := func(policies string) string {
+		return configVms(`  vm_ids     = [cloudtower_vm.tf-acc-vm-a.id, cloudtower_vm.tf-acc-vm-b.id]`, policies)
+	}
+	groupVms := func(want int) resource.TestCheckFunc {
+		return func(s *terraform.State) error {
+			id := s.RootModule().Resources[name].Primary.ID
+			if vms := srv.Get("vm-placement-groups", id)["vms"].([]any); len(vms) != want {
+				return fmt.Errorf("vm placement group %s has %d vms, want %d", id, len(vms), want)
+			}
+			return nil
+		}
+	}
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckDestroy(srv, "cloudtower_vm_placement_group", "vm-placement-groups"),
+		Steps: []resource.TestStep{
+			{
+				Config: config(fmt.Sprintf(`
+  vm_vm_policy = "MUST_DIFFERENT"
+
+  vm_host_prefer_policy {
+    policy   = "RUN_ON"
+    host_ids = [%q]
+  }
+`, hostIds[2])),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(srv, name, "vm-placement-groups"),
+					resource.TestCheckResourceAttr(name, "enabled", "true"),
+					resource.TestCheckResourceAttr(name, "vm_ids.#", "2"),
+					resource.TestCheckResourceAttr(name, "vm_vm_policy", "MUST_DIFFERENT"),
+					resource.TestCheckResourceAttr(name, "vm_host_must_policy.#", "0"),
+					resource.TestCheckResourceAttr(name, "vm_host_prefer_policy.0.policy", "RUN_ON"),
+					resource.TestCheckTypeSetElemAttr(name, "vm_host_prefer_policy.0.host_ids.*", hostIds[2]),
+				),
+			},
+			{
+				Config: config(fmt.Sprintf(`
+  vm_vm_policy = "PREFER_SAME"
+
+  vm_host_must_policy {
+    policy   = "NOT_RUN_ON"
+    host_ids = [%q]
+  }
+`, hostIds[1])),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "vm_vm_policy", "PREFER_SAME"),
+					resource.TestCheckResourceAttr(name, "vm_host_must_policy.0.policy", "NOT_RUN_ON"),
+					resource.TestCheckTypeSetElemAttr(name, "vm_host_must_policy.0.host_ids.*", hostIds[1]),
+					resource.TestCheckResourceAttr(name, "vm_host_prefer_policy.#", "0"),
+				),
+			},
+			{
+				// no vm_ids clears the VMs of the group instead of adding every VM
+				Config: configVms("", ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "vm_ids.#", "0"),
+					groupVms(0),
+				),
+			},
+			{
+				ResourceName:      name,
+				ImportState:       true,
+				ImportStateId:     srv.Fixtures.ClusterName + "/tf-acc-placement-group",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccResourceVmPlacementGroup_noVms(t *testing.T) {
+	srv := fake.NewServer(t)
+	name := "cloudtower_vm_placement_group.test"
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckDestroy(srv, "cloudtower_vm_placement_group", "vm-placement-groups"),
+		Steps: []resource.TestStep{
+			{
+				// a group created without vm_ids has no VMs, not every VM of the cluster
+				Config: srv.ProviderConfig() + testAccVmConfig(srv, "tf-acc-vm") + fmt.Sprintf(`
+resource "cloudtower_vm_placement_group" "test" {
+  name         = "tf-acc-placement-group"
+  cluster_id   = %q
+  vm_vm_policy = "MUST_DIFFERENT"
+  depends_on   = [cloudtower_vm.tf-acc-vm]
+}
+`, srv.Fixtures.ClusterId),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "vm_ids.#", "0"),
+				),
+			},
+		},
+	})
+}
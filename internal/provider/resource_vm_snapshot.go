@@ -3,6 +3,7 @@ package provider
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-provider-cloudtower/internal/cloudtower"
@@ -21,6 +22,13 @@ func resourceVmSnapshot() *schema.Resource {
 		CreateContext: resourceVmSnapshotCreate,
 		ReadContext:   resourceVmSnapshotRead,
 		DeleteContext: resourceVmSnapshotDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importState(vmSnapshotImportLookup),
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(20 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
 
 		Schema: map[string]*schema.Schema{
 			"vm_id": {
@@ -181,6 +189,7 @@ func resourceVmSnapshotCreate(ctx context.Context, d *schema.ResourceData, meta
 			},
 		},
 	}
+	cvsp.Context = ctx
 	snapshots, err := ct.Api.VMSnapshot.CreateVMSnapshot(cvsp)
 	if err != nil {
 		return diag.FromErr(err)
@@ -188,7 +197,7 @@ func resourceVmSnapshotCreate(ctx context.Context, d *schema.ResourceData, meta
 	d.SetId(*snapshots.Payload[0].Data.ID)
 	_, err = ct.WaitTasksFinish(ctx, []string{*snapshots.Payload[0].TaskID})
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromTaskErr(err)
 	}
 
 	return resourceVmSnapshotRead(ctx, d, meta)
@@ -205,6 +214,7 @@ func resourceVmSnapshotRead(ctx context.Context, d *schema.ResourceData, meta in
 			ID: &id,
 		},
 	}
+	gvsp.Context = ctx
 	vmSnapshots, err := ct.Api.VMSnapshot.GetVMSnapshots(gvsp)
 	if err != nil {
 		return diag.FromErr(err)
@@ -214,6 +224,14 @@ func resourceVmSnapshotRead(ctx context.Context, d *schema.ResourceData, meta in
 		return diags
 	}
 	snapshot := vmSnapshots.Payload[0]
+	if err = d.Set("name", snapshot.Name); err != nil {
+		return diag.FromErr(err)
+	}
+	if snapshot.VM != nil {
+		if err = d.Set("vm_id", snapshot.VM.ID); err != nil {
+			return diag.FromErr(err)
+		}
+	}
 	var disks []map[string]interface{} = make([]map[string]interface{}, 0)
 	var cdroms []map[string]interface{} = make([]map[string]interface{}, 0)
 	for _, disk := range snapshot.VMDisks {
@@ -293,6 +311,7 @@ func resourceVmSnapshotDelete(ctx context.Context, d *schema.ResourceData, meta
 			ID: &id,
 		},
 	}
+	dvsp.Context = ctx
 	snapshots, err := ct.Api.VMSnapshot.DeleteVMSnapshot(dvsp)
 	if err != nil {
 		return diag.FromErr(err)
@@ -305,8 +324,53 @@ func resourceVmSnapshotDelete(ctx context.Context, d *schema.ResourceData, meta
 	}
 	_, err = ct.WaitTasksFinish(ctx, taskIds)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromTaskErr(err)
 	}
 	d.SetId("")
 	return diags
 }
+
+func vmSnapshotImportLookup(ctx context.Context, meta interface{}) *importLookup {
+	ct := meta.(*cloudtower.Client)
+	find := func(where *models.VMSnapshotWhereInput) ([]string, error) {
+		gvsp := vm_snapshot.NewGetVMSnapshotsParams()
+		gvsp.RequestBody = &models.GetVMSnapshotsRequestBody{
+			Where: where,
+		}
+		gvsp.Context = ctx
+		snapshots, err := ct.Api.VMSnapshot.GetVMSnapshots(gvsp)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, 0, len(snapshots.Payload))
+		for _, s := range snapshots.Payload {
+			ids = append(ids, *s.ID)
+		}
+		return ids, nil
+	}
+	return &importLookup{
+		kind:   "VM snapshot",
+		format: "[[cluster_name/]vm_name/]snapshot_name",
+		byId: func(id string) ([]string, error) {
+			return find(&models.VMSnapshotWhereInput{
+				ID: &id,
+			})
+		},
+		byName: func(names []string) ([]string, error) {
+			where := &models.VMSnapshotWhereInput{
+				Name: namesAt(names, 0),
+			}
+			if vmName := namesAt(names, 1); vmName != nil {
+				where.VM = &models.VMWhereInput{
+					Name: vmName,
+				}
+				if clusterName := namesAt(names, 2); clusterName != nil {
+					where.VM.Cluster = &models.ClusterWhereInput{
+						Name: clusterName,
+					}
+				}
+			}
+			return find(where)
+		},
+	}
+}
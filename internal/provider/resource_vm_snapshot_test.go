@@ -0,0 +1,47 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/fake"
+)
+
+func TestAccResourceVmSnapshot(t *testing.T) {
+	srv := fake.NewServer(t)
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckDestroy(srv, "cloudtower_vm_snapshot", "vm-snapshots"),
+		Steps: []resource.TestStep{
+			{
+				Config: srv.ProviderConfig() + testAccVmConfig(srv, "tf-acc-vm") + `
+resource "cloudtower_vm_snapshot" "test" {
+  name  = "tf-acc-snapshot"
+  vm_id = cloudtower_vm.tf-acc-vm.id
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(srv, "cloudtower_vm_snapshot.test", "vm-snapshots"),
+					resource.TestCheckResourceAttr("cloudtower_vm_snapshot.test", "consistent_type", "CRASH_CONSISTENT"),
+					resource.TestCheckResourceAttr("cloudtower_vm_snapshot.test", "disks.#", "1"),
+					resource.TestCheckResourceAttr("cloudtower_vm_snapshot.test", "disks.0.storage_policy", "REPLICA_2_THIN_PROVISION"),
+					resource.TestCheckResourceAttr("cloudtower_vm_snapshot.test", "cd_roms.#", "1"),
+					resource.TestCheckResourceAttr("cloudtower_vm_snapshot.test", "cd_roms.0.elf_image_id", srv.Fixtures.ElfImageId),
+					resource.TestCheckResourceAttr("cloudtower_vm_snapshot.test", "nics.#", "1"),
+					resource.TestCheckResourceAttr("cloudtower_vm_snapshot.test", "nics.0.vlan_id", srv.Fixtures.VlanId),
+				),
+			},
+			{
+				ResourceName:      "cloudtower_vm_snapshot.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:      "cloudtower_vm_snapshot.test",
+				ImportState:       true,
+				ImportStateId:     "tf-acc-vm/tf-acc-snapshot",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
@@ -3,6 +3,7 @@ package provider
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/hashicorp/terraform-provider-cloudtower/internal/cloudtower"
 	"github.com/hashicorp/terraform-provider-cloudtower/internal/helper"
@@ -21,6 +22,15 @@ func resourceVmTemplate() *schema.Resource {
 		ReadContext:   resourceVmTemplateRead,
 		DeleteContext: resourceVmTemplateDelete,
 		UpdateContext: resourceVmTemplateUpdate,
+		Importer: &schema.ResourceImporter{
+			StateContext: importState(vmTemplateImportLookup),
+		},
+		CustomizeDiff: labelsCustomizeDiff,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
 
 		Schema: map[string]*schema.Schema{
 			// "create_effect": {
@@ -45,9 +55,10 @@ func resourceVmTemplate() *schema.Resource {
 			// 	},
 			// },
 			"src_vm_id": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Id of source vm from created vm to be cloned from",
+				Type:             schema.TypeString,
+				Required:         true,
+				DiffSuppressFunc: suppressAfterCreate,
+				Description:      "Id of source vm from created vm to be cloned from, changes are ignored once the template is created",
 			},
 			"name": {
 				Type:        schema.TypeString,
@@ -66,6 +77,8 @@ func resourceVmTemplate() *schema.Resource {
 				Description: "VM template's description",
 				Optional:    true,
 			},
+			"labels":     labelsSchema("VM template"),
+			"labels_all": labelsAllSchema("VM template"),
 			"id": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -225,6 +238,7 @@ func resourceVmTemplateCreate(ctx context.Context, d *schema.ResourceData, meta
 				CloudInitSupported: &cloudInitSupported,
 			},
 		}
+		cvtfv.Context = ctx
 		response, err := ct.Api.VMTemplate.CloneVMTemplateFromVM(cvtfv)
 		if err != nil {
 			return diag.FromErr(err)
@@ -236,7 +250,11 @@ func resourceVmTemplateCreate(ctx context.Context, d *schema.ResourceData, meta
 	d.SetId(*templates[0].Data.ID)
 	_, err := ct.WaitTasksFinish(ctx, []string{*templates[0].TaskID})
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromTaskErr(err)
+	}
+	err = updateLabels(ctx, d, ct, labelTarget{vmTemplate: &models.VMTemplateWhereInput{ID: templates[0].Data.ID}})
+	if err != nil {
+		return diagFromTaskErr(err)
 	}
 	return resourceVmTemplateRead(ctx, d, meta)
 }
@@ -252,6 +270,7 @@ func resourceVmTemplateRead(ctx context.Context, d *schema.ResourceData, meta in
 			ID: &id,
 		},
 	}
+	gvtp.Context = ctx
 	vmTemplates, err := ct.Api.VMTemplate.GetVMTemplates(gvtp)
 	if err != nil {
 		return diag.FromErr(err)
@@ -264,6 +283,15 @@ func resourceVmTemplateRead(ctx context.Context, d *schema.ResourceData, meta in
 	if err = d.Set("name", template.Name); err != nil {
 		diags = append(diags, diag.FromErr(err)...)
 	}
+	if err = d.Set("description", template.Description); err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+	}
+	if err = d.Set("cloud_init_supported", template.CloudInitSupported); err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+	}
+	if err = setLabels(ctx, d, ct, template.Labels); err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+	}
 	var disks []map[string]interface{} = make([]map[string]interface{}, 0)
 	var cdroms []map[string]interface{} = make([]map[string]interface{}, 0)
 	for _, disk := range template.VMDisks {
@@ -343,6 +371,7 @@ func resourceVmTemplateDelete(ctx context.Context, d *schema.ResourceData, meta
 			ID: &id,
 		},
 	}
+	dvtp.Context = ctx
 	templates, err := ct.Api.VMTemplate.DeleteVMTemplate(dvtp)
 	if err != nil {
 		return diag.FromErr(err)
@@ -355,7 +384,7 @@ func resourceVmTemplateDelete(ctx context.Context, d *schema.ResourceData, meta
 	}
 	_, err = ct.WaitTasksFinish(ctx, taskIds)
 	if err != nil {
-		return diag.FromErr(err)
+		return diagFromTaskErr(err)
 	}
 	d.SetId("")
 	return diags
@@ -363,24 +392,73 @@ func resourceVmTemplateDelete(ctx context.Context, d *schema.ResourceData, meta
 
 func resourceVmTemplateUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	ct := meta.(*cloudtower.Client)
-	uvtp := vm_template.NewUpdateVMTemplateParams()
-	name := d.Get("name").(string)
-	description := d.Get("description").(string)
-	cloudInitSupported := d.Get("cloud_init_supported").(bool)
 	id := d.Id()
-	uvtp.RequestBody = &models.VMTemplateUpdationParams{
-		Where: &models.VMTemplateWhereInput{
-			ID: &id,
-		},
-		Data: &models.VMTemplateUpdationParamsData{
-			Name:               &name,
-			Description:        &description,
-			CloudInitSupported: &cloudInitSupported,
-		},
+	if d.HasChange("description") {
+		uvtp := vm_template.NewUpdateVMTemplateParams()
+		name := d.Get("name").(string)
+		description := d.Get("description").(string)
+		cloudInitSupported := d.Get("cloud_init_supported").(bool)
+		uvtp.RequestBody = &models.VMTemplateUpdationParams{
+			Where: &models.VMTemplateWhereInput{
+				ID: &id,
+			},
+			Data: &models.VMTemplateUpdationParamsData{
+				Name:               &name,
+				Description:        &description,
+				CloudInitSupported: &cloudInitSupported,
+			},
+		}
+		uvtp.Context = ctx
+		_, err := ct.Api.VMTemplate.UpdateVMTemplate(uvtp)
+		if err != nil {
+			return diag.FromErr(err)
+		}
 	}
-	_, err := ct.Api.VMTemplate.UpdateVMTemplate(uvtp)
-	if err != nil {
-		return diag.FromErr(err)
+	if d.HasChange("labels_all") {
+		err := updateLabels(ctx, d, ct, labelTarget{vmTemplate: &models.VMTemplateWhereInput{ID: &id}})
+		if err != nil {
+			return diagFromTaskErr(err)
+		}
 	}
 	return resourceVmTemplateRead(ctx, d, meta)
 }
+
+func vmTemplateImportLookup(ctx context.Context, meta interface{}) *importLookup {
+	ct := meta.(*cloudtower.Client)
+	find := func(where *models.VMTemplateWhereInput) ([]string, error) {
+		gvtp := vm_template.NewGetVMTemplatesParams()
+		gvtp.RequestBody = &models.GetVMTemplatesRequestBody{
+			Where: where,
+		}
+		gvtp.Context = ctx
+		templates, err := ct.Api.VMTemplate.GetVMTemplates(gvtp)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, 0, len(templates.Payload))
+		for _, t := range templates.Payload {
+			ids = append(ids, *t.ID)
+		}
+		return ids, nil
+	}
+	return &importLookup{
+		kind:   "VM template",
+		format: "[cluster_name/]template_name",
+		byId: func(id string) ([]string, error) {
+			return find(&models.VMTemplateWhereInput{
+				ID: &id,
+			})
+		},
+		byName: func(names []string) ([]string, error) {
+			where := &models.VMTemplateWhereInput{
+				Name: namesAt(names, 0),
+			}
+			if clusterName := namesAt(names, 1); clusterName != nil {
+				where.Cluster = &models.ClusterWhereInput{
+					Name: clusterName,
+				}
+			}
+			return find(where)
+		},
+	}
+}
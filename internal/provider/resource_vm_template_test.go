@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/fake"
+)
+
+func TestAccResourceVmTemplate(t *testing.T) {
+	srv := fake.NewServer(t)
+	config := func(description string) string {
+		return srv.ProviderConfig() + testAccVmConfig(srv, "tf-acc-vm") + fmt.Sprintf(`
+resource "cloudtower_vm_template" "test" {
+  name                 = "tf-acc-template"
+  src_vm_id            = cloudtower_vm.tf-acc-vm.id
+  cloud_init_supported = false
+  description          = %q
+}
+`, description)
+	}
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckDestroy(srv, "cloudtower_vm_template", "vm-templates"),
+		Steps: []resource.TestStep{
+			{
+				Config: config("created by acceptance tests"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(srv, "cloudtower_vm_template.test", "vm-templates"),
+					resource.TestCheckResourceAttr("cloudtower_vm_template.test", "name", "tf-acc-template"),
+					resource.TestCheckResourceAttr("cloudtower_vm_template.test", "disks.#", "1"),
+					resource.TestCheckResourceAttr("cloudtower_vm_template.test", "cd_roms.#", "1"),
+					resource.TestCheckResourceAttr("cloudtower_vm_template.test", "nics.#", "1"),
+				),
+			},
+			{
+				Config: config("updated by acceptance tests"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudtower_vm_template.test", "description", "updated by acceptance tests"),
+				),
+			},
+			{
+				ResourceName:            "cloudtower_vm_template.test",
+				ImportState:             true,
+				ImportStateId:           "tf-acc-template",
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"src_vm_id"},
+			},
+		},
+	})
+}
+
+func TestAccResourceVm_cloneFromTemplate(t *testing.T) {
+	srv := fake.NewServer(t)
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckDestroy(srv, "cloudtower_vm", "vms"),
+		Steps: []resource.TestStep{
+			{
+				Config: srv.ProviderConfig() + testAccVmConfig(srv, "tf-acc-vm") + `
+resource "cloudtower_vm_template" "test" {
+  name                 = "tf-acc-template"
+  src_vm_id            = cloudtower_vm.tf-acc-vm.id
+  cloud_init_supported = false
+}
+
+resource "cloudtower_vm" "from_template" {
+  name = "tf-acc-from-template"
+  create_effect {
+    clone_from_template = cloudtower_vm_template.test.id
+    is_full_copy        = true
+  }
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(srv, "cloudtower_vm.from_template", "vms"),
+					resource.TestCheckResourceAttr("cloudtower_vm.from_template", "cluster_id", srv.Fixtures.ClusterId),
+					resource.TestCheckResourceAttr("cloudtower_vm.from_template", "disk.#", "1"),
+					resource.TestCheckResourceAttr("cloudtower_vm.from_template", "cd_rom.#", "1"),
+					resource.TestCheckResourceAttr("cloudtower_vm.from_template", "nic.#", "1"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,620 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/fake"
+)
+
+func TestAccResourceVm(t *testing.T) {
+	srv := fake.NewServer(t)
+	config := srv.ProviderConfig() + testAccVmConfig(srv, "tf-acc-vm")
+	updated := strings.NewReplacer(
+		`name       = "tf-acc-vm"`, `name       = "tf-acc-vm-renamed"`,
+		`vcpu       = 2`, `vcpu       = 4`,
+		`status     = "STOPPED"`, `status     = "RUNNING"`,
+	).Replace(config)
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckDestroy(srv, "cloudtower_vm", "vms"),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(srv, "cloudtower_vm.tf-acc-vm", "vms"),
+					resource.TestCheckResourceAttr("cloudtower_vm.tf-acc-vm", "name", "tf-acc-vm"),
+					resource.TestCheckResourceAttr("cloudtower_vm.tf-acc-vm", "status", "STOPPED"),
+					resource.TestCheckResourceAttr("cloudtower_vm.tf-acc-vm", "cpu_cores", "1"),
+					resource.TestCheckResourceAttr("cloudtower_vm.tf-acc-vm", "cpu_sockets", "2"),
+					resource.TestCheckResourceAttr("cloudtower_vm.tf-acc-vm", "host_id", srv.Fixtures.HostId),
+					resource.TestCheckResourceAttr("cloudtower_vm.tf-acc-vm", "disk.#", "1"),
+					resource.TestCheckResourceAttr("cloudtower_vm.tf-acc-vm", "disk.0.vm_volume.0.size", "10737418240"),
+					resource.TestCheckResourceAttr("cloudtower_vm.tf-acc-vm", "disk.0.vm_volume.0.storage_policy", "REPLICA_2_THIN_PROVISION"),
+					resource.TestCheckResourceAttr("cloudtower_vm.tf-acc-vm", "cd_rom.#", "1"),
+					resource.TestCheckResourceAttr("cloudtower_vm.tf-acc-vm", "cd_rom.0.iso_id", srv.Fixtures.ElfImageId),
+					resource.TestCheckResourceAttr("cloudtower_vm.tf-acc-vm", "nic.#", "1"),
+					resource.TestCheckResourceAttr("cloudtower_vm.tf-acc-vm", "nic.0.vlan_id", srv.Fixtures.VlanId),
+					resource.TestCheckResourceAttr("cloudtower_vm.tf-acc-vm", "nic.0.enabled", "true"),
+				),
+			},
+			{
+				ResourceName:      "cloudtower_vm.tf-acc-vm",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:      "cloudtower_vm.tf-acc-vm",
+				ImportState:       true,
+				ImportStateId:     srv.Fixtures.ClusterName + "/tf-acc-vm",
+				ImportStateVerify: true,
+			},
+			{
+				Config: updated,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudtower_vm.tf-acc-vm", "name", "tf-acc-vm-renamed"),
+					resource.TestCheckResourceAttr("cloudtower_vm.tf-acc-vm", "vcpu", "4"),
+					resource.TestCheckResourceAttr("cloudtower_vm.tf-acc-vm", "status", "RUNNING"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVm_cloneFromVm(t *testing.T) {
+	srv := fake.NewServer(t)
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckDestroy(srv, "cloudtower_vm", "vms"),
+		Steps: []resource.TestStep{
+			{
+				Config: srv.ProviderConfig() + testAccVmConfig(srv, "tf-acc-src") + `
+resource "cloudtower_vm" "clone" {
+  name = "tf-acc-clone"
+  create_effect {
+    clone_from_vm = cloudtower_vm.tf-acc-src.id
+  }
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(srv, "cloudtower_vm.clone", "vms"),
+					resource.TestCheckResourceAttrPair("cloudtower_vm.clone", "cluster_id", "cloudtower_vm.tf-acc-src", "cluster_id"),
+					resource.TestCheckResourceAttrPair("cloudtower_vm.clone", "vcpu", "cloudtower_vm.tf-acc-src", "vcpu"),
+					resource.TestCheckResourceAttr("cloudtower_vm.clone", "disk.#", "1"),
+					resource.TestCheckResourceAttr("cloudtower_vm.clone", "nic.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+// TestAccResourceVm_cloneCpuTopology checks a clone with only vcpu configured keeps the sockets of its source
+func TestAccResourceVm_cloneCpuTopology(t *testing.T) {
+	srv := fake.NewServer(t)
+	source := strings.Replace(testAccVmConfig(srv, "tf-acc-src"), `vcpu       = 2`, `vcpu       = 4
+  cpu_cores  = 2`, 1)
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckDestroy(srv, "cloudtower_vm", "vms"),
+		Steps: []resource.TestStep{
+			{
+				Config: srv.ProviderConfig() + source + `
+resource "cloudtower_vm" "clone" {
+  name = "tf-acc-clone"
+  vcpu = 6
+  create_effect {
+    clone_from_vm = cloudtower_vm.tf-acc-src.id
+  }
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudtower_vm.clone", "vcpu", "6"),
+					resource.TestCheckResourceAttr("cloudtower_vm.clone", "cpu_cores", "3"),
+					resource.TestCheckResourceAttr("cloudtower_vm.clone", "cpu_sockets", "2"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVm_taskFailure(t *testing.T) {
+	srv := fake.NewServer(t)
+	srv.FailNextTask("createVm", "not enough memory")
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      srv.ProviderConfig() + testAccVmConfig(srv, "tf-acc-vm"),
+				ExpectError: regexp.MustCompile("not enough memory"),
+			},
+		},
+	})
+}
+
+func TestAccResourceVm_cpuTopology(t *testing.T) {
+	srv := fake.NewServer(t)
+	name := "cloudtower_vm.tf-acc-vm"
+	config := srv.ProviderConfig() + testAccVmConfig(srv, "tf-acc-vm")
+	topology := func(cpu string) string {
+		return strings.Replace(config, `vcpu       = 2`, cpu, 1)
+	}
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckDestroy(srv, "cloudtower_vm", "vms"),
+		Steps: []resource.TestStep{
+			{
+				Config: topology(`vcpu        = 3
+  cpu_sockets = 2`),
+				ExpectError: regexp.MustCompile("vcpu 3 must be divisible by number of cpu sockets 2"),
+			},
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "vcpu", "2"),
+					resource.TestCheckResourceAttr(name, "cpu_cores", "1"),
+					resource.TestCheckResourceAttr(name, "cpu_sockets", "2"),
+				),
+			},
+			{
+				Config: topology(`vcpu       = 4
+  cpu_cores  = 2`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "vcpu", "4"),
+					resource.TestCheckResourceAttr(name, "cpu_cores", "2"),
+					resource.TestCheckResourceAttr(name, "cpu_sockets", "2"),
+				),
+			},
+			{
+				Config: topology(`vcpu       = 6
+  cpu_cores  = 4`),
+				ExpectError: regexp.MustCompile("vcpu 6 must be divisible by number of cpu cores 4"),
+			},
+			{
+				Config: topology(`vcpu        = 8
+  cpu_cores   = 2
+  cpu_sockets = 2`),
+				ExpectError: regexp.MustCompile("vcpu 8 does not match 2 cpu cores in 2 cpu sockets"),
+			},
+		},
+	})
+}
+
+func TestAccResourceVm_updateNics(t *testing.T) {
+	srv := fake.NewServer(t)
+	name := "cloudtower_vm.tf-acc-vm"
+	nic := `
+  nic {
+    vlan_id = "` + srv.Fixtures.VlanId + `"
+  }`
+	base := srv.ProviderConfig() + testAccVmConfig(srv, "tf-acc-vm")
+	withNics := func(nics ...string) string {
+		return strings.Replace(base, nic, strings.Join(nics, ""), 1)
+	}
+	e1000 := strings.Replace(nic, "vlan_id", `model   = "E1000"
+    vlan_id`, 1)
+	var firstId, firstMac, secondId, secondMac string
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckDestroy(srv, "cloudtower_vm", "vms"),
+		Steps: []resource.TestStep{
+			{
+				Config: withNics(nic, nic),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "nic.#", "2"),
+					resource.TestCheckResourceAttrWith(name, "nic.0.id", func(v string) error { firstId = v; return nil }),
+					resource.TestCheckResourceAttrWith(name, "nic.0.mac_address", func(v string) error { firstMac = v; return nil }),
+					resource.TestCheckResourceAttrWith(name, "nic.1.id", func(v string) error { secondId = v; return nil }),
+					resource.TestCheckResourceAttrWith(name, "nic.1.mac_address", func(v string) error { secondMac = v; return nil }),
+				),
+			},
+			{
+				// the second nic is updated in place and a third one is added
+				Config: withNics(nic, e1000, nic),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "nic.#", "3"),
+					resource.TestCheckResourceAttrPtr(name, "nic.0.id", &firstId),
+					resource.TestCheckResourceAttrPtr(name, "nic.0.mac_address", &firstMac),
+					resource.TestCheckResourceAttrPtr(name, "nic.1.id", &secondId),
+					resource.TestCheckResourceAttrPtr(name, "nic.1.mac_address", &secondMac),
+					resource.TestCheckResourceAttr(name, "nic.1.model", "E1000"),
+					resource.TestCheckResourceAttr(name, "nic.2.model", "VIRTIO"),
+				),
+			},
+			{
+				// only the removed nic is deleted
+				Config: withNics(nic, e1000),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "nic.#", "2"),
+					resource.TestCheckResourceAttrPtr(name, "nic.0.id", &firstId),
+					resource.TestCheckResourceAttrPtr(name, "nic.1.id", &secondId),
+					resource.TestCheckResourceAttrPtr(name, "nic.1.mac_address", &secondMac),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVm_expandDisk(t *testing.T) {
+	srv := fake.NewServer(t)
+	name := "cloudtower_vm.tf-acc-vm"
+	config := strings.Replace(srv.ProviderConfig()+testAccVmConfig(srv, "tf-acc-vm"),
+		`status     = "STOPPED"`, `status     = "RUNNING"`, 1)
+	expanded := strings.NewReplacer(
+		`size           = 10737418240`, `size           = 21474836480`,
+		`bus  = "VIRTIO"`, `bus  = "SCSI"`,
+	).Replace(config)
+	shrunk := strings.Replace(config, `size           = 10737418240`, `size           = 5368709120`, 1)
+	// the size is unknown when planned, it is not taken as a shrink
+	unknownSize := strings.Replace(expanded, `size           = 21474836480`, `size           = terraform_data.size.output`, 1) + `
+resource "terraform_data" "size" {
+  input = 32212254720
+}
+`
+	var diskId, volumeId string
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckDestroy(srv, "cloudtower_vm", "vms"),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrWith(name, "disk.0.id", func(v string) error { diskId = v; return nil }),
+					resource.TestCheckResourceAttrWith(name, "disk.0.vm_volume.0.id", func(v string) error { volumeId = v; return nil }),
+				),
+			},
+			{
+				Config: expanded,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPtr(name, "disk.0.id", &diskId),
+					resource.TestCheckResourceAttrPtr(name, "disk.0.vm_volume.0.id", &volumeId),
+					resource.TestCheckResourceAttr(name, "disk.0.vm_volume.0.size", "21474836480"),
+					resource.TestCheckResourceAttr(name, "disk.0.bus", "SCSI"),
+					resource.TestCheckResourceAttr(name, "status", "RUNNING"),
+				),
+			},
+			{
+				Config: unknownSize,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPtr(name, "disk.0.vm_volume.0.id", &volumeId),
+					resource.TestCheckResourceAttr(name, "disk.0.vm_volume.0.size", "32212254720"),
+				),
+			},
+			{
+				Config:      shrunk,
+				ExpectError: regexp.MustCompile("size can not shrink"),
+			},
+		},
+	})
+}
+
+func TestAccResourceVm_updateStoragePolicy(t *testing.T) {
+	srv := fake.NewServer(t)
+	name := "cloudtower_vm.tf-acc-vm"
+	config := srv.ProviderConfig() + testAccVmConfig(srv, "tf-acc-vm")
+	var volumeId string
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckDestroy(srv, "cloudtower_vm", "vms"),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check:  resource.TestCheckResourceAttrWith(name, "disk.0.vm_volume_id", func(v string) error { volumeId = v; return nil }),
+			},
+			{
+				Config: strings.Replace(config, `"REPLICA_2_THIN_PROVISION"`, `"REPLICA_3_THICK_PROVISION"`, 1),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPtr(name, "disk.0.vm_volume_id", &volumeId),
+					resource.TestCheckResourceAttr(name, "disk.0.vm_volume.0.storage_policy", "REPLICA_3_THICK_PROVISION"),
+					func(*terraform.State) error {
+						if policy := srv.Get("vm-volumes", volumeId)["elf_storage_policy"]; policy != "REPLICA_3_THICK_PROVISION" {
+							return fmt.Errorf("volume %s has storage policy %v", volumeId, policy)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVm_migrateAcrossCluster(t *testing.T) {
+	srv := fake.NewServer(t)
+	target := srv.AddCluster("tf-acc-target", "192.168.3.10")
+	name := "cloudtower_vm.tf-acc-vm"
+	config := srv.ProviderConfig() + testAccVmConfig(srv, "tf-acc-vm")
+	policyChanged := strings.Replace(config, `"REPLICA_2_THIN_PROVISION"`, `"REPLICA_3_THIN_PROVISION"`, 1)
+	migrated := strings.NewReplacer(
+		srv.Fixtures.ClusterId, target.ClusterId,
+		srv.Fixtures.VlanId, target.VlanId,
+		`ha         = true`, `ha         = true
+  host_id    = "`+target.HostId+`"`,
+	).Replace(policyChanged)
+	// the nic and the disk are updated along with the migration back, which recreates them
+	migratedBack := strings.NewReplacer(
+		`vlan_id = "`+srv.Fixtures.VlanId+`"`, `vlan_id = "`+srv.Fixtures.VlanId+`"
+    model   = "E1000"`,
+		`bus  = "VIRTIO"`, `bus  = "SCSI"`,
+	).Replace(config)
+	var vmId, macAddress, diskId string
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckDestroy(srv, "cloudtower_vm", "vms"),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrWith(name, "id", func(v string) error { vmId = v; return nil }),
+					resource.TestCheckResourceAttrWith(name, "nic.0.mac_address", func(v string) error { macAddress = v; return nil }),
+				),
+			},
+			{
+				Config: migrated,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPtr(name, "id", &vmId),
+					resource.TestCheckResourceAttr(name, "cluster_id", target.ClusterId),
+					resource.TestCheckResourceAttr(name, "host_id", target.HostId),
+					resource.TestCheckResourceAttr(name, "nic.#", "1"),
+					resource.TestCheckResourceAttr(name, "nic.0.vlan_id", target.VlanId),
+					resource.TestCheckResourceAttrPtr(name, "nic.0.mac_address", &macAddress),
+					resource.TestCheckResourceAttr(name, "disk.0.vm_volume.0.storage_policy", "REPLICA_3_THIN_PROVISION"),
+					resource.TestCheckResourceAttrWith(name, "disk.0.id", func(v string) error { diskId = v; return nil }),
+				),
+			},
+			{
+				Config: migratedBack,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPtr(name, "id", &vmId),
+					resource.TestCheckResourceAttr(name, "cluster_id", srv.Fixtures.ClusterId),
+					resource.TestCheckResourceAttr(name, "nic.#", "1"),
+					resource.TestCheckResourceAttr(name, "nic.0.vlan_id", srv.Fixtures.VlanId),
+					resource.TestCheckResourceAttr(name, "nic.0.model", "E1000"),
+					resource.TestCheckResourceAttrPtr(name, "nic.0.mac_address", &macAddress),
+					resource.TestCheckResourceAttr(name, "disk.#", "1"),
+					resource.TestCheckResourceAttr(name, "disk.0.bus", "SCSI"),
+					func(*terraform.State) error {
+						if srv.Get("vm-disks", diskId) != nil {
+							return fmt.Errorf("vm disk %s was not recreated by the migration", diskId)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+// testAccVmWaitForGuestConfig is the VM of testAccVmConfig running and waiting for its guest
+func testAccVmWaitForGuestConfig(srv *fake.Server, name string, waitForGuest string) string {
+	return strings.Replace(testAccVmConfig(srv, name), `status     = "STOPPED"`, `status     = "RUNNING"
+
+  wait_for_guest {
+`+waitForGuest+`
+  }`, 1)
+}
+
+func TestAccResourceVm_waitForGuest(t *testing.T) {
+	srv := fake.NewServer(t)
+	srv.GuestTools = true
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckDestroy(srv, "cloudtower_vm", "vms"),
+		Steps: []resource.TestStep{
+			{
+				Config: srv.ProviderConfig() + testAccVmWaitForGuestConfig(srv, "tf-acc-vm", `    ip_address = true`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(srv, "cloudtower_vm.tf-acc-vm", "vms"),
+					resource.TestCheckResourceAttr("cloudtower_vm.tf-acc-vm", "status", "RUNNING"),
+					resource.TestCheckResourceAttr("cloudtower_vm.tf-acc-vm", "hostname", "tf-acc-vm"),
+				),
+			},
+		},
+	})
+}
+
+// TestAccResourceVm_guestNotReady checks creating a VM whose guest is not ready in time fails
+func TestAccResourceVm_guestNotReady(t *testing.T) {
+	srv := fake.NewServer(t)
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckDestroy(srv, "cloudtower_vm", "vms"),
+		Steps: []resource.TestStep{
+			{
+				Config: srv.ProviderConfig() + testAccVmWaitForGuestConfig(srv, "tf-acc-vm", `    ip_address = true
+    timeout    = "3s"`),
+				ExpectError: regexp.MustCompile("Guest of VM .* is not ready"),
+			},
+		},
+	})
+}
+
+func TestAccResourceVm_moveFolder(t *testing.T) {
+	srv := fake.NewServer(t)
+	name := "cloudtower_vm.tf-acc-vm"
+	folders := fmt.Sprintf(`
+resource "cloudtower_vm_folder" "a" {
+  name       = "tf-acc-folder-a"
+  cluster_id = %[1]q
+}
+
+resource "cloudtower_vm_folder" "b" {
+  name       = "tf-acc-folder-b"
+  cluster_id = %[1]q
+}
+`, srv.Fixtures.ClusterId)
+	inFolder := func(folder string) string {
+		return srv.ProviderConfig() + folders + strings.Replace(testAccVmConfig(srv, "tf-acc-vm"),
+			`ha         = true`, `ha         = true
+  folder_id  = cloudtower_vm_folder.`+folder+`.id`, 1)
+	}
+	var vmId string
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckDestroy(srv, "cloudtower_vm", "vms"),
+		Steps: []resource.TestStep{
+			{
+				Config: inFolder("a"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrWith(name, "id", func(v string) error { vmId = v; return nil }),
+					resource.TestCheckResourceAttrPair(name, "folder_id", "cloudtower_vm_folder.a", "id"),
+				),
+			},
+			{
+				Config: inFolder("b"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPtr(name, "id", &vmId),
+					resource.TestCheckResourceAttrPair(name, "folder_id", "cloudtower_vm_folder.b", "id"),
+				),
+			},
+			{
+				// the VM taken out of its folder outside of terraform is moved back
+				PreConfig: func() { srv.Update("vms", vmId, map[string]any{"folder": nil}) },
+				Config:    inFolder("b"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPtr(name, "id", &vmId),
+					resource.TestCheckResourceAttrPair(name, "folder_id", "cloudtower_vm_folder.b", "id"),
+					func(*terraform.State) error {
+						if folder := srv.Get("vms", vmId)["folder"]; folder == nil {
+							return fmt.Errorf("vm %s is not in any folder", vmId)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceVm_labels(t *testing.T) {
+	srv := fake.NewServer(t)
+	name := "cloudtower_vm.tf-acc-vm"
+	provider := strings.Replace(srv.ProviderConfig(), `task_poll_interval = 1`, `task_poll_interval = 1
+  default_labels     = { env = "test" }`, 1)
+	withLabels := func(labels string) string {
+		return provider + strings.Replace(testAccVmConfig(srv, "tf-acc-vm"),
+			`ha         = true`, `ha         = true
+  labels     = `+labels, 1)
+	}
+	vmLabels := func(want int) resource.TestCheckFunc {
+		return func(s *terraform.State) error {
+			id := s.RootModule().Resources[name].Primary.ID
+			if labels := srv.Get("vms", id)["labels"].([]any); len(labels) != want {
+				return fmt.Errorf("vm %s has %d labels, want %d", id, len(labels), want)
+			}
+			return nil
+		}
+	}
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckDestroy(srv, "cloudtower_vm", "vms"),
+		Steps: []resource.TestStep{
+			{
+				Config: withLabels(`{ team = "a" }`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "labels.%", "1"),
+					resource.TestCheckResourceAttr(name, "labels.team", "a"),
+					resource.TestCheckResourceAttr(name, "labels_all.%", "2"),
+					resource.TestCheckResourceAttr(name, "labels_all.env", "test"),
+					vmLabels(2),
+				),
+			},
+			{
+				// a label of the resource overrides the default one of the same key
+				Config: withLabels(`{ team = "b", env = "prod" }`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "labels.%", "2"),
+					resource.TestCheckResourceAttr(name, "labels_all.team", "b"),
+					resource.TestCheckResourceAttr(name, "labels_all.env", "prod"),
+					vmLabels(2),
+				),
+			},
+			{
+				Config: withLabels(`{ team = "b" }`) + `
+data "cloudtower_vm" "test" {
+  label      = { team = "b", env = "test" }
+  depends_on = [cloudtower_vm.tf-acc-vm]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "labels.%", "1"),
+					resource.TestCheckResourceAttr(name, "labels_all.env", "test"),
+					resource.TestCheckResourceAttr("data.cloudtower_vm.test", "vms.#", "1"),
+					resource.TestCheckResourceAttrPair("data.cloudtower_vm.test", "vms.0.id", name, "id"),
+					vmLabels(2),
+				),
+			},
+			{
+				ResourceName:      name,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccResourceVm_autoSchedule(t *testing.T) {
+	srv := fake.NewServer(t)
+	hostIds := testAccAddHosts(srv, 2)
+	name := "cloudtower_vm.tf-acc-vm-b"
+	group := fmt.Sprintf(`
+resource "cloudtower_vm_placement_group" "test" {
+  name         = "tf-acc-anti-affinity"
+  cluster_id   = %q
+  vm_ids       = [cloudtower_vm.tf-acc-vm-a.id, cloudtower_vm.tf-acc-vm-b.id]
+  vm_vm_policy = "MUST_DIFFERENT"
+
+  vm_host_must_policy {
+    policy   = "NOT_RUN_ON"
+    host_ids = [%q]
+  }
+}
+`, srv.Fixtures.ClusterId, hostIds[1])
+	config := srv.ProviderConfig() + testAccVmConfig(srv, "tf-acc-vm-a") + testAccVmConfig(srv, "tf-acc-vm-b") + group
+	scheduled := srv.ProviderConfig() + testAccVmConfig(srv, "tf-acc-vm-a") + strings.Replace(testAccVmConfig(srv, "tf-acc-vm-b"),
+		`ha         = true`, `ha         = true
+  host_id    = "AUTO_SCHEDULE"`, 1) + group
+	var vmId string
+	vmOnHost := func(hostId string) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			if host := srv.Get("vms", vmId)["host"].(map[string]any)["id"]; host != hostId {
+				return fmt.Errorf("vm %s is on host %v, want %s", vmId, host, hostId)
+			}
+			return nil
+		}
+	}
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckDestroy(srv, "cloudtower_vm", "vms"),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrWith(name, "id", func(v string) error { vmId = v; return nil }),
+					resource.TestCheckResourceAttr(name, "host_id", hostIds[0]),
+				),
+			},
+			{
+				// the other VM of the group is on the first host, and the second one is excluded,
+				// the VM is not moved again once it is on a host satisfying the group
+				Config: scheduled,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPtr(name, "id", &vmId),
+					resource.TestCheckResourceAttr(name, "host_id", "AUTO_SCHEDULE"),
+					vmOnHost(hostIds[2]),
+				),
+			},
+			{
+				// the VM moved against the group outside of terraform is scheduled again
+				PreConfig: func() { srv.Update("vms", vmId, map[string]any{"host": map[string]any{"id": hostIds[0]}}) },
+				Config:    scheduled,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "host_id", "AUTO_SCHEDULE"),
+					vmOnHost(hostIds[2]),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,306 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/cloudtower"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/helper"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/client/vm_volume"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/models"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceVmVolume() *schema.Resource {
+	return &schema.Resource{
+		Description: "CloudTower vm volume resource, a volume that outlives the VMs it is attached to by cloudtower_vm_disk_attachment.",
+
+		CreateContext: resourceVmVolumeCreate,
+		ReadContext:   resourceVmVolumeRead,
+		UpdateContext: resourceVmVolumeUpdate,
+		DeleteContext: resourceVmVolumeDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importState(vmVolumeImportLookup),
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(30 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+		CustomizeDiff: customdiff.All(
+			labelsCustomizeDiff,
+			// an unknown size is left to the apply, once it is known
+			customdiff.If(func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) bool {
+				return d.NewValueKnown("size")
+			}, customdiff.ValidateChange("size", func(ctx context.Context, old, new, meta interface{}) error {
+				if new.(float64) < old.(float64) {
+					return fmt.Errorf("vm volume's size can not shrink from %.0f to %.0f bytes", old.(float64), new.(float64))
+				}
+				return nil
+			})),
+		),
+
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "vm volume's id",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "vm volume's name",
+			},
+			"cluster_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "the id of the cluster the vm volume is created in",
+			},
+			"size": {
+				Type:        schema.TypeFloat,
+				Required:    true,
+				Description: "vm volume's size, in the unit of byte, it can grow in place but can not shrink",
+			},
+			"storage_policy": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "vm volume's storage policy, changing it migrates the vm volume to the new storage policy in place",
+				ValidateFunc: validation.StringInSlice(
+					[]string{
+						"REPLICA_2_THIN_PROVISION",
+						"REPLICA_2_THICK_PROVISION",
+						"REPLICA_3_THIN_PROVISION",
+						"REPLICA_3_THICK_PROVISION",
+					}, false,
+				),
+			},
+			"sharing": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				ForceNew:    true,
+				Description: "whether the vm volume can be attached to several VMs at the same time",
+			},
+			"path": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "vm volume's iscsi LUN path",
+			},
+			"mounting": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "whether the vm volume is attached to a VM",
+			},
+			"labels":     labelsSchema("vm volume"),
+			"labels_all": labelsAllSchema("vm volume"),
+		},
+	}
+}
+
+func resourceVmVolumeCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	ct := meta.(*cloudtower.Client)
+	name := d.Get("name").(string)
+	clusterId := d.Get("cluster_id").(string)
+	size := int64(d.Get("size").(float64))
+	policy := models.VMVolumeElfStoragePolicyType(d.Get("storage_policy").(string))
+	sharing := d.Get("sharing").(bool)
+	cvp := vm_volume.NewCreateVMVolumeParams()
+	cvp.RequestBody = []*models.VMVolumeCreationParams{{
+		Name:             &name,
+		ClusterID:        &clusterId,
+		Size:             &size,
+		ElfStoragePolicy: &policy,
+		Sharing:          &sharing,
+	}}
+	cvp.Context = ctx
+	volumes, err := ct.Api.VMVolume.CreateVMVolume(cvp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(*volumes.Payload[0].Data.ID)
+	_, err = ct.WaitTasksFinish(ctx, []string{*volumes.Payload[0].TaskID})
+	if err != nil {
+		return diagFromTaskErr(err)
+	}
+	err = updateLabels(ctx, d, ct, labelTarget{vmVolume: &models.VMVolumeWhereInput{ID: volumes.Payload[0].Data.ID}})
+	if err != nil {
+		return diagFromTaskErr(err)
+	}
+
+	return resourceVmVolumeRead(ctx, d, meta)
+}
+
+func resourceVmVolumeRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	ct := meta.(*cloudtower.Client)
+
+	id := d.Id()
+	gvp := vm_volume.NewGetVMVolumesParams()
+	gvp.RequestBody = &models.GetVMVolumesRequestBody{
+		Where: &models.VMVolumeWhereInput{
+			ID: &id,
+		},
+	}
+	gvp.Context = ctx
+	volumes, err := ct.Api.VMVolume.GetVMVolumes(gvp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if len(volumes.Payload) < 1 {
+		d.SetId("")
+		return diags
+	}
+	volume := volumes.Payload[0]
+	if err = d.Set("name", volume.Name); err != nil {
+		return diag.FromErr(err)
+	}
+	if volume.Cluster != nil {
+		if err = d.Set("cluster_id", volume.Cluster.ID); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	if err = d.Set("size", volume.Size); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("storage_policy", helper.GetVmVolumeElfStoragePolicy(volume)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("sharing", volume.Sharing); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("path", volume.Path); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("mounting", volume.Mounting); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = setLabels(ctx, d, ct, volume.Labels); err != nil {
+		return diag.FromErr(err)
+	}
+	return diags
+}
+
+func resourceVmVolumeUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	ct := meta.(*cloudtower.Client)
+	id := d.Id()
+	data := &models.VMVolumeUpdationParamsData{}
+	if d.HasChange("name") {
+		name := d.Get("name").(string)
+		data.Name = &name
+	}
+	if d.HasChange("size") {
+		size := int64(d.Get("size").(float64))
+		data.Size = &size
+	}
+	if d.HasChange("storage_policy") {
+		policy := models.VMVolumeElfStoragePolicyType(d.Get("storage_policy").(string))
+		data.ElfStoragePolicy = &policy
+	}
+	if d.HasChanges("name", "size", "storage_policy") {
+		uvp := vm_volume.NewUpdateVMVolumeParams()
+		uvp.RequestBody = &models.VMVolumeUpdationParams{
+			Where: &models.VMVolumeWhereInput{
+				ID: &id,
+			},
+			Data: data,
+		}
+		uvp.Context = ctx
+		volumes, err := ct.Api.VMVolume.UpdateVMVolume(uvp)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		taskIds := make([]string, 0)
+		for _, v := range volumes.Payload {
+			if v.TaskID != nil {
+				taskIds = append(taskIds, *v.TaskID)
+			}
+		}
+		_, err = ct.WaitTasksFinish(ctx, taskIds)
+		if err != nil {
+			return diagFromTaskErr(err)
+		}
+	}
+	if d.HasChange("labels_all") {
+		err := updateLabels(ctx, d, ct, labelTarget{vmVolume: &models.VMVolumeWhereInput{ID: &id}})
+		if err != nil {
+			return diagFromTaskErr(err)
+		}
+	}
+
+	return resourceVmVolumeRead(ctx, d, meta)
+}
+
+func resourceVmVolumeDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	ct := meta.(*cloudtower.Client)
+	id := d.Id()
+	dvp := vm_volume.NewDeleteVMVolumeFromVMParams()
+	dvp.RequestBody = &models.VMVolumeDeletionParams{
+		Where: &models.VMVolumeWhereInput{
+			ID: &id,
+		},
+	}
+	dvp.Context = ctx
+	volumes, err := ct.Api.VMVolume.DeleteVMVolumeFromVM(dvp)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	taskIds := make([]string, 0)
+	for _, v := range volumes.Payload {
+		if v.TaskID != nil {
+			taskIds = append(taskIds, *v.TaskID)
+		}
+	}
+	_, err = ct.WaitTasksFinish(ctx, taskIds)
+	if err != nil {
+		return diagFromTaskErr(err)
+	}
+	d.SetId("")
+	return diags
+}
+
+func vmVolumeImportLookup(ctx context.Context, meta interface{}) *importLookup {
+	ct := meta.(*cloudtower.Client)
+	find := func(where *models.VMVolumeWhereInput) ([]string, error) {
+		gvp := vm_volume.NewGetVMVolumesParams()
+		gvp.RequestBody = &models.GetVMVolumesRequestBody{
+			Where: where,
+		}
+		gvp.Context = ctx
+		volumes, err := ct.Api.VMVolume.GetVMVolumes(gvp)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, 0, len(volumes.Payload))
+		for _, v := range volumes.Payload {
+			ids = append(ids, *v.ID)
+		}
+		return ids, nil
+	}
+	return &importLookup{
+		kind:   "VM volume",
+		format: "[cluster_name/]volume_name",
+		byId: func(id string) ([]string, error) {
+			return find(&models.VMVolumeWhereInput{
+				ID: &id,
+			})
+		},
+		byName: func(names []string) ([]string, error) {
+			where := &models.VMVolumeWhereInput{
+				Name: namesAt(names, 0),
+			}
+			if clusterName := namesAt(names, 1); clusterName != nil {
+				where.Cluster = &models.ClusterWhereInput{
+					Name: clusterName,
+				}
+			}
+			return find(where)
+		},
+	}
+}
@@ -0,0 +1,126 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/hashicorp/terraform-provider-cloudtower/internal/fake"
+)
+
+func testAccVmVolumeConfig(srv *fake.Server, name string, size int64, storagePolicy string) string {
+	return fmt.Sprintf(`
+resource "cloudtower_vm_volume" "test" {
+  name           = %q
+  cluster_id     = %q
+  size           = %d
+  storage_policy = %q
+}
+`, name, srv.Fixtures.ClusterId, size, storagePolicy)
+}
+
+func TestAccResourceVmVolume(t *testing.T) {
+	srv := fake.NewServer(t)
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckDestroy(srv, "cloudtower_vm_volume", "vm-volumes"),
+		Steps: []resource.TestStep{
+			{
+				Config: srv.ProviderConfig() + testAccVmVolumeConfig(srv, "tf-acc-volume", 10737418240, "REPLICA_2_THIN_PROVISION"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckExists(srv, "cloudtower_vm_volume.test", "vm-volumes"),
+					resource.TestCheckResourceAttr("cloudtower_vm_volume.test", "cluster_id", srv.Fixtures.ClusterId),
+					resource.TestCheckResourceAttr("cloudtower_vm_volume.test", "size", "10737418240"),
+					resource.TestCheckResourceAttr("cloudtower_vm_volume.test", "sharing", "false"),
+					resource.TestCheckResourceAttr("cloudtower_vm_volume.test", "mounting", "false"),
+					resource.TestCheckResourceAttrSet("cloudtower_vm_volume.test", "path"),
+				),
+			},
+			{
+				Config: srv.ProviderConfig() + testAccVmVolumeConfig(srv, "tf-acc-volume-renamed", 21474836480, "REPLICA_3_THICK_PROVISION"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("cloudtower_vm_volume.test", "name", "tf-acc-volume-renamed"),
+					resource.TestCheckResourceAttr("cloudtower_vm_volume.test", "size", "21474836480"),
+					resource.TestCheckResourceAttr("cloudtower_vm_volume.test", "storage_policy", "REPLICA_3_THICK_PROVISION"),
+				),
+			},
+			{
+				Config:      srv.ProviderConfig() + testAccVmVolumeConfig(srv, "tf-acc-volume-renamed", 10737418240, "REPLICA_3_THICK_PROVISION"),
+				ExpectError: regexp.MustCompile("size can not shrink"),
+			},
+			{
+				// the size is unknown when planned, it is not taken as a shrink
+				Config: srv.ProviderConfig() + strings.Replace(testAccVmVolumeConfig(srv, "tf-acc-volume-renamed", 21474836480, "REPLICA_3_THICK_PROVISION"), "21474836480", "terraform_data.size.output", 1) + `
+resource "terraform_data" "size" {
+  input = 32212254720
+}
+`,
+				Check: resource.TestCheckResourceAttr("cloudtower_vm_volume.test", "size", "32212254720"),
+			},
+			{
+				ResourceName:      "cloudtower_vm_volume.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:      "cloudtower_vm_volume.test",
+				ImportState:       true,
+				ImportStateId:     srv.Fixtures.ClusterName + "/tf-acc-volume-renamed",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccResourceVmVolume_labels(t *testing.T) {
+	srv := fake.NewServer(t)
+	name := "cloudtower_vm_volume.test"
+	provider := strings.Replace(srv.ProviderConfig(), `task_poll_interval = 1`, `task_poll_interval = 1
+  default_labels     = { env = "test" }`, 1)
+	withLabels := func(labels string) string {
+		return provider + strings.Replace(testAccVmVolumeConfig(srv, "tf-acc-volume", 10737418240, "REPLICA_2_THIN_PROVISION"),
+			`size           = 10737418240`, `size           = 10737418240
+  labels         = `+labels, 1)
+	}
+	volumeLabels := func(want int) resource.TestCheckFunc {
+		return func(s *terraform.State) error {
+			id := s.RootModule().Resources[name].Primary.ID
+			if labels, _ := srv.Get("vm-volumes", id)["labels"].([]any); len(labels) != want {
+				return fmt.Errorf("vm volume %s has %d labels, want %d", id, len(labels), want)
+			}
+			return nil
+		}
+	}
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckDestroy(srv, "cloudtower_vm_volume", "vm-volumes"),
+		Steps: []resource.TestStep{
+			{
+				Config: withLabels(`{ team = "a" }`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "labels.%", "1"),
+					resource.TestCheckResourceAttr(name, "labels_all.%", "2"),
+					resource.TestCheckResourceAttr(name, "labels_all.env", "test"),
+					volumeLabels(2),
+				),
+			},
+			{
+				// only the labels change, the vm volume itself is not updated
+				Config: withLabels(`{}`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "labels.%", "0"),
+					resource.TestCheckResourceAttr(name, "labels_all.%", "1"),
+					volumeLabels(1),
+				),
+			},
+			{
+				ResourceName:      name,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
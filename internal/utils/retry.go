@@ -3,37 +3,50 @@ package utils
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"time"
 )
 
 type RetryOptions struct {
 	MaxRetries int
 	Backoff    time.Duration
+	// Classifier decides which errors are retried, defaults to ClassifyError
+	Classifier RetryClassifier
 }
 
 func Retry[T any](ctx context.Context, fn func() (T, error), opt RetryOptions) (T, error) {
 	var zero T
 	maxRetries := opt.MaxRetries
 	backoff := opt.Backoff
+	classifier := opt.Classifier
 	if maxRetries <= 0 {
 		maxRetries = 3
 	}
 	if backoff <= 0 {
 		backoff = 1 * time.Second
 	}
+	if classifier == nil {
+		classifier = ClassifyError
+	}
+	var lastErr error
 	for i := 0; i < maxRetries; i++ {
 		res, err := fn()
 		if err == nil {
 			return res, nil
 		}
-		select {
-		case <-ctx.Done():
-			return zero, ctx.Err()
-		case <-time.After(backoff):
-			continue
+		lastErr = err
+		retryable, after := classifier(err)
+		if !retryable {
+			return zero, err
+		}
+		if i == maxRetries-1 {
+			break
+		}
+		if err := Sleep(ctx, delayFor(backoff, after, 0)); err != nil {
+			return zero, err
 		}
 	}
-	return zero, fmt.Errorf("failed after %d retries", maxRetries)
+	return zero, fmt.Errorf("failed after %d retries: %w", maxRetries, lastErr)
 }
 
 type RetryWithExponentialBackoffOptions struct {
@@ -41,6 +54,8 @@ type RetryWithExponentialBackoffOptions struct {
 	Ratio          float64
 	MaxDelay       time.Duration
 	InitialBackoff time.Duration
+	// Classifier decides which errors are retried, defaults to ClassifyError
+	Classifier RetryClassifier
 }
 
 func RetryWithExponentialBackoff[T any](ctx context.Context, fn func() (T, error), opt RetryWithExponentialBackoffOptions) (T, error) {
@@ -49,6 +64,7 @@ func RetryWithExponentialBackoff[T any](ctx context.Context, fn func() (T, error
 	ratio := opt.Ratio
 	maxDelay := opt.MaxDelay
 	initialBackoff := opt.InitialBackoff
+	classifier := opt.Classifier
 	if maxRetries <= 0 {
 		maxRetries = 3
 	}
@@ -61,23 +77,57 @@ func RetryWithExponentialBackoff[T any](ctx context.Context, fn func() (T, error
 	if maxDelay <= 0 {
 		maxDelay = 10 * initialBackoff
 	}
+	if classifier == nil {
+		classifier = ClassifyError
+	}
 
 	backoff := initialBackoff
+	var lastErr error
 	for i := 0; i < maxRetries; i++ {
 		res, err := fn()
 		if err == nil {
 			return res, nil
 		}
+		lastErr = err
+		retryable, after := classifier(err)
+		if !retryable {
+			return zero, err
+		}
+		if i == maxRetries-1 {
+			break
+		}
 		backoff = time.Duration(float64(backoff) * ratio)
 		if backoff > maxDelay {
 			backoff = maxDelay
 		}
-		select {
-		case <-ctx.Done():
-			return zero, ctx.Err()
-		case <-time.After(backoff):
-			continue
+		if err := Sleep(ctx, delayFor(backoff, after, maxDelay)); err != nil {
+			return zero, err
 		}
 	}
-	return zero, fmt.Errorf("failed after %d retries", maxRetries)
+	return zero, fmt.Errorf("failed after %d retries: %w", maxRetries, lastErr)
+}
+
+// delayFor honors the delay requested by the server up to maxDelay, unless it is zero,
+// and otherwise spreads the backoff by ±20% so concurrent callers do not retry in lockstep
+func delayFor(backoff time.Duration, after time.Duration, maxDelay time.Duration) time.Duration {
+	if after > 0 {
+		if maxDelay > 0 && after > maxDelay {
+			return maxDelay
+		}
+		return after
+	}
+	jitter := float64(backoff) * 0.2
+	return backoff + time.Duration(jitter*(2*rand.Float64()-1))
+}
+
+// Sleep waits for d, it returns early with the error of ctx once ctx is done
+func Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
 }
@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	"github.com/hasura/go-graphql-client"
+)
+
+// RetryClassifier reports whether err is transient and worth retrying, along
+// with the delay requested by the server, zero means using the backoff
+type RetryClassifier func(err error) (retryable bool, after time.Duration)
+
+// ClassifyError is the default RetryClassifier, it retries network failures,
+// timeouts, throttling and server side errors, but not the errors caused by
+// the request itself such as validation failures or missing permissions
+func ClassifyError(err error) (bool, time.Duration) {
+	if err == nil {
+		return false, 0
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false, 0
+	}
+
+	var apiErr *runtime.APIError
+	if errors.As(err, &apiErr) {
+		var after time.Duration
+		if apiErr.Response != nil {
+			if resp, ok := apiErr.Response.(runtime.ClientResponse); ok {
+				after = parseRetryAfter(resp.GetHeader("Retry-After"))
+			}
+		}
+		return isRetryableStatus(apiErr.Code), after
+	}
+	// responses generated by go-swagger, e.g. *vm.GetVmsBadRequest
+	var codeErr interface{ Code() int }
+	if errors.As(err, &codeErr) {
+		return isRetryableStatus(codeErr.Code()), 0
+	}
+
+	var networkErr graphql.NetworkError
+	if errors.As(err, &networkErr) {
+		return isRetryableStatus(networkErr.StatusCode()), 0
+	}
+	var gqlErrs graphql.Errors
+	if errors.As(err, &gqlErrs) {
+		for _, e := range gqlErrs {
+			if isRetryableGraphqlError(e) {
+				return true, 0
+			}
+		}
+		return false, 0
+	}
+	var gqlErr graphql.Error
+	if errors.As(err, &gqlErr) {
+		return isRetryableGraphqlError(gqlErr), 0
+	}
+
+	// a certificate rejected once will be rejected again
+	var certErr *tls.CertificateVerificationError
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	if errors.As(err, &certErr) || errors.As(err, &unknownAuthorityErr) || errors.As(err, &hostnameErr) {
+		return false, 0
+	}
+
+	// network failures such as timeouts and connection resets are transient,
+	// and errors we know nothing about keep being retried as before
+	return true, 0
+}
+
+func isRetryableStatus(code int) bool {
+	switch {
+	case code == http.StatusRequestTimeout, code == http.StatusTooManyRequests:
+		return true
+	case code >= 500 && code != http.StatusNotImplemented:
+		return true
+	default:
+		return false
+	}
+}
+
+func isRetryableGraphqlError(e graphql.Error) bool {
+	// errors raised by the client itself wrap the cause, e.g. a NetworkError
+	// carrying the status code or a connection reset
+	if cause := e.Unwrap(); cause != nil {
+		retryable, _ := ClassifyError(cause)
+		return retryable
+	}
+	code, _ := e.Extensions["code"].(string)
+	return code == "INTERNAL_SERVER_ERROR"
+}
+
+// parseRetryAfter accepts both forms of Retry-After, delay seconds and HTTP date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
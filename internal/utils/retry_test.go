@@ -3,8 +3,16 @@ package utils
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/go-openapi/runtime"
+	"github.com/hasura/go-graphql-client"
 )
 
 type testContext struct {
@@ -221,3 +229,150 @@ func TestRetryWithExponentialBackoff(t *testing.T) {
 		})
 	}
 }
+
+func TestRetryWithExponentialBackoffCapsRetryAfter(t *testing.T) {
+	attempts := 0
+	start := time.Now()
+	_, err := RetryWithExponentialBackoff(context.Background(), func() (string, error) {
+		attempts++
+		return "", errors.New("throttled")
+	}, RetryWithExponentialBackoffOptions{
+		MaxRetries:     3,
+		InitialBackoff: 10 * time.Millisecond,
+		MaxDelay:       50 * time.Millisecond,
+		Classifier: func(err error) (bool, time.Duration) {
+			return true, time.Hour
+		},
+	})
+	if err == nil || attempts != 3 {
+		t.Fatalf("RetryWithExponentialBackoff() attempts = %d, err = %v, want 3 failed attempts", attempts, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("RetryWithExponentialBackoff() waited %v, want Retry-After capped by MaxDelay", elapsed)
+	}
+}
+
+type fakeClientResponse struct {
+	code    int
+	headers map[string]string
+}
+
+func (r fakeClientResponse) Code() int                       { return r.code }
+func (r fakeClientResponse) Message() string                 { return "" }
+func (r fakeClientResponse) GetHeader(name string) string    { return r.headers[name] }
+func (r fakeClientResponse) GetHeaders(name string) []string { return []string{r.headers[name]} }
+func (r fakeClientResponse) Body() io.ReadCloser             { return io.NopCloser(strings.NewReader("")) }
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		wantRetryable bool
+		wantAfter     time.Duration
+	}{
+		{
+			name:          "bad request",
+			err:           runtime.NewAPIError("GetVms", fakeClientResponse{code: 400}, 400),
+			wantRetryable: false,
+		},
+		{
+			name:          "forbidden",
+			err:           fmt.Errorf("wrapped: %w", runtime.NewAPIError("GetVms", fakeClientResponse{code: 403}, 403)),
+			wantRetryable: false,
+		},
+		{
+			name:          "service unavailable",
+			err:           runtime.NewAPIError("GetVms", fakeClientResponse{code: 503}, 503),
+			wantRetryable: true,
+		},
+		{
+			name: "too many requests with retry after",
+			err: runtime.NewAPIError("GetVms", fakeClientResponse{
+				code:    429,
+				headers: map[string]string{"Retry-After": "2"},
+			}, 429),
+			wantRetryable: true,
+			wantAfter:     2 * time.Second,
+		},
+		{
+			name:          "graphql validation error",
+			err:           graphql.Errors{{Message: "invalid", Extensions: map[string]any{"code": "GRAPHQL_VALIDATION_FAILED"}}},
+			wantRetryable: false,
+		},
+		{
+			name:          "graphql internal error",
+			err:           graphql.Errors{{Message: "boom", Extensions: map[string]any{"code": "INTERNAL_SERVER_ERROR"}}},
+			wantRetryable: true,
+		},
+		{
+			name:          "network error",
+			err:           &url.Error{Op: "Post", URL: "http://tower", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}},
+			wantRetryable: true,
+		},
+		{
+			name:          "context canceled",
+			err:           fmt.Errorf("wrapped: %w", context.Canceled),
+			wantRetryable: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retryable, after := ClassifyError(tt.err)
+			if retryable != tt.wantRetryable {
+				t.Errorf("ClassifyError() retryable = %v, want %v", retryable, tt.wantRetryable)
+			}
+			if after != tt.wantAfter {
+				t.Errorf("ClassifyError() after = %v, want %v", after, tt.wantAfter)
+			}
+		})
+	}
+}
+
+func TestRetryWithExponentialBackoffClassifier(t *testing.T) {
+	t.Run("stop on non retryable error", func(t *testing.T) {
+		calls := 0
+		badRequest := runtime.NewAPIError("CreateVm", fakeClientResponse{code: 400}, 400)
+		_, err := RetryWithExponentialBackoff(context.Background(), func() (string, error) {
+			calls++
+			return "", badRequest
+		}, RetryWithExponentialBackoffOptions{InitialBackoff: time.Millisecond * 10})
+		if calls != 1 {
+			t.Errorf("RetryWithExponentialBackoff() calls = %d, want 1", calls)
+		}
+		if !errors.Is(err, badRequest) {
+			t.Errorf("RetryWithExponentialBackoff() error = %v, want %v", err, badRequest)
+		}
+	})
+
+	t.Run("wrap last error", func(t *testing.T) {
+		calls := 0
+		lastErr := errors.New("connection reset")
+		_, err := RetryWithExponentialBackoff(context.Background(), func() (string, error) {
+			calls++
+			return "", lastErr
+		}, RetryWithExponentialBackoffOptions{MaxRetries: 3, InitialBackoff: time.Millisecond * 10})
+		if calls != 3 {
+			t.Errorf("RetryWithExponentialBackoff() calls = %d, want 3", calls)
+		}
+		if !errors.Is(err, lastErr) {
+			t.Errorf("RetryWithExponentialBackoff() error = %v, want wrapping %v", err, lastErr)
+		}
+	})
+
+	t.Run("custom classifier", func(t *testing.T) {
+		calls := 0
+		_, err := Retry(context.Background(), func() (string, error) {
+			calls++
+			return "", errors.New("permanent")
+		}, RetryOptions{
+			Backoff: time.Millisecond * 10,
+			Classifier: func(err error) (bool, time.Duration) {
+				return false, 0
+			},
+		})
+		if err == nil || calls != 1 {
+			t.Errorf("Retry() calls = %d, error = %v, want 1 call and an error", calls, err)
+		}
+	})
+}